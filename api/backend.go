@@ -1,6 +1,8 @@
 package api
 
 import (
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
@@ -11,6 +13,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/rpc"
 	abciTypes "github.com/tendermint/tendermint/abci/types"
+	mempl "github.com/tendermint/tendermint/mempool"
 	tmn "github.com/tendermint/tendermint/node"
 	rpcClient "github.com/tendermint/tendermint/rpc/client"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
@@ -43,6 +46,8 @@ type Backend struct {
 	client *rpcClient.HTTP
 	// local client for in-proc app to execute the rpc functions without the overhead of http
 	localClient *rpcClient.Local
+	// mempool of the in-proc tendermint node
+	mempool *mempl.Mempool
 
 	// travis chain id
 	chainID string
@@ -118,6 +123,15 @@ func (b *Backend) Config() *eth.Config {
 func (b *Backend) SetTMNode(tmNode *tmn.Node) {
 	b.chainID = tmNode.GenesisDoc().ChainID
 	b.localClient = rpcClient.NewLocal(tmNode)
+	b.mempool = tmNode.MempoolReactor().Mempool
+}
+
+// MempoolSize returns the number of txs waiting in the tendermint mempool
+func (b *Backend) MempoolSize() int {
+	if b.mempool != nil {
+		return b.mempool.Size()
+	}
+	return 0
 }
 
 func (b *Backend) PeerCount() int {
@@ -142,10 +156,18 @@ func (b *Backend) DeliverTx(tx *ethTypes.Transaction) abciTypes.ResponseDeliverT
 	return b.es.DeliverTx(tx)
 }
 
+// DeliverSponsoredTx appends a transaction whose gas is paid by payer to the current block
+// #unstable
+func (b *Backend) DeliverSponsoredTx(tx *ethTypes.Transaction, payer common.Address) abciTypes.ResponseDeliverTx {
+	return b.es.DeliverSponsoredTx(tx, payer)
+}
+
 // AccumulateRewards accumulates the rewards based on the given strategy
+// and returns the amount credited to each account
 // #unstable
-func (b *Backend) AccumulateRewards(config *params.ChainConfig, strategy *emtTypes.Strategy) {
-	b.es.AccumulateRewards(config, strategy)
+func (b *Backend) AccumulateRewards(config *params.ChainConfig, strategy emtTypes.RewardStrategy,
+	validators []abciTypes.Validator) map[common.Address]*big.Int {
+	return b.es.AccumulateRewards(config, strategy, validators)
 }
 
 // Commit finalises the current block
@@ -177,6 +199,18 @@ func (b *Backend) GasLimit() uint64 {
 	return b.es.GasLimit().Gas()
 }
 
+// HeaderGasLimit returns the gas limit of the block being built
+// #unstable
+func (b *Backend) HeaderGasLimit() uint64 {
+	return b.es.HeaderGasLimit()
+}
+
+// SetNextGasLimit sets the gas limit of the blocks built after the next Commit
+// #unstable
+func (b *Backend) SetNextGasLimit(limit uint64) {
+	b.es.SetNextGasLimit(limit)
+}
+
 //----------------------------------------------------------------------
 // Implements: node.Service
 
@@ -219,6 +253,18 @@ func (b *Backend) Start(_ *p2p.Server) error {
 	return nil
 }
 
+// FlushState writes the state trie of the head block, which a full node keeps in
+// memory between its periodic flushes, to disk. An archive node flushes it on
+// every block already.
+func (b *Backend) FlushState() error {
+	blockchain := b.ethereum.BlockChain()
+	currentState, err := blockchain.State()
+	if err != nil {
+		return err
+	}
+	return currentState.Database().TrieDB().Commit(blockchain.CurrentBlock().Root(), false)
+}
+
 // Stop implements node.Service, terminating all internal goroutines used by the
 // Ethereum protocol.
 // #stable
@@ -228,6 +274,13 @@ func (b *Backend) Stop() error {
 	return nil
 }
 
+// Flush persists the recent chain state held in memory. The chain doesn't
+// process blocks anymore afterwards, it's meant to be called on shutdown.
+func (b *Backend) Flush() error {
+	b.ethereum.BlockChain().Stop()
+	return nil
+}
+
 // Protocols implements node.Service, returning all the currently configured
 // network protocols to start.
 // #stable
@@ -0,0 +1,126 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/trie"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// accountCeiling caps the number of accounts of the state. The accounts are
+// counted once when the ceiling is set, then the accounts created by the
+// delivered txs are added.
+type accountCeiling struct {
+	// maximum number of accounts, 0 disables the ceiling
+	max uint64
+	// number of accounts of the committed state and the delivered txs
+	count uint64
+}
+
+// countAccounts walks the account trie of the state at root
+func countAccounts(db state.Database, root common.Hash) (uint64, error) {
+	tr, err := db.OpenTrie(root)
+	if err != nil {
+		return 0, err
+	}
+	var count uint64
+	it := trie.NewIterator(tr.NodeIterator(nil))
+	for it.Next() {
+		count++
+	}
+	return count, it.Err
+}
+
+// setMaxAccounts sets the account ceiling, counting the accounts of the head state
+func (app *EthermintApplication) setMaxAccounts(limit uint64) error {
+	var count uint64
+	if limit > 0 && app.backend != nil {
+		st, err := app.backend.Ethereum().BlockChain().State()
+		if err != nil {
+			return err
+		}
+		head := app.backend.Ethereum().BlockChain().CurrentBlock()
+		if count, err = countAccounts(st.Database(), head.Root()); err != nil {
+			return fmt.Errorf("error counting the accounts: %v", err)
+		}
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.accounts.max = limit
+	app.accounts.count = count
+	return nil
+}
+
+// checkAccountCeiling rejects a tx creating an account once the state holds the
+// maximum number of accounts. The txs to existing accounts and the contract
+// creations are unaffected. The accounts created by the txs waiting in the
+// mempool only count once delivered, so the ceiling can be overshot by a block.
+func (app *EthermintApplication) checkAccountCeiling(currentState *state.StateDB,
+	tx *ethTypes.Transaction) abciTypes.ResponseCheckTx {
+
+	app.mu.Lock()
+	ceiling := app.accounts
+	app.mu.Unlock()
+
+	if ceiling.max == 0 || tx.To() == nil || currentState.Exist(*tx.To()) {
+		return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+	}
+	if ceiling.count >= ceiling.max {
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeStateFull,
+			Log: fmt.Sprintf("State is full: %d accounts, new account %s refused",
+				ceiling.count, tx.To().Hex())}
+	}
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
+
+// newAccounts returns the accounts a tx may create missing from the DeliverTx
+// state: its recipient, or the address of the contract it creates. Nil when the
+// ceiling is disabled, the accounts created by inner calls aren't counted.
+func (app *EthermintApplication) newAccounts(tx *ethTypes.Transaction) []common.Address {
+	app.mu.Lock()
+	max := app.accounts.max
+	app.mu.Unlock()
+	if max == 0 {
+		return nil
+	}
+
+	addr := tx.To()
+	if addr == nil {
+		from, err := app.sender(tx)
+		if err != nil {
+			return nil
+		}
+		created := crypto.CreateAddress(from, tx.Nonce())
+		addr = &created
+	}
+	if app.DeliverTxState().Exist(*addr) {
+		return nil
+	}
+	return []common.Address{*addr}
+}
+
+// countNewAccounts adds the accounts of newAccounts existing once the tx is delivered
+func (app *EthermintApplication) countNewAccounts(candidates []common.Address) {
+	if len(candidates) == 0 {
+		return
+	}
+	var created uint64
+	deliverState := app.DeliverTxState()
+	for _, addr := range candidates {
+		if deliverState.Exist(addr) {
+			created++
+		}
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.accounts.count += created
+}
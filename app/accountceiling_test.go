@@ -0,0 +1,53 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestAccountCeiling(t *testing.T) {
+	assert := assert.New(t)
+
+	existing := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	fresh := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	st := newTestState()
+	st.AddBalance(existing, big.NewInt(1))
+	st.AddBalance(common.HexToAddress("0x2000000000000000000000000000000000000002"), big.NewInt(1))
+	root, err := st.Commit(true)
+	assert.Nil(err)
+	count, err := countAccounts(st.Database(), root)
+	assert.Nil(err)
+	assert.Equal(uint64(2), count)
+
+	toExisting := ethTypes.NewTransaction(0, existing, big.NewInt(1), 21000, big.NewInt(1), nil)
+	toFresh := ethTypes.NewTransaction(0, fresh, big.NewInt(1), 21000, big.NewInt(1), nil)
+	creation := ethTypes.NewContractCreation(0, big.NewInt(0), 100000, big.NewInt(1), nil)
+
+	// disabled by default
+	app := &EthermintApplication{}
+	assert.Equal(abciTypes.CodeTypeOK, app.checkAccountCeiling(st, toFresh).Code)
+	assert.Nil(app.newAccounts(toFresh))
+
+	// one account below the ceiling, the app has no backend to count them
+	assert.Nil(app.setOption("max_accounts", "3"))
+	app.accounts.count = count
+	assert.Equal(abciTypes.CodeTypeOK, app.checkAccountCeiling(st, toFresh).Code)
+
+	// at the ceiling new accounts are refused, the existing ones still receive
+	app.accounts.count++
+	assert.Equal(errors.CodeTypeStateFull, app.checkAccountCeiling(st, toFresh).Code)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkAccountCeiling(st, toExisting).Code)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkAccountCeiling(st, creation).Code)
+
+	assert.Nil(app.setOption("max_accounts", "0"))
+	assert.Equal(abciTypes.CodeTypeOK, app.checkAccountCeiling(st, toFresh).Code)
+	assert.NotNil(app.setOption("max_accounts", "-1"))
+}
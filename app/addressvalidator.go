@@ -0,0 +1,53 @@
+package app
+
+import (
+	goerr "errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// AddressValidator checks the recipient of a tx, an error rejects the tx. It isn't
+// consulted for the contract creations.
+type AddressValidator func(addr common.Address) error
+
+var errZeroAddress = goerr.New("zero address recipient")
+
+// RejectZeroAddress is an AddressValidator refusing the txs sent to the zero
+// address, a common mistake of clients meaning to create a contract
+func RejectZeroAddress(addr common.Address) error {
+	if addr == (common.Address{}) {
+		return errZeroAddress
+	}
+	return nil
+}
+
+// SetAddressValidator sets the validator of the recipients, e.g. to require
+// registered addresses; nil accepts all
+// #unstable
+func (app *EthermintApplication) SetAddressValidator(validator AddressValidator) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.addressValidator = validator
+}
+
+// checkRecipientAddress rejects a tx whose recipient is refused by the address validator
+func (app *EthermintApplication) checkRecipientAddress(tx *ethTypes.Transaction) abciTypes.ResponseCheckTx {
+	app.mu.Lock()
+	validator := app.addressValidator
+	app.mu.Unlock()
+
+	if validator == nil || tx.To() == nil {
+		return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+	}
+	if err := validator(*tx.To()); err != nil {
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeBaseInvalidInput,
+			Log:  "Invalid recipient: " + err.Error()}
+	}
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestAddressValidator(t *testing.T) {
+	assert := assert.New(t)
+
+	toZero := ethTypes.NewTransaction(0, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	creation := ethTypes.NewContractCreation(0, big.NewInt(0), 100000, big.NewInt(1), nil)
+	registered := common.HexToAddress("0x2000000000000000000000000000000000000002")
+
+	// all accepted by default
+	app := &EthermintApplication{}
+	assert.Equal(abciTypes.CodeTypeOK, app.checkRecipientAddress(toZero).Code)
+
+	app.SetAddressValidator(RejectZeroAddress)
+	resp := app.checkRecipientAddress(toZero)
+	assert.Equal(errors.CodeTypeBaseInvalidInput, resp.Code)
+	assert.Equal("Invalid recipient: zero address recipient", resp.Log)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkRecipientAddress(pricedTx(0, 1)).Code)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkRecipientAddress(creation).Code)
+
+	// registered addresses only
+	app.SetAddressValidator(func(addr common.Address) error {
+		if addr != registered {
+			return fmt.Errorf("%s isn't registered", addr.Hex())
+		}
+		return nil
+	})
+	assert.Equal(abciTypes.CodeTypeOK, app.checkRecipientAddress(pricedTx(0, 1)).Code)
+	assert.Equal(errors.CodeTypeBaseInvalidInput, app.checkRecipientAddress(toZero).Code)
+
+	app.SetAddressValidator(nil)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkRecipientAddress(toZero).Code)
+}
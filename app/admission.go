@@ -0,0 +1,57 @@
+package app
+
+import (
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// AdmissionPolicy decides whether a tx which passed the consensus checks
+// (signature, nonce, balance, gas) is admitted to the mempool
+type AdmissionPolicy interface {
+	Admit(tx *ethTypes.Transaction, currentState *state.StateDB) (ok bool, code uint32, log string)
+}
+
+// SetAdmissionPolicies replaces the admission policies, which are consulted in
+// order on the first check of a tx. The default is the low price policy alone.
+// #unstable
+func (app *EthermintApplication) SetAdmissionPolicies(policies ...AdmissionPolicy) {
+	app.admissionPolicies = policies
+}
+
+// LowPricePolicy returns the default policy, letting only the first tx of each
+// from/to pair pay less than the minimum gas price
+// #unstable
+func (app *EthermintApplication) LowPricePolicy() AdmissionPolicy {
+	return lowPricePolicy{app}
+}
+
+// admit runs the tx through the admission policies, the first refusal wins
+func (app *EthermintApplication) admit(tx *ethTypes.Transaction, currentState *state.StateDB) abciTypes.ResponseCheckTx {
+	for _, policy := range app.admissionPolicies {
+		if ok, code, log := policy.Admit(tx, currentState); !ok {
+			if code == abciTypes.CodeTypeOK {
+				code = errors.CodeTypeBaseInvalidInput
+			}
+			return abciTypes.ResponseCheckTx{Code: code, Log: log}
+		}
+	}
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
+
+// lowPricePolicy is the AdmissionPolicy wrapping checkLowPrice
+type lowPricePolicy struct {
+	app *EthermintApplication
+}
+
+func (p lowPricePolicy) Admit(tx *ethTypes.Transaction, currentState *state.StateDB) (bool, uint32, string) {
+	// the sender has been recovered by the consensus checks already and is cached
+	from, err := ethTypes.Sender(p.app.signer(tx), tx)
+	if err != nil {
+		return false, errors.CodeTypeInternalErr, err.Error()
+	}
+	resp := p.app.checkLowPrice(from, tx, p.app.now())
+	return resp.Code == abciTypes.CodeTypeOK, resp.Code, resp.Log
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// blockContractPolicy refuses the txs sent to a contract
+type blockContractPolicy struct {
+	contract common.Address
+}
+
+func (p blockContractPolicy) Admit(tx *ethTypes.Transaction, currentState *state.StateDB) (bool, uint32, string) {
+	if to := tx.To(); to != nil && *to == p.contract {
+		return false, errors.CodeTypeUnauthorized, "contract is blocked"
+	}
+	return true, abciTypes.CodeTypeOK, ""
+}
+
+// countingPolicy admits everything and counts the txs it saw
+type countingPolicy struct {
+	seen *int
+}
+
+func (p countingPolicy) Admit(tx *ethTypes.Transaction, currentState *state.StateDB) (bool, uint32, string) {
+	*p.seen++
+	return true, abciTypes.CodeTypeOK, ""
+}
+
+func TestAdmissionPolicies(t *testing.T) {
+	assert := assert.New(t)
+
+	st := newTestState()
+	blocked := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	other := common.HexToAddress("0x2000000000000000000000000000000000000002")
+
+	seen := 0
+	app := &EthermintApplication{}
+	app.SetAdmissionPolicies(blockContractPolicy{blocked}, countingPolicy{&seen})
+
+	tx := ethTypes.NewTransaction(0, blocked, big.NewInt(0), 50000, big.NewInt(1), nil)
+	resp := app.admit(tx, st)
+	assert.Equal(errors.CodeTypeUnauthorized, resp.Code)
+	assert.Equal("contract is blocked", resp.Log)
+	// the policies after a refusal aren't consulted
+	assert.Equal(0, seen)
+
+	tx = ethTypes.NewTransaction(0, other, big.NewInt(0), 50000, big.NewInt(1), nil)
+	assert.Equal(abciTypes.CodeTypeOK, app.admit(tx, st).Code)
+	assert.Equal(1, seen)
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+)
+
+// chainReader is the part of the blockchain the app hash is read from
+type chainReader interface {
+	CurrentBlock() *ethTypes.Block
+	GetBlockByNumber(number uint64) *ethTypes.Block
+}
+
+// chainInfo answers Info from the head of the chain
+func chainInfo(chain chainReader) abciTypes.ResponseInfo {
+	currentBlock := chain.CurrentBlock()
+	height := currentBlock.Number()
+	hash := currentBlock.Hash()
+
+	// This check determines whether it is the first time ethermint gets started.
+	// If it is the first time, then we have to respond with an empty hash, since
+	// that is what tendermint expects.
+	if height.Cmp(bigZero) == 0 {
+		return abciTypes.ResponseInfo{
+			Data:             "ABCIEthereum",
+			LastBlockHeight:  height.Int64(),
+			LastBlockAppHash: []byte{},
+		}
+	}
+
+	return abciTypes.ResponseInfo{
+		Data:             "ABCIEthereum",
+		LastBlockHeight:  height.Int64(),
+		LastBlockAppHash: hash[:],
+	}
+}
+
+// appHashAt returns the app hash reported to tendermint for the given height,
+// the hash of the block at that height; the genesis has an empty one
+func appHashAt(chain chainReader, height int64) (common.Hash, error) {
+	if height < 0 {
+		return common.Hash{}, fmt.Errorf("invalid height %d", height)
+	}
+	if head := chain.CurrentBlock().NumberU64(); uint64(height) > head {
+		return common.Hash{}, fmt.Errorf("height %d is in the future, current height is %d", height, head)
+	}
+	if height == 0 {
+		return common.Hash{}, nil
+	}
+	block := chain.GetBlockByNumber(uint64(height))
+	if block == nil {
+		return common.Hash{}, fmt.Errorf("block %d not found", height)
+	}
+	return block.Hash(), nil
+}
+
+// AppHashAt recomputes the app hash of a past height from the stored block, to
+// find where the node diverged from the others
+// #unstable
+func (app *EthermintApplication) AppHashAt(height int64) (common.Hash, error) {
+	return appHashAt(app.backend.Ethereum().BlockChain(), height)
+}
+
+// appHashQuery serves travis_appHash, whose only param is the height
+func (app *EthermintApplication) appHashQuery(params []interface{}) (common.Hash, error) {
+	if len(params) != 1 {
+		return common.Hash{}, fmt.Errorf("expected 1 param, got %d", len(params))
+	}
+	// json numbers are decoded as float64
+	height, ok := params[0].(float64)
+	if !ok || height != float64(int64(height)) {
+		return common.Hash{}, fmt.Errorf("invalid height: %v", params[0])
+	}
+	return app.AppHashAt(int64(height))
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// stubChain is a chain of empty blocks
+type stubChain []*ethTypes.Block
+
+func newStubChain(length int) stubChain {
+	var chain stubChain
+	parent := common.Hash{}
+	for i := 0; i < length; i++ {
+		block := ethTypes.NewBlockWithHeader(&ethTypes.Header{
+			Number:     big.NewInt(int64(i)),
+			ParentHash: parent,
+			GasLimit:   8000000,
+		})
+		chain = append(chain, block)
+		parent = block.Hash()
+	}
+	return chain
+}
+
+func (c stubChain) CurrentBlock() *ethTypes.Block { return c[len(c)-1] }
+
+func (c stubChain) GetBlockByNumber(number uint64) *ethTypes.Block {
+	if number >= uint64(len(c)) {
+		return nil
+	}
+	return c[number]
+}
+
+func TestAppHashAt(t *testing.T) {
+	assert := assert.New(t)
+
+	chain := newStubChain(6)
+
+	// the current height matches Info
+	info := chainInfo(chain)
+	hash, err := appHashAt(chain, info.LastBlockHeight)
+	assert.Nil(err)
+	assert.Equal(info.LastBlockAppHash, hash.Bytes())
+
+	// past heights
+	hash, err = appHashAt(chain, 3)
+	assert.Nil(err)
+	assert.Equal(chain[3].Hash(), hash)
+	assert.Equal(chain[3].Hash(), chain[4].ParentHash())
+
+	// as Info, the genesis has no app hash
+	hash, err = appHashAt(chain, 0)
+	assert.Nil(err)
+	assert.Equal(common.Hash{}, hash)
+	assert.Empty(chainInfo(chain[:1]).LastBlockAppHash)
+
+	_, err = appHashAt(chain, 6)
+	assert.NotNil(err)
+	_, err = appHashAt(chain, -1)
+	assert.NotNil(err)
+}
+
+func TestAppHashQueryParams(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{}
+	for _, params := range [][]interface{}{nil, {"5"}, {1.5}, {float64(1), float64(2)}} {
+		_, err := app.appHashQuery(params)
+		assert.NotNil(err, "%v", params)
+	}
+}
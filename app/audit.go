@@ -0,0 +1,90 @@
+package app
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Inconsistency is a discrepancy between the CheckTx state of an account
+// and what the pending txs imply
+type Inconsistency struct {
+	Address  common.Address `json:"address"`
+	Field    string         `json:"field"`
+	Expected *big.Int       `json:"expected"`
+	Actual   *big.Int       `json:"actual"`
+}
+
+// AuditCheckTxState cross-checks the nonce and balance of every account touched by
+// the pending txs against the committed state with those txs applied. After a
+// Commit the pending txs are the residents rechecked so far, which are the only
+// ones applied to the CheckTx state again.
+// Only plain ether transfers are accounted for, the effects of stake and
+// governance txs on the CheckTx state are not.
+// #unstable
+func (app *EthermintApplication) AuditCheckTxState() []Inconsistency {
+	// in the order of Commit, which swaps the CheckTx state and resets the pool
+	app.checkTxStateMtx.Lock()
+	defer app.checkTxStateMtx.Unlock()
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	nonces := make(map[common.Address]uint64)
+	balances := make(map[common.Address]*big.Int)
+	balance := func(addr common.Address) *big.Int {
+		if _, ok := balances[addr]; !ok {
+			// GetBalance hands out the live balance of the state object
+			balances[addr] = new(big.Int).Set(app.pool.base.GetBalance(addr))
+		}
+		return balances[addr]
+	}
+	for _, ptx := range app.pool.txs {
+		if _, ok := nonces[ptx.from]; !ok {
+			nonces[ptx.from] = app.pool.base.GetNonce(ptx.from)
+		}
+		nonces[ptx.from]++
+		b := balance(ptx.from)
+		b.Sub(b, ptx.tx.Value())
+		payer := ptx.from
+		if ptx.payer != (common.Address{}) {
+			payer = ptx.payer
+		}
+		b = balance(payer)
+		b.Sub(b, gasCost(ptx.tx))
+		if to := ptx.tx.To(); to != nil {
+			b := balance(*to)
+			b.Add(b, ptx.tx.Value())
+		}
+	}
+
+	var inconsistencies []Inconsistency
+	for addr, expected := range nonces {
+		if actual := app.checkTxState.GetNonce(addr); actual != expected {
+			inconsistencies = append(inconsistencies, Inconsistency{
+				Address:  addr,
+				Field:    "nonce",
+				Expected: new(big.Int).SetUint64(expected),
+				Actual:   new(big.Int).SetUint64(actual),
+			})
+		}
+	}
+	for addr, expected := range balances {
+		if actual := app.checkTxState.GetBalance(addr); actual.Cmp(expected) != 0 {
+			inconsistencies = append(inconsistencies, Inconsistency{
+				Address:  addr,
+				Field:    "balance",
+				Expected: expected,
+				Actual:   new(big.Int).Set(actual),
+			})
+		}
+	}
+
+	sort.Slice(inconsistencies, func(i, j int) bool {
+		if inconsistencies[i].Address != inconsistencies[j].Address {
+			return inconsistencies[i].Address.Big().Cmp(inconsistencies[j].Address.Big()) < 0
+		}
+		return inconsistencies[i].Field < inconsistencies[j].Field
+	})
+	return inconsistencies
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestAuditCheckTxState(t *testing.T) {
+	assert := assert.New(t)
+
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	st := newTestState()
+	st.AddBalance(from, big.NewInt(1000000))
+
+	app := &EthermintApplication{checkTxState: st, pool: newTxPool(st.Copy())}
+
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		tx := ethTypes.NewTransaction(nonce, to, big.NewInt(100), 21000, big.NewInt(1), nil)
+		applySpeculativeTx(st, from, nonce, tx, CheckTxNew)
+		app.recordPending(from, tx)
+	}
+	assert.Empty(app.AuditCheckTxState())
+
+	// a double debit of the sender is reported
+	st.SubBalance(from, big.NewInt(42))
+	inconsistencies := app.AuditCheckTxState()
+	if assert.Len(inconsistencies, 1) {
+		assert.Equal(from, inconsistencies[0].Address)
+		assert.Equal("balance", inconsistencies[0].Field)
+		assert.Equal(int64(42), new(big.Int).Sub(inconsistencies[0].Expected, inconsistencies[0].Actual).Int64())
+	}
+
+	// so is a skipped nonce increment
+	st.AddBalance(from, big.NewInt(42))
+	st.SetNonce(from, 1)
+	inconsistencies = app.AuditCheckTxState()
+	if assert.Len(inconsistencies, 1) {
+		assert.Equal("nonce", inconsistencies[0].Field)
+		assert.Equal(big.NewInt(2), inconsistencies[0].Expected)
+		assert.Equal(big.NewInt(1), inconsistencies[0].Actual)
+	}
+}
+
+func TestAuditCheckTxStateAfterCommit(t *testing.T) {
+	assert := assert.New(t)
+
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	st := newTestState()
+	st.AddBalance(from, big.NewInt(1000000))
+
+	app := &EthermintApplication{checkTxState: st, pool: newTxPool(st.Copy())}
+
+	var txs []*ethTypes.Transaction
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		tx := ethTypes.NewTransaction(nonce, to, big.NewInt(100), 21000, big.NewInt(1), nil)
+		applySpeculativeTx(st, from, nonce, tx, CheckTxNew)
+		app.recordPending(from, tx)
+		txs = append(txs, tx)
+	}
+
+	// the block commits the first tx, the CheckTx state is reset to it
+	committed := newTestState()
+	committed.AddBalance(from, new(big.Int).Sub(big.NewInt(1000000), txs[0].Cost()))
+	committed.AddBalance(to, big.NewInt(100))
+	committed.SetNonce(from, 1)
+	app.checkTxState = committed
+	app.resetPending(committed)
+	assert.Empty(app.AuditCheckTxState())
+
+	// the residents are applied again as they're rechecked
+	for _, tx := range txs[1:] {
+		assert.Equal(abciTypes.CodeTypeOK,
+			applySpeculativeTx(committed, from, committed.GetNonce(from), tx, CheckTxRecheck).Code)
+		app.recordPending(from, tx)
+		assert.Empty(app.AuditCheckTxState())
+	}
+	assert.Equal(uint64(3), committed.GetNonce(from))
+}
+
+func TestAuditCheckTxStateDelegatedFee(t *testing.T) {
+	assert := assert.New(t)
+
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	payer := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	st := newTestState()
+	st.AddBalance(from, big.NewInt(1000))
+	st.AddBalance(payer, big.NewInt(1000000))
+
+	app := &EthermintApplication{checkTxState: st, pool: newTxPool(st.Copy())}
+
+	// the gas is debited from the payer
+	tx := ethTypes.NewTransaction(0, to, big.NewInt(100), 21000, big.NewInt(1), nil)
+	assert.Equal(abciTypes.CodeTypeOK, applySponsoredTx(st, from, payer, 0, tx, CheckTxNew).Code)
+	app.recordDelegatedPending(from, payer, tx)
+	assert.Empty(app.AuditCheckTxState())
+}
@@ -0,0 +1,138 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// AuditRecord describes a tx delivered in a block
+type AuditRecord struct {
+	Height int64          `json:"height"`
+	Index  uint64         `json:"index"`
+	Hash   common.Hash    `json:"hash"`
+	From   common.Address `json:"from"`
+	// nil for a contract creation
+	To      *common.Address `json:"to"`
+	Value   *hexutil.Big    `json:"value"`
+	GasUsed uint64          `json:"gasUsed"`
+}
+
+// AuditSink receives a record of every tx delivered by DeliverTx, in order.
+// Record is called on the consensus path, it mustn't block.
+type AuditSink interface {
+	Record(rec AuditRecord)
+}
+
+// SetAuditSink sets the sink the delivered txs are recorded to; nil disables it
+// #unstable
+func (app *EthermintApplication) SetAuditSink(sink AuditSink) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.auditSink = sink
+}
+
+func (app *EthermintApplication) getAuditSink() AuditSink {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return app.auditSink
+}
+
+// auditTx records a delivered tx to the audit sink
+func (app *EthermintApplication) auditTx(sink AuditSink, height int64, index uint64,
+	tx *ethTypes.Transaction, gasUsed uint64) {
+
+	// the sender has been recovered by DeliverTx already and is cached
+	from, _ := app.sender(tx)
+	sink.Record(AuditRecord{
+		Height:  height,
+		Index:   index,
+		Hash:    tx.Hash(),
+		From:    from,
+		To:      tx.To(),
+		Value:   (*hexutil.Big)(tx.Value()),
+		GasUsed: gasUsed,
+	})
+}
+
+// FileAuditSink appends the records to a file as newline delimited json. The
+// records are queued and written by a background goroutine, a record coming
+// while the queue is full is dropped and counted.
+type FileAuditSink struct {
+	file    *os.File
+	records chan AuditRecord
+	dropped uint64
+
+	closeOnce sync.Once
+	done      chan struct{}
+	err       error
+}
+
+// NewFileAuditSink opens the file for appending, creating it if needed, with a
+// queue of the given number of records
+func NewFileAuditSink(path string, queue int) (*FileAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, err
+	}
+	sink := &FileAuditSink{
+		file:    file,
+		records: make(chan AuditRecord, queue),
+		done:    make(chan struct{}),
+	}
+	go sink.loop()
+	return sink, nil
+}
+
+// Record implements AuditSink
+func (s *FileAuditSink) Record(rec AuditRecord) {
+	select {
+	case s.records <- rec:
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
+
+// Dropped returns the number of records dropped on a full queue
+func (s *FileAuditSink) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Close writes the queued records and closes the file. No record must be
+// recorded afterwards.
+func (s *FileAuditSink) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.records)
+		<-s.done
+		if err := s.file.Close(); s.err == nil {
+			s.err = err
+		}
+	})
+	return s.err
+}
+
+// loop writes the records, flushing whenever the queue is drained
+func (s *FileAuditSink) loop() {
+	defer close(s.done)
+	w := bufio.NewWriter(s.file)
+	enc := json.NewEncoder(w)
+	for rec := range s.records {
+		if err := enc.Encode(rec); err != nil && s.err == nil {
+			s.err = err
+		}
+		if len(s.records) == 0 {
+			if err := w.Flush(); err != nil && s.err == nil {
+				s.err = err
+			}
+		}
+	}
+	if err := w.Flush(); err != nil && s.err == nil {
+		s.err = err
+	}
+}
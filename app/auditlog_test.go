@@ -0,0 +1,119 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type memoryAuditSink struct {
+	mu      sync.Mutex
+	records []AuditRecord
+}
+
+func (s *memoryAuditSink) Record(rec AuditRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, rec)
+}
+
+func TestAuditDeliveredTxs(t *testing.T) {
+	assert := assert.New(t)
+
+	app, signer := newPendingCapTestApp(0)
+	sink := &memoryAuditSink{}
+	app.SetAuditSink(sink)
+
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	var txs []*ethTypes.Transaction
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		tx, err := ethTypes.SignTx(pricedTx(nonce, 1), signer, key)
+		assert.Nil(err)
+		txs = append(txs, tx)
+	}
+	creation, err := ethTypes.SignTx(ethTypes.NewContractCreation(3, big.NewInt(0), 53000, big.NewInt(1), nil), signer, key)
+	assert.Nil(err)
+	txs = append(txs, creation)
+
+	// the bookkeeping of DeliverTx past the backend
+	for _, tx := range txs {
+		index := app.countDeliveredTx(21000)
+		app.auditTx(app.getAuditSink(), 7, index, tx, 21000)
+	}
+
+	if assert.Len(sink.records, 4) {
+		for i, rec := range sink.records {
+			assert.Equal(int64(7), rec.Height)
+			assert.Equal(uint64(i), rec.Index)
+			assert.Equal(txs[i].Hash(), rec.Hash)
+			assert.Equal(from, rec.From)
+			assert.Equal(uint64(21000), rec.GasUsed)
+		}
+		assert.Equal(*txs[0].To(), *sink.records[0].To)
+		assert.Equal(big.NewInt(1), sink.records[0].Value.ToInt())
+		assert.Nil(sink.records[3].To)
+	}
+
+	app.SetAuditSink(nil)
+	assert.Nil(app.getAuditSink())
+}
+
+func TestFileAuditSink(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "audit")
+	assert.Nil(err)
+	defer os.RemoveAll(dir) // nolint: errcheck
+	path := filepath.Join(dir, "audit.log")
+
+	sink, err := NewFileAuditSink(path, 16)
+	assert.Nil(err)
+	for i := 0; i < 10; i++ {
+		sink.Record(AuditRecord{Height: 1, Index: uint64(i)})
+	}
+	assert.Nil(sink.Close())
+	assert.Equal(uint64(0), sink.Dropped())
+
+	// reopening appends
+	sink, err = NewFileAuditSink(path, 16)
+	assert.Nil(err)
+	sink.Record(AuditRecord{Height: 2})
+	assert.Nil(sink.Close())
+
+	file, err := os.Open(path)
+	assert.Nil(err)
+	defer file.Close() // nolint: errcheck
+	var records []AuditRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var rec AuditRecord
+		assert.Nil(json.Unmarshal(scanner.Bytes(), &rec))
+		records = append(records, rec)
+	}
+	if assert.Len(records, 11) {
+		assert.Equal(uint64(9), records[9].Index)
+		assert.Equal(int64(2), records[10].Height)
+	}
+}
+
+func TestFileAuditSinkDropsWhenFull(t *testing.T) {
+	assert := assert.New(t)
+
+	// no reader: the queue fills up and Record doesn't block
+	sink := &FileAuditSink{records: make(chan AuditRecord, 2)}
+	for i := 0; i < 5; i++ {
+		sink.Record(AuditRecord{Index: uint64(i)})
+	}
+	assert.Equal(uint64(3), sink.Dropped())
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestCheckBalanceLeavesStateUntouched(t *testing.T) {
+	assert := assert.New(t)
+
+	st := newTestState()
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	st.AddBalance(from, big.NewInt(30000))
+
+	tx := ethTypes.NewTransaction(0, to, big.NewInt(100), 21000, big.NewInt(1), nil)
+
+	// successive validations see the same balance
+	assert.Equal(abciTypes.CodeTypeOK, checkBalance(st, from, tx).Code)
+	assert.Equal(abciTypes.CodeTypeOK, checkBalance(st, from, tx).Code)
+	assert.Equal(big.NewInt(30000), st.GetBalance(from))
+
+	expensive := ethTypes.NewTransaction(0, to, big.NewInt(10000), 21000, big.NewInt(1), nil)
+	assert.Equal(errors.CodeTypeBaseInvalidInput, checkBalance(st, from, expensive).Code)
+	assert.Equal(big.NewInt(30000), st.GetBalance(from))
+}
@@ -30,6 +30,8 @@ type BaseApp struct {
 	*StoreApp
 	EthApp              *EthermintApplication
 	checkedTx           map[common.Hash]*types.Transaction
+	// txs admitted to the mempool before the last Commit, rechecked from then on
+	residentTx          map[common.Hash]struct{}
 	ethereum            *eth.Ethereum
 	AbsentValidators    *stake.AbsentValidators
 	ByzantineValidators []abci.Evidence
@@ -111,10 +113,48 @@ func (app *BaseApp) Info(req abci.RequestInfo) abci.ResponseInfo {
 	return travisInfoRes
 }
 
+// InitChain - ABCI
+func (app *BaseApp) InitChain(req abci.RequestInitChain) abci.ResponseInitChain {
+	return app.EthApp.InitChain(req)
+}
+
+// SetOption - ABCI
+func (app *BaseApp) SetOption(req abci.RequestSetOption) abci.ResponseSetOption {
+	return app.EthApp.SetOption(req)
+}
+
+// ethQueryPaths are the query paths answered by the EthermintApplication, the
+// json-rpc requests; the other paths are the store queries of the StoreApp
+var ethQueryPaths = map[string]bool{
+	"":           true,
+	"/":          true,
+	queryPathV2: true,
+}
+
+// Query - ABCI
+func (app *BaseApp) Query(req abci.RequestQuery) abci.ResponseQuery {
+	if ethQueryPaths[req.Path] {
+		return app.EthApp.Query(req)
+	}
+	return app.StoreApp.Query(req)
+}
+
 // DeliverTx - ABCI
 func (app *BaseApp) DeliverTx(txBytes []byte) abci.ResponseDeliverTx {
-	tx, err := decodeTx(txBytes)
-	if err != nil {
+	var (
+		tx    *types.Transaction
+		payer common.Address
+		err   error
+	)
+	// EIP-2718 typed txs aren't rlp lists and can't be decoded as legacy txs,
+	// a delegated fee tx carries one with the account paying its gas
+	if isTypedTxEnvelope(txBytes) {
+		var code uint32
+		if tx, payer, code, err = openTypedTx(txBytes); err != nil {
+			app.logger.Error("DeliverTx: Received invalid typed transaction", "err", err)
+			return abci.ResponseDeliverTx{Code: code, Log: err.Error()}
+		}
+	} else if tx, err = decodeTx(txBytes); err != nil {
 		app.logger.Error("DeliverTx: Received invalid transaction", "err", err)
 		return errors.DeliverResult(err)
 	}
@@ -122,17 +162,16 @@ func (app *BaseApp) DeliverTx(txBytes []byte) abci.ResponseDeliverTx {
 	if utils.IsEthTx(tx) {
 		if checkedTx, ok := app.checkedTx[tx.Hash()]; ok {
 			tx = checkedTx
+			// a delivered tx leaves the mempool
+			delete(app.checkedTx, tx.Hash())
 		} else {
-			// force cache from of tx
-			networkId := big.NewInt(int64(app.ethereum.NetVersion()))
-			signer := types.NewEIP155Signer(networkId)
-
-			if _, err := types.Sender(signer, tx); err != nil {
+			// force cache from of tx, recovered with the signer of the CheckTx
+			if _, err := app.EthApp.sender(tx); err != nil {
 				app.logger.Debug("DeliverTx: Received invalid transaction", "tx", tx, "err", err)
 				return errors.DeliverResult(err)
 			}
 		}
-		resp := app.EthApp.DeliverTx(tx)
+		resp := app.EthApp.deliverTx(tx, payer)
 		app.logger.Debug("EthApp DeliverTx response", "resp", resp)
 		return resp
 	}
@@ -143,16 +182,53 @@ func (app *BaseApp) DeliverTx(txBytes []byte) abci.ResponseDeliverTx {
 	return app.deliverHandler(ctx, app.Append(), tx)
 }
 
+// checkTxType tells a recheck from the first check of a tx. Tendermint v0.22
+// doesn't flag the rechecks, which it runs after Commit on the txs left in its
+// mempool, so a tx admitted before the last Commit is taken for a recheck.
+func (app *BaseApp) checkTxType(hash common.Hash) CheckTxType {
+	if _, ok := app.residentTx[hash]; ok {
+		return CheckTxRecheck
+	}
+	return CheckTxNew
+}
+
+// keepResidentTxs records the txs admitted and not delivered since the last Commit
+// as the residents of the mempool, the ones which don't pass their recheck are
+// forgotten on the next Commit
+func (app *BaseApp) keepResidentTxs() {
+	app.residentTx = make(map[common.Hash]struct{}, len(app.checkedTx))
+	for hash := range app.checkedTx {
+		app.residentTx[hash] = struct{}{}
+	}
+	app.checkedTx = make(map[common.Hash]*types.Transaction)
+}
+
 // CheckTx - ABCI
 func (app *BaseApp) CheckTx(txBytes []byte) abci.ResponseCheckTx {
-	tx, err := decodeTx(txBytes)
-	if err != nil {
+	if resp := app.EthApp.checkPaused(); resp.IsErr() {
+		return resp
+	}
+
+	var (
+		tx    *types.Transaction
+		payer common.Address
+		err   error
+	)
+	// EIP-2718 typed txs aren't rlp lists and can't be decoded as legacy txs,
+	// a delegated fee tx carries one with the account paying its gas
+	if isTypedTxEnvelope(txBytes) {
+		var code uint32
+		if tx, payer, code, err = openTypedTx(txBytes); err != nil {
+			app.logger.Error("CheckTx: Received invalid typed transaction", "err", err)
+			return abci.ResponseCheckTx{Code: code, Log: err.Error()}
+		}
+	} else if tx, err = decodeTx(txBytes); err != nil {
 		app.logger.Error("CheckTx: Received invalid transaction", "err", err)
 		return errors.CheckResult(err)
 	}
 
 	if utils.IsEthTx(tx) {
-		resp := app.EthApp.CheckTx(tx)
+		resp := app.EthApp.checkTx(tx, payer, app.checkTxType(tx.Hash()), false)
 		app.logger.Debug("EthApp CheckTx response", "resp", resp)
 		if resp.IsErr() {
 			return errors.CheckResult(goerr.New(resp.String()))
@@ -163,6 +239,9 @@ func (app *BaseApp) CheckTx(txBytes []byte) abci.ResponseCheckTx {
 
 	app.logger.Info("CheckTx: Received valid transaction", "tx", tx)
 
+	// the handler reads and bumps the nonce in the CheckTx state, which Commit swaps
+	app.EthApp.checkTxStateMtx.Lock()
+	defer app.EthApp.checkTxStateMtx.Unlock()
 	ctx := ttypes.NewContext(app.GetChainID(), app.WorkingHeight(), app.blockTime, app.EthApp.checkTxState)
 	return app.checkHandler(ctx, app.Check(), tx)
 }
@@ -216,7 +295,7 @@ func (app *BaseApp) BeginBlock(req abci.RequestBeginBlock) (res abci.ResponseBeg
 
 // EndBlock - ABCI - triggers Tick actions
 func (app *BaseApp) EndBlock(req abci.RequestEndBlock) (res abci.ResponseEndBlock) {
-	app.EthApp.EndBlock(req)
+	ethRes := app.EthApp.EndBlock(req)
 	utils.BlockGasFee = big.NewInt(0).Add(utils.BlockGasFee, app.TotalUsedGasFee)
 
 	var backups stake.Validators
@@ -243,6 +322,10 @@ func (app *BaseApp) EndBlock(req abci.RequestEndBlock) (res abci.ResponseEndBloc
 	// punish the absent validators
 	for k, v := range app.AbsentValidators.Validators {
 		stake.PunishAbsentValidator(k, v)
+		if v.GetCount() == utils.GetParams().MaxSlashingBlocks {
+			// the validator has been removed, start counting afresh
+			app.EthApp.ResetLiveness(k.Address())
+		}
 	}
 
 	// execute tick if present
@@ -255,11 +338,15 @@ func (app *BaseApp) EndBlock(req abci.RequestEndBlock) (res abci.ResponseEndBloc
 	// handle the pending unstake requests
 	stake.HandlePendingUnstakeRequests(app.WorkingHeight(), app.Append())
 
-	return app.StoreApp.EndBlock(req)
+	res = app.StoreApp.EndBlock(req)
+	// the updates of the stake module are the ones handed to tendermint
+	res.ValidatorUpdates = app.EthApp.updateValidators(res.ValidatorUpdates)
+	res.ConsensusParamUpdates = ethRes.ConsensusParamUpdates
+	return res
 }
 
 func (app *BaseApp) Commit() (res abci.ResponseCommit) {
-	app.checkedTx = make(map[common.Hash]*types.Transaction)
+	app.keepResidentTxs()
 	ethAppCommit := app.EthApp.Commit()
 	if len(ethAppCommit.Data) == 0 {
 		// Rollback transaction
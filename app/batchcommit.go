@@ -0,0 +1,63 @@
+package app
+
+import (
+	goerr "errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+)
+
+// SetBatchedCommit switches the batched commit mode, meant for catching up on
+// many blocks. In batched mode Commit doesn't rebuild the CheckTx state after
+// each block, FlushState persists the head state and rebuilds it once at the
+// end of the batch. Turning it off flushes.
+// #unstable
+func (app *EthermintApplication) SetBatchedCommit(batched bool) error {
+	app.commitMtx.Lock()
+	wasBatched := app.batchedCommit
+	app.batchedCommit = batched
+	app.commitMtx.Unlock()
+
+	if wasBatched && !batched {
+		return app.FlushState()
+	}
+	return nil
+}
+
+// FlushState writes the state trie of the last committed block to disk and
+// rebuilds the CheckTx state on it
+// #unstable
+func (app *EthermintApplication) FlushState() error {
+	app.commitMtx.Lock()
+	defer app.commitMtx.Unlock()
+	if app.closed {
+		return goerr.New("flush on a closed application")
+	}
+
+	if err := app.backend.FlushState(); err != nil {
+		return err
+	}
+	return app.refreshCheckTxState()
+}
+
+// refreshCheckTxState resets the CheckTx state to the last committed block,
+// dropping the mempool bookkeeping made obsolete by it
+func (app *EthermintApplication) refreshCheckTxState() error {
+	if err := app.faults.inject(faultResetState); err != nil {
+		return err
+	}
+	managed, err := app.backend.ResetState()
+	if err != nil {
+		return err
+	}
+	app.checkTxStateMtx.Lock()
+	defer app.checkTxStateMtx.Unlock()
+	app.resetCheckTxState(managed.StateDB)
+	return nil
+}
+
+// commitTrie writes the trie of the given state root, held in memory by the trie
+// database until then, to disk
+func commitTrie(db state.Database, root common.Hash) error {
+	return db.TrieDB().Commit(root, false)
+}
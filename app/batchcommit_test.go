@@ -0,0 +1,76 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// commitBlocks commits a balance change per block, flushing the trie to disk
+// every flushEvery blocks and after the last one
+func commitBlocks(db state.Database, blocks, flushEvery int) (common.Hash, error) {
+	var root common.Hash
+	for i := 0; i < blocks; i++ {
+		st, err := state.New(root, db)
+		if err != nil {
+			return root, err
+		}
+		st.AddBalance(common.BigToAddress(big.NewInt(int64(i%64+1))), big.NewInt(1))
+		if root, err = st.Commit(false); err != nil {
+			return root, err
+		}
+		if (i+1)%flushEvery == 0 {
+			if err := commitTrie(db, root); err != nil {
+				return root, err
+			}
+		}
+	}
+	return root, commitTrie(db, root)
+}
+
+func TestCommitTrie(t *testing.T) {
+	assert := assert.New(t)
+
+	diskdb := ethdb.NewMemDatabase()
+	db := state.NewDatabase(diskdb)
+	st, _ := state.New(common.Hash{}, db)
+	addr := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	st.AddBalance(addr, big.NewInt(42))
+	root, err := st.Commit(false)
+	assert.Nil(err)
+
+	// only in memory until flushed
+	_, err = state.New(root, state.NewDatabase(diskdb))
+	assert.NotNil(err)
+
+	assert.Nil(commitTrie(db, root))
+	reopened, err := state.New(root, state.NewDatabase(diskdb))
+	assert.Nil(err)
+	assert.Equal(big.NewInt(42), reopened.GetBalance(addr))
+}
+
+func TestBatchedCommitSameState(t *testing.T) {
+	assert := assert.New(t)
+
+	perBlock, err := commitBlocks(state.NewDatabase(ethdb.NewMemDatabase()), 100, 1)
+	assert.Nil(err)
+	batched, err := commitBlocks(state.NewDatabase(ethdb.NewMemDatabase()), 100, 100)
+	assert.Nil(err)
+	assert.Equal(perBlock, batched)
+}
+
+func benchmarkCommitBlocks(b *testing.B, flushEvery int) {
+	for i := 0; i < b.N; i++ {
+		if _, err := commitBlocks(state.NewDatabase(ethdb.NewMemDatabase()), 1000, flushEvery); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCommitFlushPerBlock(b *testing.B) { benchmarkCommitBlocks(b, 1) }
+func BenchmarkCommitFlushBatched(b *testing.B)  { benchmarkCommitBlocks(b, 1000) }
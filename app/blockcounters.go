@@ -0,0 +1,36 @@
+package app
+
+// blockCounters aggregates the txs delivered in the current block
+type blockCounters struct {
+	TxCount uint64 `json:"tx_count"`
+	GasUsed uint64 `json:"gas_used"`
+}
+
+// countDeliveredTx accounts a tx delivered in the current block and returns its
+// index in the block
+func (app *EthermintApplication) countDeliveredTx(gasUsed uint64) uint64 {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	index := app.block.TxCount
+	app.block.TxCount++
+	app.block.GasUsed += gasUsed
+	return index
+}
+
+// blockCounters returns the counters of the current block
+func (app *EthermintApplication) blockCounters() blockCounters {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	return app.block
+}
+
+// resetBlockCounters starts counting a new block
+func (app *EthermintApplication) resetBlockCounters() {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.block = blockCounters{}
+	app.delivered = nil
+}
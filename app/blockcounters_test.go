@@ -0,0 +1,21 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockCounters(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{}
+	app.countDeliveredTx(21000)
+	app.countDeliveredTx(50000)
+	app.countDeliveredTx(30000)
+	assert.Equal(blockCounters{TxCount: 3, GasUsed: 101000}, app.blockCounters())
+
+	// Commit starts a new block
+	app.resetBlockCounters()
+	assert.Equal(blockCounters{}, app.blockCounters())
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// buffered block events of a consumer, the oldest is dropped for a slow consumer
+const blockEventBuffer = 16
+
+// BlockEvent notifies a block committed by Commit
+type BlockEvent struct {
+	Height uint64
+	Hash   common.Hash
+}
+
+// blockFeed fans out the block events to the consumers without ever blocking Commit
+type blockFeed struct {
+	mu        sync.Mutex
+	consumers map[int]chan BlockEvent
+	nextID    int
+	// events dropped from the buffer of a slow consumer
+	dropped uint64
+}
+
+// SubscribeBlocks registers a consumer of the blocks committed from now on. The
+// channel is closed once cancel is called. A consumer falling behind loses its
+// oldest events, see DroppedBlockEvents.
+// #unstable
+func (app *EthermintApplication) SubscribeBlocks() (<-chan BlockEvent, func()) {
+	f := &app.blockFeed
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.consumers == nil {
+		f.consumers = make(map[int]chan BlockEvent)
+	}
+	id := f.nextID
+	f.nextID++
+	ch := make(chan BlockEvent, blockEventBuffer)
+	f.consumers[id] = ch
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			delete(f.consumers, id)
+			close(ch)
+		})
+	}
+	return ch, cancel
+}
+
+// DroppedBlockEvents returns the number of block events dropped for slow consumers
+// #unstable
+func (app *EthermintApplication) DroppedBlockEvents() uint64 {
+	app.blockFeed.mu.Lock()
+	defer app.blockFeed.mu.Unlock()
+
+	return app.blockFeed.dropped
+}
+
+// publishBlockEvent notifies the consumers of a committed block. A full buffer
+// has its oldest event dropped to make room.
+func (app *EthermintApplication) publishBlockEvent(height uint64, hash common.Hash) {
+	f := &app.blockFeed
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ev := BlockEvent{Height: height, Hash: hash}
+	for _, ch := range f.consumers {
+		select {
+		case ch <- ev:
+			continue
+		default:
+		}
+		select {
+		case <-ch:
+			f.dropped++
+		default:
+		}
+		// only the publisher sends, under the lock, so there is room now
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func blockHashAt(height uint64) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(height))
+}
+
+func TestBlockEvents(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{}
+	events, cancel := app.SubscribeBlocks()
+	slow, cancelSlow := app.SubscribeBlocks()
+	defer cancelSlow()
+
+	for height := uint64(1); height <= 3; height++ {
+		app.publishBlockEvent(height, blockHashAt(height))
+	}
+	for height := uint64(1); height <= 3; height++ {
+		ev := <-events
+		assert.Equal(height, ev.Height)
+		assert.Equal(blockHashAt(height), ev.Hash)
+	}
+
+	// the slow consumer loses its oldest events, without blocking the publisher
+	for height := uint64(4); height <= blockEventBuffer+2; height++ {
+		app.publishBlockEvent(height, blockHashAt(height))
+	}
+	assert.Equal(uint64(2), app.DroppedBlockEvents())
+	assert.Equal(blockEventBuffer, len(slow))
+	ev := <-slow
+	assert.Equal(uint64(3), ev.Height)
+	assert.Equal(blockHashAt(3), ev.Hash)
+
+	// a cancelled consumer has its channel closed and gets nothing more
+	cancel()
+	cancel()
+	for range events {
+	}
+	app.publishBlockEvent(blockEventBuffer+3, blockHashAt(blockEventBuffer+3))
+	_, ok := <-events
+	assert.False(ok)
+}
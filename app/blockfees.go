@@ -0,0 +1,108 @@
+package app
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// blockFeesKept is the number of recently committed blocks whose fees are kept
+const blockFeesKept = 1024
+
+// blockFees are the tx fees collected in a committed block, apart from the
+// validator rewards, along with the running total since the node started
+type blockFees struct {
+	Height hexutil.Uint64 `json:"height"`
+	Fees   *hexutil.Big   `json:"fees"`
+	Total  *hexutil.Big   `json:"total"`
+}
+
+// feeLedger accumulates the fees, gas used * gas price, of the delivered txs
+type feeLedger struct {
+	// fees of the txs delivered in the current block
+	current *big.Int
+	// fees of the recently committed blocks by height
+	committed map[uint64]*big.Int
+	// fees of all the blocks committed since the node started
+	total *big.Int
+	// height of the last committed block, 0 if none
+	latest uint64
+}
+
+// record adds the fee of a delivered tx to the current block
+func (l *feeLedger) record(gasUsed uint64, price *big.Int) {
+	if l.current == nil {
+		l.current = new(big.Int)
+	}
+	fee := new(big.Int).SetUint64(gasUsed)
+	l.current.Add(l.current, fee.Mul(fee, price))
+}
+
+// commitBlock stores the fees of the block committed at height and starts the
+// next block
+func (l *feeLedger) commitBlock(height uint64) {
+	fees := l.current
+	if fees == nil {
+		fees = new(big.Int)
+	}
+	if l.committed == nil {
+		l.committed = make(map[uint64]*big.Int)
+		l.total = new(big.Int)
+	}
+	l.committed[height] = fees
+	if height >= blockFeesKept {
+		delete(l.committed, height-blockFeesKept)
+	}
+	l.total.Add(l.total, fees)
+	l.latest = height
+	l.current = nil
+}
+
+// recordBlockFee adds the fee of a tx delivered with gasUsed to the current block
+func (app *EthermintApplication) recordBlockFee(tx *ethTypes.Transaction, gasUsed uint64) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.fees.record(gasUsed, tx.GasPrice())
+}
+
+// commitBlockFees closes the fees of the block committed at height
+func (app *EthermintApplication) commitBlockFees(height uint64) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.fees.commitBlock(height)
+}
+
+// blockFeesQuery serves travis_blockFees, whose optional param is the height;
+// the last committed block by default. The total isn't persisted, it starts over
+// when the node restarts.
+func (app *EthermintApplication) blockFeesQuery(params []interface{}) (*blockFees, error) {
+	if len(params) > 1 {
+		return nil, fmt.Errorf("expected at most 1 param, got %d", len(params))
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	height := app.fees.latest
+	if len(params) == 1 {
+		// json numbers are decoded as float64
+		h, ok := params[0].(float64)
+		if !ok || h < 0 || h != float64(uint64(h)) {
+			return nil, fmt.Errorf("invalid height: %v", params[0])
+		}
+		height = uint64(h)
+	}
+	fees, ok := app.fees.committed[height]
+	if !ok {
+		return nil, fmt.Errorf("no fees for height %d", height)
+	}
+	return &blockFees{
+		Height: hexutil.Uint64(height),
+		Fees:   (*hexutil.Big)(new(big.Int).Set(fees)),
+		Total:  (*hexutil.Big)(new(big.Int).Set(app.fees.total)),
+	}, nil
+}
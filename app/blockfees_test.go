@@ -0,0 +1,62 @@
+package app
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+)
+
+func TestBlockFees(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{logger: tmLog.NewNopLogger()}
+	_, err := app.blockFeesQuery(nil)
+	assert.NotNil(err)
+
+	// block 1: 21000 * 2 gwei + 50000 * 3 gwei
+	app.recordBlockFee(pricedTx(0, 2e9), 21000)
+	app.recordBlockFee(pricedTx(1, 3e9), 50000)
+	app.commitBlockFees(1)
+	// block 2 without txs
+	app.commitBlockFees(2)
+	// block 3: 30000 * 1 gwei
+	app.recordBlockFee(pricedTx(2, 1e9), 30000)
+	app.commitBlockFees(3)
+
+	fees1 := big.NewInt(21000*2e9 + 50000*3e9)
+	fees3 := big.NewInt(30000 * 1e9)
+	total := new(big.Int).Add(fees1, fees3)
+
+	latest, err := app.blockFeesQuery(nil)
+	assert.Nil(err)
+	assert.Equal(hexutil.Uint64(3), latest.Height)
+	assert.Equal(fees3.String(), latest.Fees.ToInt().String())
+	assert.Equal(total.String(), latest.Total.ToInt().String())
+
+	first, err := app.blockFeesQuery([]interface{}{float64(1)})
+	assert.Nil(err)
+	assert.Equal(fees1.String(), first.Fees.ToInt().String())
+	assert.Equal(total.String(), first.Total.ToInt().String())
+	empty, err := app.blockFeesQuery([]interface{}{float64(2)})
+	assert.Nil(err)
+	assert.Equal("0", empty.Fees.ToInt().String())
+
+	_, err = app.blockFeesQuery([]interface{}{float64(4)})
+	assert.NotNil(err)
+	_, err = app.blockFeesQuery([]interface{}{"1"})
+	assert.NotNil(err)
+
+	// through Query
+	data, _ := json.Marshal(jsonRequest{Method: "travis_blockFees", Params: []interface{}{1}})
+	res := app.Query(abciTypes.RequestQuery{Data: data})
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+	var out blockFees
+	assert.Nil(json.Unmarshal(res.Value, &out))
+	assert.Equal(fees1.String(), out.Fees.ToInt().String())
+}
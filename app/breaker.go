@@ -0,0 +1,61 @@
+package app
+
+import (
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+	"github.com/CyberMiles/travis/utils"
+)
+
+// deliveryBreaker trips after DeliveryBreakerThreshold consecutive errors of the
+// backend in DeliverTx, the remaining txs of the block are then failed without
+// reaching it. The threshold is a chain parameter, since the breaker decides which
+// txs of a block are executed.
+type deliveryBreaker struct {
+	failures uint64
+	open     bool
+}
+
+// deliverThroughBreaker delivers the tx with deliver unless the breaker is open,
+// and trips it once the backend errored threshold times in a row. Only the internal
+// errors of the backend count, a tx refused as invalid shows it's working.
+func (app *EthermintApplication) deliverThroughBreaker(tx *ethTypes.Transaction,
+	deliver func(*ethTypes.Transaction) abciTypes.ResponseDeliverTx) abciTypes.ResponseDeliverTx {
+
+	app.mu.Lock()
+	open := app.breaker.open
+	app.mu.Unlock()
+	if open {
+		return abciTypes.ResponseDeliverTx{
+			Code: errors.CodeTypeBreakerOpen,
+			Log:  "Backend failing, transaction not delivered until the next block"}
+	}
+
+	res := deliver(tx)
+
+	threshold := utils.GetParams().DeliveryBreakerThreshold
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	if res.Code != errors.CodeTypeInternalErr {
+		app.breaker.failures = 0
+		return res
+	}
+	app.breaker.failures++
+	if threshold > 0 && app.breaker.failures >= threshold {
+		app.breaker.open = true
+		// nolint: errcheck
+		app.logger.Error("DeliverTx: Backend failing, the rest of the block is not delivered",
+			"consecutiveErrors", app.breaker.failures)
+	}
+	return res
+}
+
+// resetDeliveryBreaker closes the breaker once a block is committed
+func (app *EthermintApplication) resetDeliveryBreaker() {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.breaker.failures = 0
+	app.breaker.open = false
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+
+	"github.com/CyberMiles/travis/errors"
+	"github.com/CyberMiles/travis/utils"
+)
+
+func TestDeliveryBreaker(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{logger: tmLog.NewNopLogger()}
+	assert.True(utils.SetParam("delivery_breaker_threshold", "3"))
+	defer utils.SetParam("delivery_breaker_threshold", "0")
+
+	calls := 0
+	failing := func(tx *ethTypes.Transaction) abciTypes.ResponseDeliverTx {
+		calls++
+		return abciTypes.ResponseDeliverTx{Code: errors.CodeTypeInternalErr, Log: "missing trie node"}
+	}
+	succeeding := func(tx *ethTypes.Transaction) abciTypes.ResponseDeliverTx {
+		calls++
+		return abciTypes.ResponseDeliverTx{Code: abciTypes.CodeTypeOK}
+	}
+	invalid := func(tx *ethTypes.Transaction) abciTypes.ResponseDeliverTx {
+		calls++
+		return abciTypes.ResponseDeliverTx{Code: errors.CodeTypeBaseInvalidInput, Log: "nonce too high"}
+	}
+	tx := ethTypes.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil)
+
+	// a success in between restarts the count
+	app.deliverThroughBreaker(tx, failing)
+	app.deliverThroughBreaker(tx, failing)
+	app.deliverThroughBreaker(tx, succeeding)
+	// and so does an invalid tx, the backend answered
+	app.deliverThroughBreaker(tx, failing)
+	app.deliverThroughBreaker(tx, failing)
+	for i := 0; i < 5; i++ {
+		res := app.deliverThroughBreaker(tx, invalid)
+		assert.Equal(errors.CodeTypeBaseInvalidInput, res.Code)
+	}
+	for i := 0; i < 3; i++ {
+		res := app.deliverThroughBreaker(tx, failing)
+		assert.Equal(errors.CodeTypeInternalErr, res.Code)
+	}
+	assert.Equal(13, calls)
+
+	// tripped, the backend isn't called anymore in this block
+	for i := 0; i < 10; i++ {
+		res := app.deliverThroughBreaker(tx, succeeding)
+		assert.Equal(errors.CodeTypeBreakerOpen, res.Code)
+	}
+	assert.Equal(13, calls)
+
+	// reset by the next Commit
+	app.resetDeliveryBreaker()
+	res := app.deliverThroughBreaker(tx, succeeding)
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+	assert.Equal(14, calls)
+}
+
+func TestDeliveryBreakerDisabled(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{logger: tmLog.NewNopLogger()}
+	failing := func(tx *ethTypes.Transaction) abciTypes.ResponseDeliverTx {
+		return abciTypes.ResponseDeliverTx{Code: errors.CodeTypeInternalErr}
+	}
+	tx := ethTypes.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil)
+	for i := 0; i < 100; i++ {
+		res := app.deliverThroughBreaker(tx, failing)
+		assert.Equal(errors.CodeTypeInternalErr, res.Code)
+	}
+}
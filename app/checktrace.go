@@ -0,0 +1,156 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+)
+
+// checkTracesKept is the number of completed CheckTx traces kept, the oldest
+// are dropped first
+const checkTracesKept = 256
+
+// checkTraceStep is the outcome of a validation step of CheckTx
+type checkTraceStep struct {
+	Step string `json:"step"`
+	Code uint32 `json:"code"`
+	Log  string `json:"log,omitempty"`
+}
+
+// checkTrace records the validation steps of a CheckTx of a tx, up to the
+// failing one, and the response
+type checkTrace struct {
+	Hash    common.Hash      `json:"hash"`
+	Recheck bool             `json:"recheck"`
+	Steps   []checkTraceStep `json:"steps"`
+	Code    uint32           `json:"code"`
+	Log     string           `json:"log,omitempty"`
+}
+
+// checkTracer traces the CheckTx of the txs whose hash is in the filter, so that
+// a rejection can be explained without the debug logs of every tx
+type checkTracer struct {
+	mu     sync.Mutex
+	filter map[common.Hash]struct{}
+	// traces of the CheckTx in progress
+	active map[common.Hash]*checkTrace
+	// completed traces, the oldest first
+	traces []checkTrace
+}
+
+// parseTxHashes parses a comma separated list of tx hashes; an empty value gives
+// an empty set
+func parseTxHashes(value string) (map[common.Hash]struct{}, error) {
+	hashes := make(map[common.Hash]struct{})
+	for _, hex := range strings.Split(value, ",") {
+		hex = strings.TrimSpace(hex)
+		if hex == "" {
+			continue
+		}
+		if len(strings.TrimPrefix(hex, "0x")) != 2*common.HashLength {
+			return nil, fmt.Errorf("invalid tx hash: %s", hex)
+		}
+		hashes[common.HexToHash(hex)] = struct{}{}
+	}
+	return hashes, nil
+}
+
+// setFilter replaces the traced tx hashes; an empty filter disables the tracing
+func (t *checkTracer) setFilter(filter map[common.Hash]struct{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.filter = filter
+}
+
+// begin starts the trace of a CheckTx if the tx is traced
+func (t *checkTracer) begin(hash common.Hash, checkType CheckTxType) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.filter[hash]; !ok {
+		return
+	}
+	if t.active == nil {
+		t.active = make(map[common.Hash]*checkTrace)
+	}
+	t.active[hash] = &checkTrace{Hash: hash, Recheck: checkType == CheckTxRecheck}
+}
+
+// step records the outcome of a step of a traced CheckTx in progress
+func (t *checkTracer) step(hash common.Hash, name string, resp abciTypes.ResponseCheckTx) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if trace, ok := t.active[hash]; ok {
+		trace.Steps = append(trace.Steps, checkTraceStep{Step: name, Code: resp.Code, Log: resp.Log})
+	}
+}
+
+// end completes the trace of a CheckTx with its response
+func (t *checkTracer) end(hash common.Hash, resp abciTypes.ResponseCheckTx) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	trace, ok := t.active[hash]
+	if !ok {
+		return
+	}
+	delete(t.active, hash)
+	trace.Code, trace.Log = resp.Code, resp.Log
+	t.traces = append(t.traces, *trace)
+	if over := len(t.traces) - checkTracesKept; over > 0 {
+		t.traces = append([]checkTrace(nil), t.traces[over:]...)
+	}
+}
+
+// find returns the completed traces of a tx, the oldest first, or all of them
+// for a nil hash
+func (t *checkTracer) find(hash *common.Hash) []checkTrace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	traces := []checkTrace{}
+	for _, trace := range t.traces {
+		if hash == nil || trace.Hash == *hash {
+			traces = append(traces, trace)
+		}
+	}
+	return traces
+}
+
+// traceStep records the outcome of a validation step of a traced tx and returns
+// it, so that it wraps the checks in place
+func (app *EthermintApplication) traceStep(tx *ethTypes.Transaction, name string,
+	resp abciTypes.ResponseCheckTx) abciTypes.ResponseCheckTx {
+
+	app.checkTraces.step(tx.Hash(), name, resp)
+	return resp
+}
+
+// tracePass records validation steps passed by a traced tx
+func (app *EthermintApplication) tracePass(tx *ethTypes.Transaction, names ...string) {
+	for _, name := range names {
+		app.checkTraces.step(tx.Hash(), name, abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK})
+	}
+}
+
+// traceCheckQuery serves travis_traceCheck, whose optional param is the tx hash;
+// all the kept traces by default
+func (app *EthermintApplication) traceCheckQuery(params []interface{}) ([]checkTrace, error) {
+	switch len(params) {
+	case 0:
+		return app.checkTraces.find(nil), nil
+	case 1:
+		hex, ok := params[0].(string)
+		if !ok || len(strings.TrimPrefix(hex, "0x")) != 2*common.HashLength {
+			return nil, fmt.Errorf("invalid tx hash: %v", params[0])
+		}
+		hash := common.HexToHash(hex)
+		return app.checkTraces.find(&hash), nil
+	}
+	return nil, fmt.Errorf("expected at most 1 param, got %d", len(params))
+}
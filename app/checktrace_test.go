@@ -0,0 +1,85 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestCheckTxTrace(t *testing.T) {
+	assert := assert.New(t)
+
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	st := newTestState()
+	st.SetNonce(from, 3)
+	app := &EthermintApplication{logger: tmLog.NewNopLogger(), checkTxState: st}
+
+	traced, other := pricedTx(1, 1), pricedTx(3, 1)
+	assert.Nil(app.setOption("trace_check", traced.Hash().Hex()))
+
+	// the nonce check fails the traced tx, as in CheckTx; the app has no backend
+	// to run the steps before it
+	app.checkTraces.begin(traced.Hash(), CheckTxNew)
+	app.tracePass(traced, "size", "chain_id", "signature")
+	_, resp := app.checkNonce(st, from, traced)
+	app.checkTraces.end(traced.Hash(), resp)
+	assert.Equal(errors.CodeTypeBadNonce, resp.Code)
+
+	// the untraced tx isn't recorded
+	app.checkTraces.begin(other.Hash(), CheckTxNew)
+	_, resp = app.checkNonce(st, from, other)
+	app.checkTraces.end(other.Hash(), resp)
+	assert.Equal(abciTypes.CodeTypeOK, resp.Code)
+
+	traces, err := app.traceCheckQuery([]interface{}{traced.Hash().Hex()})
+	assert.Nil(err)
+	assert.Len(traces, 1)
+	trace := traces[0]
+	assert.False(trace.Recheck)
+	assert.Equal(errors.CodeTypeBadNonce, trace.Code)
+	assert.Len(trace.Steps, 4)
+	failed := trace.Steps[len(trace.Steps)-1]
+	assert.Equal("nonce", failed.Step)
+	assert.Equal(errors.CodeTypeBadNonce, failed.Code)
+	assert.Equal("Nonce not strictly increasing. Expected 3 Got 1", failed.Log)
+	for _, step := range trace.Steps[:3] {
+		assert.Equal(abciTypes.CodeTypeOK, step.Code)
+	}
+	traces, _ = app.traceCheckQuery([]interface{}{other.Hash().Hex()})
+	assert.Empty(traces)
+
+	// through CheckTx, a paused app fails the first step
+	assert.Nil(app.setOption("paused", "true"))
+	app.CheckTx(traced, CheckTxRecheck)
+	data, _ := json.Marshal(jsonRequest{Method: "travis_traceCheck"})
+	res := app.Query(abciTypes.RequestQuery{Data: data})
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+	assert.Nil(json.Unmarshal(res.Value, &traces))
+	assert.Len(traces, 2)
+	assert.True(traces[1].Recheck)
+	assert.Equal("paused", traces[1].Steps[0].Step)
+	assert.NotEqual(abciTypes.CodeTypeOK, traces[1].Code)
+
+	// the buffer is bounded
+	for i := 0; i < checkTracesKept; i++ {
+		app.CheckTx(traced, CheckTxNew)
+	}
+	traces, _ = app.traceCheckQuery(nil)
+	assert.Len(traces, checkTracesKept)
+
+	// an empty filter disables the tracing
+	assert.Nil(app.setOption("trace_check", ""))
+	app.CheckTx(other, CheckTxNew)
+	traces, _ = app.traceCheckQuery([]interface{}{other.Hash().Hex()})
+	assert.Empty(traces)
+	assert.NotNil(app.setOption("trace_check", "0x12"))
+	_, err = app.traceCheckQuery([]interface{}{"0x12"})
+	assert.NotNil(err)
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// CheckTxType tells the first check of a tx apart from a mempool recheck
+type CheckTxType int
+
+const (
+	// CheckTxNew is the first check of a tx entering the mempool
+	CheckTxNew CheckTxType = iota
+	// CheckTxRecheck is a recheck of a tx already in the mempool
+	CheckTxRecheck
+)
+
+// applySpeculativeTx applies the effects of an admitted tx to the CheckTx state, so
+// that the following txs are checked against it. A recheck doesn't apply them again,
+// unless the state doesn't hold them anymore: once Commit reset it to the block, a
+// resident tx is the next tx of its sender again. A resident left with a nonce gap,
+// a tx of its sender before it having left the mempool, fails its recheck.
+// The balance is checked again right before the debit, so that whatever the order
+// of the checks the state never goes negative; the state is left untouched then.
+func applySpeculativeTx(currentState *state.StateDB, from common.Address, nonce uint64,
+	tx *ethTypes.Transaction, checkType CheckTxType) abciTypes.ResponseCheckTx {
+
+	return applySponsoredTx(currentState, from, from, nonce, tx, checkType)
+}
+
+// applySponsoredTx is applySpeculativeTx with the gas of the tx paid by payer
+func applySponsoredTx(currentState *state.StateDB, from, payer common.Address, nonce uint64,
+	tx *ethTypes.Transaction, checkType CheckTxType) abciTypes.ResponseCheckTx {
+
+	if checkType == CheckTxRecheck {
+		switch stateNonce := currentState.GetNonce(from); {
+		case tx.Nonce() < stateNonce:
+			// applied already
+			return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+		case tx.Nonce() > stateNonce:
+			// a tx of the sender before it left the mempool
+			return abciTypes.ResponseCheckTx{
+				Code: errors.CodeTypeBadNonce,
+				Log: fmt.Sprintf(
+					"Nonce not strictly increasing. Expected %d Got %d",
+					stateNonce, tx.Nonce())}
+		}
+	}
+	if resp := checkSponsoredBalance(currentState, from, payer, tx); resp.Code != abciTypes.CodeTypeOK {
+		return resp
+	}
+
+	// Update ether balances
+	// amount + gasprice * gaslimit, the gas being paid by the payer
+	currentState.SubBalance(from, tx.Value())
+	currentState.SubBalance(payer, gasCost(tx))
+	// tx.To() returns a pointer to a common address. It returns nil
+	// if it is a contract creation transaction.
+	if to := tx.To(); to != nil {
+		currentState.AddBalance(*to, tx.Value())
+	}
+	currentState.SetNonce(from, nonce+1)
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestRecheckDoesNotDoubleDebit(t *testing.T) {
+	assert := assert.New(t)
+
+	st := newTestState()
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	st.AddBalance(from, big.NewInt(1000000))
+
+	tx := ethTypes.NewTransaction(0, to, big.NewInt(100), 21000, big.NewInt(1), nil)
+	expected := new(big.Int).Sub(big.NewInt(1000000), tx.Cost())
+
+	applySpeculativeTx(st, from, 0, tx, CheckTxNew)
+	assert.Equal(expected, st.GetBalance(from))
+	assert.Equal(big.NewInt(100), st.GetBalance(to))
+	assert.Equal(uint64(1), st.GetNonce(from))
+
+	applySpeculativeTx(st, from, 0, tx, CheckTxRecheck)
+	assert.Equal(expected, st.GetBalance(from))
+	assert.Equal(big.NewInt(100), st.GetBalance(to))
+	assert.Equal(uint64(1), st.GetNonce(from))
+
+	// the recheck following a Commit, which reset the state, applies it again
+	reset := newTestState()
+	reset.AddBalance(from, big.NewInt(1000000))
+	applySpeculativeTx(reset, from, 0, tx, CheckTxRecheck)
+	assert.Equal(expected, reset.GetBalance(from))
+	assert.Equal(uint64(1), reset.GetNonce(from))
+
+	// a resident following a tx which left the mempool fails its recheck
+	gapped := ethTypes.NewTransaction(2, to, big.NewInt(100), 21000, big.NewInt(1), nil)
+	assert.Equal(errors.CodeTypeBadNonce, applySpeculativeTx(reset, from, 1, gapped, CheckTxRecheck).Code)
+	assert.Equal(expected, reset.GetBalance(from))
+	assert.Equal(uint64(1), reset.GetNonce(from))
+}
+
+func TestRecheckInferred(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &BaseApp{checkedTx: make(map[common.Hash]*ethTypes.Transaction)}
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	delivered := ethTypes.NewTransaction(0, to, big.NewInt(1), 21000, big.NewInt(1), nil)
+	resident := ethTypes.NewTransaction(1, to, big.NewInt(1), 21000, big.NewInt(1), nil)
+
+	// the txs admitted before a Commit are rechecked after it, but the delivered ones
+	for _, tx := range []*ethTypes.Transaction{delivered, resident} {
+		assert.Equal(CheckTxNew, app.checkTxType(tx.Hash()))
+		app.checkedTx[tx.Hash()] = tx
+	}
+	delete(app.checkedTx, delivered.Hash())
+	app.keepResidentTxs()
+	assert.Equal(CheckTxNew, app.checkTxType(delivered.Hash()))
+	assert.Equal(CheckTxRecheck, app.checkTxType(resident.Hash()))
+	assert.Len(app.checkedTx, 0)
+
+	// a tx failing its recheck leaves the mempool, a later check of it is new
+	app.keepResidentTxs()
+	assert.Equal(CheckTxNew, app.checkTxType(resident.Hash()))
+}
+
+func TestNilTxRejected(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{logger: tmLog.NewNopLogger()}
+
+	checkResp := app.CheckTx(nil, CheckTxNew)
+	assert.Equal(errors.CodeTypeBaseInvalidInput, checkResp.Code)
+	assert.Equal("nil transaction", checkResp.Log)
+
+	deliverResp := app.DeliverTx(nil)
+	assert.Equal(errors.CodeTypeBaseInvalidInput, deliverResp.Code)
+	assert.Equal("nil transaction", deliverResp.Log)
+}
+
+func TestSpeculativeTxNeverOverdraws(t *testing.T) {
+	assert := assert.New(t)
+
+	st := newTestState()
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	st.AddBalance(from, big.NewInt(1000000))
+
+	// the cost of a max value tx is far beyond any balance, and doesn't wrap around
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	huge := ethTypes.NewTransaction(0, to, maxUint256, math.MaxUint64, maxUint256, nil)
+	assert.True(huge.Cost().Cmp(maxUint256) > 0)
+	assert.Equal(errors.CodeTypeBaseInvalidInput, checkBalance(st, from, huge).Code)
+
+	// applied without the balance check, it's rejected and the state left untouched
+	resp := applySpeculativeTx(st, from, 0, huge, CheckTxNew)
+	assert.Equal(errors.CodeTypeBaseInvalidInput, resp.Code)
+	assert.Equal(big.NewInt(1000000), st.GetBalance(from))
+	assert.Equal(uint64(0), st.GetNonce(from))
+	assert.Equal(0, st.GetBalance(to).Sign())
+
+	// a tx costing the whole balance empties it
+	exact := ethTypes.NewTransaction(0, to, big.NewInt(1000000-21000), 21000, big.NewInt(1), nil)
+	assert.Equal(abciTypes.CodeTypeOK, applySpeculativeTx(st, from, 0, exact, CheckTxNew).Code)
+	assert.Equal(0, st.GetBalance(from).Sign())
+	assert.Equal(uint64(1), st.GetNonce(from))
+
+	// then the next one is rejected
+	next := ethTypes.NewTransaction(1, to, big.NewInt(0), 21000, big.NewInt(1), nil)
+	assert.Equal(errors.CodeTypeBaseInvalidInput, applySpeculativeTx(st, from, 1, next, CheckTxNew).Code)
+	assert.Equal(0, st.GetBalance(from).Sign())
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+)
+
+// CheckTxBatch checks new txs as many calls to CheckTx in the same order would,
+// e.g. to warm up the mempool on startup. The senders are recovered in parallel
+// first, then the txs are checked in order against the CheckTx state, which no
+// Commit can reset in between. The results are those of the sequential calls.
+// #unstable
+func (app *EthermintApplication) CheckTxBatch(txs []*ethTypes.Transaction) []abciTypes.ResponseCheckTx {
+	app.recoverSenders(txs)
+
+	app.checkTxStateMtx.Lock()
+	defer app.checkTxStateMtx.Unlock()
+
+	results := make([]abciTypes.ResponseCheckTx, len(txs))
+	for i, tx := range txs {
+		results[i] = app.checkTx(tx, common.Address{}, CheckTxNew, true)
+	}
+	return results
+}
+
+// recoverSenders recovers the senders of the txs over all the CPUs. The senders
+// are cached on the txs and in the sender cache, an invalid signature is left
+// to the checks.
+func (app *EthermintApplication) recoverSenders(txs []*ethTypes.Transaction) {
+	workers := runtime.NumCPU()
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+	next := make(chan *ethTypes.Transaction)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for tx := range next {
+				app.sender(tx) // nolint: errcheck
+			}
+		}()
+	}
+	for _, tx := range txs {
+		if tx != nil {
+			next <- tx
+		}
+	}
+	close(next)
+	wg.Wait()
+}
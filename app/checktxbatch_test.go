@@ -0,0 +1,124 @@
+package app
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// newBatchTestApp returns an app rate limiting the given senders, the txs of
+// which are rejected before any check needing the backend
+func newBatchTestApp(keys []*ecdsa.PrivateKey) (*EthermintApplication, ethTypes.Signer) {
+	app, signer := newPendingCapTestApp(0)
+	app.logger = tmLog.NewNopLogger()
+	app.maxFailedCheckTx = 1
+	for _, key := range keys {
+		app.failedCheckTx.add(crypto.PubkeyToAddress(key.PublicKey))
+	}
+	return app, signer
+}
+
+// batchTxs signs count txs over the senders
+func batchTxs(tb testing.TB, signer ethTypes.Signer, keys []*ecdsa.PrivateKey, count int) []*ethTypes.Transaction {
+	txs := make([]*ethTypes.Transaction, count)
+	for i := range txs {
+		tx, err := ethTypes.SignTx(pricedTx(uint64(i/len(keys)), 1), signer, keys[i%len(keys)])
+		if err != nil {
+			tb.Fatal(err)
+		}
+		txs[i] = tx
+	}
+	return txs
+}
+
+// decodedTxs copies the txs without their cached sender, as received from tendermint
+func decodedTxs(tb testing.TB, txs []*ethTypes.Transaction) []*ethTypes.Transaction {
+	copies := make([]*ethTypes.Transaction, len(txs))
+	for i, tx := range txs {
+		data, err := rlp.EncodeToBytes(tx)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		copies[i] = new(ethTypes.Transaction)
+		if err := rlp.DecodeBytes(data, copies[i]); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return copies
+}
+
+func TestCheckTxBatchSameResults(t *testing.T) {
+	assert := assert.New(t)
+
+	var keys []*ecdsa.PrivateKey
+	for i := 0; i < 4; i++ {
+		key, _ := crypto.GenerateKey()
+		keys = append(keys, key)
+	}
+	sequential, signer := newBatchTestApp(keys)
+	batched, _ := newBatchTestApp(keys)
+	txs := append(batchTxs(t, signer, keys, 32), nil)
+
+	expected := make([]uint32, len(txs))
+	for i, tx := range decodedTxs(t, txs[:32]) {
+		expected[i] = sequential.CheckTx(tx, CheckTxNew).Code
+	}
+	expected[32] = sequential.CheckTx(nil, CheckTxNew).Code
+
+	results := batched.CheckTxBatch(append(decodedTxs(t, txs[:32]), nil))
+	assert.Len(results, len(txs))
+	for i, res := range results {
+		assert.Equal(expected[i], res.Code, "tx %d", i)
+	}
+	assert.Equal(errors.CodeTypeRateLimited, results[0].Code)
+	assert.Equal(errors.CodeTypeBaseInvalidInput, results[32].Code)
+
+	// a paused app rejects every tx alike
+	assert.Nil(batched.setOption("paused", "true"))
+	for _, res := range batched.CheckTxBatch(txs[:4]) {
+		assert.Equal(batched.CheckTx(txs[0], CheckTxNew).Code, res.Code)
+	}
+	assert.Empty(batched.CheckTxBatch(nil))
+}
+
+func benchmarkCheckTx(b *testing.B, check func(app *EthermintApplication, txs []*ethTypes.Transaction)) {
+	var keys []*ecdsa.PrivateKey
+	for i := 0; i < 8; i++ {
+		key, _ := crypto.GenerateKey()
+		keys = append(keys, key)
+	}
+	app, signer := newBatchTestApp(keys)
+	txs := batchTxs(b, signer, keys, 256)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		fresh := decodedTxs(b, txs)
+		b.StartTimer()
+		check(app, fresh)
+	}
+}
+
+// BenchmarkCheckTxSequential measures the checks of 256 txs one at a time
+func BenchmarkCheckTxSequential(b *testing.B) {
+	benchmarkCheckTx(b, func(app *EthermintApplication, txs []*ethTypes.Transaction) {
+		for _, tx := range txs {
+			app.CheckTx(tx, CheckTxNew)
+		}
+	})
+}
+
+// BenchmarkCheckTxBatch measures the checks of 256 txs in a batch
+func BenchmarkCheckTxBatch(b *testing.B) {
+	benchmarkCheckTx(b, func(app *EthermintApplication, txs []*ethTypes.Transaction) {
+		app.CheckTxBatch(txs)
+	})
+}
@@ -0,0 +1,26 @@
+package app
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+)
+
+// commitCheckTxState runs commit, which moves the head and resets the CheckTx state
+// to it, with checkTxStateMtx held. CheckTx waits for the swap rather than
+// validating a tx against the state of the block being replaced.
+func (app *EthermintApplication) commitCheckTxState(commit func() (common.Hash, error)) (common.Hash, error) {
+	app.checkTxStateMtx.Lock()
+	defer app.checkTxStateMtx.Unlock()
+	return commit()
+}
+
+// resetCheckTxState makes the state of the last committed block the CheckTx state,
+// dropping the mempool bookkeeping made obsolete by it. checkTxStateMtx is held.
+func (app *EthermintApplication) resetCheckTxState(committed *state.StateDB) {
+	app.checkTxState = committed
+	app.resetNonceCheckedTxs(committed)
+	app.resetPending(committed)
+	app.prunePendingBySender(committed)
+	app.pruneEvicted(committed)
+	app.resetFailedCounts()
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitCheckTxStateNoStaleRead(t *testing.T) {
+	assert := assert.New(t)
+
+	// the balance of marker in the CheckTx state is the height it was reset to
+	marker := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	var head uint64
+	app := &EthermintApplication{checkTxState: newTestState()}
+
+	const commits = 200
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				h := atomic.LoadUint64(&head)
+				balance, err := app.pendingBalance([]interface{}{marker.Hex()})
+				if !assert.Nil(err) {
+					return
+				}
+				if (*big.Int)(balance).Uint64() < h {
+					t.Errorf("stale CheckTx state: balance %d at height %d", (*big.Int)(balance).Uint64(), h)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < commits; i++ {
+		_, err := app.commitCheckTxState(func() (common.Hash, error) {
+			height := atomic.AddUint64(&head, 1)
+			st := newTestState()
+			st.SetBalance(marker, new(big.Int).SetUint64(height))
+			app.checkTxState = st
+			return common.BigToHash(new(big.Int).SetUint64(height)), nil
+		})
+		assert.Nil(err)
+	}
+	close(done)
+	wg.Wait()
+
+	balance, err := app.pendingBalance([]interface{}{marker.Hex()})
+	assert.Nil(err)
+	assert.Equal((*hexutil.Big)(big.NewInt(commits)).String(), balance.String())
+}
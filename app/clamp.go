@@ -0,0 +1,58 @@
+package app
+
+import (
+	"math"
+	"math/big"
+)
+
+// The helpers below derive the next block parameters from values coming from
+// block data without overflowing: the results saturate instead of wrapping.
+
+// addSat returns a+b, saturated at the maximum uint64
+func addSat(a, b uint64) uint64 {
+	if a > math.MaxUint64-b {
+		return math.MaxUint64
+	}
+	return a + b
+}
+
+// subSat returns a-b, floored at 0
+func subSat(a, b uint64) uint64 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
+
+// mulSat returns a*b, saturated at the maximum uint64
+func mulSat(a, b uint64) uint64 {
+	if a != 0 && b > math.MaxUint64/a {
+		return math.MaxUint64
+	}
+	return a * b
+}
+
+// clampUint64 bounds v to [lo, hi]; a hi of 0 leaves v unbounded above. When the
+// bounds are crossed hi wins.
+func clampUint64(v, lo, hi uint64) uint64 {
+	if v < lo {
+		v = lo
+	}
+	if hi != 0 && v > hi {
+		v = hi
+	}
+	return v
+}
+
+// clampBig returns a copy of v bounded to [lo, hi]; a nil bound is ignored.
+// When the bounds are crossed hi wins.
+func clampBig(v, lo, hi *big.Int) *big.Int {
+	r := new(big.Int).Set(v)
+	if lo != nil && r.Cmp(lo) < 0 {
+		r.Set(lo)
+	}
+	if hi != nil && r.Cmp(hi) > 0 {
+		r.Set(hi)
+	}
+	return r
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestSaturatingArithmetic(t *testing.T) {
+	assert := assert.New(t)
+
+	max := uint64(math.MaxUint64)
+	cases := []struct {
+		a, b          uint64
+		add, sub, mul uint64
+	}{
+		{0, 0, 0, 0, 0},
+		{1, 2, 3, 0, 2},
+		{5, 3, 8, 2, 15},
+		{max, 1, max, max - 1, max},
+		{max, max, max, 0, max},
+		{max / 2, 2, max/2 + 2, max/2 - 2, max - 1},
+		{max/2 + 1, 2, max/2 + 3, max/2 - 1, max},
+		{0, max, max, 0, 0},
+	}
+	for _, c := range cases {
+		assert.Equal(c.add, addSat(c.a, c.b), "%d + %d", c.a, c.b)
+		assert.Equal(c.sub, subSat(c.a, c.b), "%d - %d", c.a, c.b)
+		assert.Equal(c.mul, mulSat(c.a, c.b), "%d * %d", c.a, c.b)
+	}
+}
+
+func TestClamp(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(uint64(5), clampUint64(1, 5, 10))
+	assert.Equal(uint64(10), clampUint64(20, 5, 10))
+	assert.Equal(uint64(7), clampUint64(7, 5, 10))
+	assert.Equal(uint64(math.MaxUint64), clampUint64(math.MaxUint64, 5, 0))
+	// crossed bounds
+	assert.Equal(uint64(3), clampUint64(4, 5, 3))
+
+	lo, hi := big.NewInt(0), big.NewInt(100)
+	assert.Equal("0", clampBig(big.NewInt(-5), lo, hi).String())
+	assert.Equal("100", clampBig(big.NewInt(500), lo, hi).String())
+	assert.Equal("50", clampBig(big.NewInt(50), lo, hi).String())
+	assert.Equal("-5", clampBig(big.NewInt(-5), nil, nil).String())
+
+	// the input isn't touched
+	v := big.NewInt(500)
+	clampBig(v, lo, hi)
+	assert.Equal(big.NewInt(500), v)
+}
+
+func TestNextGasLimitAdversarial(t *testing.T) {
+	assert := assert.New(t)
+
+	max := uint64(math.MaxUint64)
+	cases := []struct {
+		limit, gasUsed uint64
+		bounds         gasLimitBounds
+	}{
+		{0, 0, gasLimitBounds{}},
+		{0, max, gasLimitBounds{}},
+		{max, max, gasLimitBounds{}},
+		{max, 0, gasLimitBounds{target: max}},
+		{max - 1, max, gasLimitBounds{target: 8000000}},
+		{8000000, max, gasLimitBounds{min: 5000000, max: 8100000, target: 8000000}},
+		{1, 1, gasLimitBounds{target: max}},
+		// crossed bounds
+		{8000000, 8000000, gasLimitBounds{min: 9000000, max: 7000000, target: 8000000}},
+	}
+	for _, c := range cases {
+		next := nextGasLimit(c.limit, c.gasUsed, c.bounds)
+		assert.True(next >= params.MinGasLimit || (c.bounds.max != 0 && next == c.bounds.max),
+			"limit %d, gas used %d: %d", c.limit, c.gasUsed, next)
+		if c.bounds.max != 0 {
+			assert.True(next <= c.bounds.max, "limit %d, gas used %d: %d", c.limit, c.gasUsed, next)
+		}
+	}
+
+	// gas used above the limit counts as a full block
+	bounds := gasLimitBounds{target: 1}
+	assert.Equal(nextGasLimit(8000000, 8000000, bounds), nextGasLimit(8000000, max, bounds))
+}
+
+func TestNextGasLimitFuzz(t *testing.T) {
+	assert := assert.New(t)
+
+	rnd := rand.New(rand.NewSource(1))
+	value := func() uint64 {
+		switch rnd.Intn(4) {
+		case 0:
+			return 0
+		case 1:
+			return math.MaxUint64 - uint64(rnd.Intn(3))
+		default:
+			return rnd.Uint64() >> uint(rnd.Intn(64))
+		}
+	}
+	for i := 0; i < 10000; i++ {
+		limit, gasUsed := value(), value()
+		bounds := gasLimitBounds{min: value(), max: value(), target: value()}
+		next := nextGasLimit(limit, gasUsed, bounds)
+
+		if bounds.max != 0 {
+			assert.True(next <= bounds.max)
+		}
+		if bounds.max == 0 || bounds.max >= params.MinGasLimit {
+			assert.True(next >= params.MinGasLimit)
+		}
+		if bounds.max == 0 || bounds.max >= bounds.min {
+			assert.True(next >= bounds.min)
+		}
+	}
+}
+
+func TestRelaxedGasPriceAdversarial(t *testing.T) {
+	assert := assert.New(t)
+
+	minGasPrice := big.NewInt(2000000000)
+	for _, c := range []struct {
+		pending, threshold int
+		percent            uint64
+	}{
+		{-1, 100, 50},
+		{math.MaxInt32, math.MaxInt64, 50},
+		{math.MaxInt64 - 1, math.MaxInt64, 99},
+		{0, 1, 1},
+		{0, 100, math.MaxUint64},
+	} {
+		price := relaxedGasPrice(minGasPrice, c.pending, c.threshold, c.percent)
+		assert.True(price.Sign() >= 0)
+		assert.True(price.Cmp(minGasPrice) <= 0)
+	}
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"time"
+)
+
+// Clock is the time source used by the time dependent checks of the application
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock reads the wall clock
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SetClock replaces the time source, e.g. with a deterministic one in tests
+// and simulations
+// #unstable
+func (app *EthermintApplication) SetClock(clock Clock) {
+	app.clock = clock
+}
+
+// now returns the current time of the application clock
+func (app *EthermintApplication) now() time.Time {
+	if app.clock == nil {
+		return time.Now()
+	}
+	return app.clock.Now()
+}
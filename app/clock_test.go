@@ -0,0 +1,48 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	return c.now
+}
+
+func (c *manualClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestInjectedClock(t *testing.T) {
+	assert := assert.New(t)
+
+	clock := &manualClock{now: time.Unix(1500000000, 0)}
+	app := newLowPriceTestApp()
+	app.SetClock(clock)
+	app.lowPriceTxTTL = time.Minute
+
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	tx := ethTypes.NewTransaction(0, to, big.NewInt(1), 21000, big.NewInt(1), nil)
+
+	app.checkLowPrice(from, tx, app.now())
+	assert.Equal(clock.now, app.lowPriceTransactions[FromTo{from: from, to: to}].added)
+
+	clock.Advance(time.Minute)
+	app.pruneLowPriceTransactions(app.now())
+	assert.Len(app.lowPriceTransactions, 1)
+
+	clock.Advance(time.Second)
+	app.pruneLowPriceTransactions(app.now())
+	assert.Len(app.lowPriceTransactions, 0)
+}
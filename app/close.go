@@ -0,0 +1,52 @@
+package app
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/CyberMiles/travis/utils"
+)
+
+// Close flushes the backend and drops the CheckTx caches. It waits for an
+// in-flight Commit to complete; Commit is refused afterwards.
+// No mempool bookkeeping is persisted, tendermint replays its mempool on restart.
+// #unstable
+func (app *EthermintApplication) Close() error {
+	app.commitMtx.Lock()
+	defer app.commitMtx.Unlock()
+
+	if app.closed {
+		return nil
+	}
+	app.closed = true
+	if app.reorgSub != nil {
+		app.reorgSub.Unsubscribe()
+	}
+
+	var err error
+	if app.flush != nil {
+		err = app.flush()
+	}
+	if app.wal != nil {
+		if werr := app.wal.close(); err == nil {
+			err = werr
+		}
+		app.wal = nil
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.lowPriceTransactions = make(map[FromTo]*lowPriceTx)
+	app.checkFailedCount = make(map[common.Address]uint64)
+	app.failedCheckTx.reset()
+	app.evicted = make(map[common.Hash]*pendingTx)
+	app.futureTxs = make(map[common.Address][]*ethTypes.Transaction)
+	app.pendingBySender = make(map[common.Address]map[common.Hash]uint64)
+	if app.pool != nil {
+		app.pool = newTxPool(app.pool.base)
+	}
+	utils.NonceCheckedTx.Reset()
+
+	return err
+}
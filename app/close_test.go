@@ -0,0 +1,47 @@
+package app
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestCloseDuringActivity(t *testing.T) {
+	assert := assert.New(t)
+
+	app := newPoolTestApp(0)
+	flushed := 0
+	app.flush = func() error {
+		flushed++
+		return nil
+	}
+
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for nonce := uint64(0); nonce < 50; nonce++ {
+				app.checkMempoolCapacity(pricedTx(nonce, int64(i+1)), CheckTxNew)
+				app.recordPending(from, pricedTx(nonce, int64(i+1)))
+				app.AuditCheckTxState()
+			}
+		}(i)
+	}
+
+	assert.NotPanics(func() {
+		assert.Nil(app.Close())
+	})
+	wg.Wait()
+
+	assert.Equal(1, flushed)
+	assert.Empty(app.lowPriceTransactions)
+
+	// closing again is a no-op
+	assert.Nil(app.Close())
+	assert.Equal(1, flushed)
+}
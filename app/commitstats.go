@@ -0,0 +1,119 @@
+package app
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// commitHistorySize is the number of recently committed blocks kept
+const commitHistorySize = 64
+
+// upper bounds of the commit latency histogram buckets, slower commits
+// land in an extra overflow bucket
+var commitLatencyBuckets = []time.Duration{
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// committedBlock is a block committed by the application
+type committedBlock struct {
+	Height uint64      `json:"height"`
+	Hash   common.Hash `json:"hash"`
+}
+
+// latencyBucket is a bucket of the commit latency histogram, an upper bound
+// of 0 standing for the overflow bucket
+type latencyBucket struct {
+	UpperBoundMs int64  `json:"upper_bound_ms"`
+	Count        uint64 `json:"count"`
+}
+
+// commitStatsView is the json form of the commit statistics
+type commitStatsView struct {
+	Commits       uint64           `json:"commits"`
+	LastLatencyMs int64            `json:"last_latency_ms"`
+	AvgLatencyMs  int64            `json:"avg_latency_ms"`
+	Histogram     []latencyBucket  `json:"histogram"`
+	History       []committedBlock `json:"history"`
+}
+
+// commitStats records the latency of Commit and the recently committed blocks
+type commitStats struct {
+	count   uint64
+	total   time.Duration
+	last    time.Duration
+	buckets []uint64
+
+	// ring buffer of the committed blocks, next is the slot written next
+	history [commitHistorySize]committedBlock
+	next    int
+	size    int
+}
+
+func newCommitStats() *commitStats {
+	return &commitStats{buckets: make([]uint64, len(commitLatencyBuckets)+1)}
+}
+
+// record accounts a committed block
+func (s *commitStats) record(height uint64, hash common.Hash, latency time.Duration) {
+	s.count++
+	s.total += latency
+	s.last = latency
+	i := 0
+	for i < len(commitLatencyBuckets) && latency > commitLatencyBuckets[i] {
+		i++
+	}
+	s.buckets[i]++
+
+	s.history[s.next] = committedBlock{Height: height, Hash: hash}
+	s.next = (s.next + 1) % commitHistorySize
+	if s.size < commitHistorySize {
+		s.size++
+	}
+}
+
+// view returns a copy of the statistics, the history oldest first
+func (s *commitStats) view() commitStatsView {
+	v := commitStatsView{
+		Commits:       s.count,
+		LastLatencyMs: int64(s.last / time.Millisecond),
+		Histogram:     make([]latencyBucket, len(s.buckets)),
+		History:       make([]committedBlock, 0, s.size),
+	}
+	if s.count > 0 {
+		v.AvgLatencyMs = int64(s.total / time.Duration(s.count) / time.Millisecond)
+	}
+	for i, c := range s.buckets {
+		v.Histogram[i].Count = c
+		if i < len(commitLatencyBuckets) {
+			v.Histogram[i].UpperBoundMs = int64(commitLatencyBuckets[i] / time.Millisecond)
+		}
+	}
+	for i := 0; i < s.size; i++ {
+		idx := (s.next - s.size + i + commitHistorySize) % commitHistorySize
+		v.History = append(v.History, s.history[idx])
+	}
+	return v
+}
+
+// recordCommit accounts a block committed by Commit
+func (app *EthermintApplication) recordCommit(height uint64, hash common.Hash, latency time.Duration) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.commitStats.record(height, hash, latency)
+}
+
+// commitStatsView returns the statistics served by the travis_commitStats query
+func (app *EthermintApplication) commitStatsView() commitStatsView {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	return app.commitStats.view()
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+)
+
+func TestCommitStats(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{logger: tmLog.NewNopLogger(), commitStats: newCommitStats()}
+	for height := uint64(1); height <= commitHistorySize+2; height++ {
+		app.recordCommit(height, common.BytesToHash([]byte{byte(height)}), 30*time.Millisecond)
+	}
+	app.recordCommit(commitHistorySize+3, common.HexToHash("0xabc"), 2*time.Second)
+
+	data, _ := json.Marshal(jsonRequest{Method: "travis_commitStats"})
+	res := app.Query(abciTypes.RequestQuery{Data: data})
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+
+	var stats commitStatsView
+	assert.Nil(json.Unmarshal(res.Value, &stats))
+	assert.Equal(uint64(commitHistorySize+3), stats.Commits)
+	assert.Equal(int64(2000), stats.LastLatencyMs)
+
+	// the ring buffer keeps the latest blocks, oldest first
+	assert.Len(stats.History, commitHistorySize)
+	assert.Equal(uint64(4), stats.History[0].Height)
+	assert.Equal(uint64(commitHistorySize+3), stats.History[commitHistorySize-1].Height)
+	assert.Equal(common.HexToHash("0xabc"), stats.History[commitHistorySize-1].Hash)
+
+	// 30ms commits land in the 50ms bucket, the 2s one in the 5s bucket
+	assert.Equal(latencyBucket{UpperBoundMs: 50, Count: commitHistorySize + 2}, stats.Histogram[1])
+	assert.Equal(latencyBucket{UpperBoundMs: 5000, Count: 1}, stats.Histogram[6])
+	assert.Equal(uint64(0), stats.Histogram[7].Count)
+}
@@ -0,0 +1,163 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// walOutcome is the resolution of the commit found in the WAL on startup
+type walOutcome int
+
+const (
+	// walClean is a WAL without a commit in flight
+	walClean walOutcome = iota
+	// walCommitted is a commit in flight which reached the backend
+	walCommitted
+	// walRolledBack is a commit in flight which didn't reach the backend, the
+	// block is replayed by tendermint
+	walRolledBack
+)
+
+// walRecord is a line of the WAL: "begin H R" is written before the block at
+// height H is committed on top of the state root R, "end H R" once it's committed
+// with the state root R
+type walRecord struct {
+	op     string
+	height uint64
+	root   common.Hash
+}
+
+func (r walRecord) String() string {
+	return fmt.Sprintf("%s %d %s\n", r.op, r.height, r.root.Hex())
+}
+
+// commitWAL is the write-ahead log of the commits, holding the last commit only.
+// A begin record without its end record tells a commit was interrupted.
+type commitWAL struct {
+	f *os.File
+}
+
+// openCommitWAL opens the WAL at path, creating it if needed, and returns its
+// last record; nil for an empty WAL
+func openCommitWAL(path string) (*commitWAL, *walRecord, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, nil, err
+	}
+	var last *walRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var rec walRecord
+		var root string
+		if _, err := fmt.Sscanf(line, "%s %d %s", &rec.op, &rec.height, &root); err != nil ||
+			(rec.op != "begin" && rec.op != "end") {
+			f.Close() // nolint: errcheck
+			return nil, nil, fmt.Errorf("corrupted commit WAL record: %q", line)
+		}
+		rec.root = common.HexToHash(root)
+		last = &rec
+	}
+	if err := scanner.Err(); err != nil {
+		f.Close() // nolint: errcheck
+		return nil, nil, err
+	}
+	return &commitWAL{f: f}, last, nil
+}
+
+// write appends a record and syncs it to disk. A begin record replaces the
+// previous commit.
+func (w *commitWAL) write(rec walRecord) error {
+	if rec.op == "begin" {
+		if err := w.f.Truncate(0); err != nil {
+			return err
+		}
+	}
+	if _, err := w.f.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+	if _, err := w.f.WriteString(rec.String()); err != nil {
+		return err
+	}
+	return w.f.Sync()
+}
+
+func (w *commitWAL) close() error {
+	return w.f.Close()
+}
+
+// resolveCommitWAL tells what became of the commit of the last record, given the
+// height and state root of the head of the chain
+func resolveCommitWAL(last *walRecord, headHeight uint64, headRoot common.Hash) (walOutcome, error) {
+	if last == nil || last.op == "end" {
+		return walClean, nil
+	}
+	switch {
+	case headHeight == last.height:
+		return walCommitted, nil
+	case headHeight+1 == last.height && headRoot == last.root:
+		return walRolledBack, nil
+	}
+	return walClean, fmt.Errorf("interrupted commit of height %d on root %s doesn't match the head %d with root %s",
+		last.height, last.root.Hex(), headHeight, headRoot.Hex())
+}
+
+// OpenCommitWAL logs the commits to the WAL at path. A commit interrupted before
+// the last shutdown is resolved against the head of the chain first: either it
+// reached the backend, or the block is replayed by tendermint.
+// #unstable
+func (app *EthermintApplication) OpenCommitWAL(path string) error {
+	head := app.backend.Ethereum().BlockChain().CurrentBlock()
+	return app.openCommitWAL(path, head.NumberU64(), head.Root())
+}
+
+func (app *EthermintApplication) openCommitWAL(path string, headHeight uint64, headRoot common.Hash) error {
+	wal, last, err := openCommitWAL(path)
+	if err != nil {
+		return err
+	}
+	outcome, err := resolveCommitWAL(last, headHeight, headRoot)
+	if err != nil {
+		wal.close() // nolint: errcheck
+		return err
+	}
+	switch outcome {
+	case walCommitted:
+		// nolint: errcheck
+		app.logger.Info("Interrupted commit reached the backend", "height", last.height)
+		err = wal.write(walRecord{op: "end", height: headHeight, root: headRoot})
+	case walRolledBack:
+		// nolint: errcheck
+		app.logger.Info("Interrupted commit didn't reach the backend, the block is replayed",
+			"height", last.height)
+		err = wal.f.Truncate(0)
+	}
+	if err != nil {
+		wal.close() // nolint: errcheck
+		return err
+	}
+
+	app.commitMtx.Lock()
+	defer app.commitMtx.Unlock()
+	app.wal = wal
+	return nil
+}
+
+// logCommit writes a WAL record, a no-op without WAL; commitMtx is held
+func (app *EthermintApplication) logCommit(op string, height uint64, root common.Hash) error {
+	if app.wal == nil {
+		return nil
+	}
+	if err := app.wal.write(walRecord{op: op, height: height, root: root}); err != nil {
+		return fmt.Errorf("error writing the commit WAL: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+)
+
+func TestCommitWALRecovery(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "commitwal")
+	assert.Nil(err)
+	defer os.RemoveAll(dir) // nolint: errcheck
+	path := filepath.Join(dir, "commit.wal")
+
+	parentRoot := common.HexToHash("0x01")
+	root := common.HexToHash("0x02")
+	app := &EthermintApplication{logger: tmLog.NewNopLogger()}
+	lastRecord := func() *walRecord {
+		wal, last, err := openCommitWAL(path)
+		assert.Nil(err)
+		wal.close() // nolint: errcheck
+		return last
+	}
+	// crash between the begin record and the commit of the backend
+	crash := func() {
+		assert.Nil(app.openCommitWAL(path, 9, parentRoot))
+		assert.Nil(app.logCommit("begin", 10, parentRoot))
+		assert.Nil(app.Close())
+		app = &EthermintApplication{logger: tmLog.NewNopLogger()}
+	}
+
+	// a new WAL is clean
+	assert.Nil(app.openCommitWAL(path, 9, parentRoot))
+	assert.Nil(lastRecord())
+	assert.Nil(app.Close())
+	app = &EthermintApplication{logger: tmLog.NewNopLogger()}
+
+	// the block reached the backend: the commit is completed
+	crash()
+	assert.Equal("begin", lastRecord().op)
+	assert.Nil(app.openCommitWAL(path, 10, root))
+	assert.Equal(&walRecord{op: "end", height: 10, root: root}, lastRecord())
+	assert.Nil(app.Close())
+	app = &EthermintApplication{logger: tmLog.NewNopLogger()}
+
+	// the block didn't reach the backend: it's left to the replay
+	crash()
+	assert.Nil(app.openCommitWAL(path, 9, parentRoot))
+	assert.Nil(lastRecord())
+	assert.Nil(app.Close())
+	app = &EthermintApplication{logger: tmLog.NewNopLogger()}
+
+	// a head matching neither is refused
+	crash()
+	assert.NotNil(app.openCommitWAL(path, 9, root))
+	assert.NotNil(app.openCommitWAL(path, 12, root))
+	assert.Nil(app.wal)
+
+	// a completed commit only keeps its records
+	assert.Nil(app.openCommitWAL(path, 10, root))
+	assert.Nil(app.logCommit("begin", 11, root))
+	assert.Nil(app.logCommit("end", 11, parentRoot))
+	assert.Equal(&walRecord{op: "end", height: 11, root: parentRoot}, lastRecord())
+	data, err := ioutil.ReadFile(path)
+	assert.Nil(err)
+	assert.Equal(walRecord{op: "begin", height: 11, root: root}.String()+
+		walRecord{op: "end", height: 11, root: parentRoot}.String(), string(data))
+	assert.Nil(app.Close())
+
+	// a corrupted WAL is refused
+	assert.Nil(ioutil.WriteFile(path, []byte("begin ten\n"), 0600))
+	assert.NotNil((&EthermintApplication{logger: tmLog.NewNopLogger()}).openCommitWAL(path, 9, parentRoot))
+}
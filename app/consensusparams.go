@@ -0,0 +1,74 @@
+package app
+
+import (
+	goerr "errors"
+	"fmt"
+
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmTypes "github.com/tendermint/tendermint/types"
+)
+
+// SetConsensusParams schedules an update of the consensus params, returned by the
+// next EndBlock, e.g. once voted by governance. Only the set fields are updated,
+// a zero value leaves a param unchanged.
+// #unstable
+func (app *EthermintApplication) SetConsensusParams(params *abciTypes.ConsensusParams) error {
+	if err := validateConsensusParams(params); err != nil {
+		return err
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.consensusParamUpdates = params
+	return nil
+}
+
+// takeConsensusParamUpdates returns the scheduled update of the consensus params
+// once, nil if there's none
+func (app *EthermintApplication) takeConsensusParamUpdates() *abciTypes.ConsensusParams {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	params := app.consensusParamUpdates
+	app.consensusParamUpdates = nil
+	return params
+}
+
+// validateConsensusParams checks the set params are positive and within the bounds
+// tendermint accepts
+func validateConsensusParams(params *abciTypes.ConsensusParams) error {
+	if params == nil || (params.BlockSize == nil && params.TxSize == nil && params.BlockGossip == nil) {
+		return goerr.New("no consensus param to update")
+	}
+
+	var maxBlockBytes int32
+	if bs := params.BlockSize; bs != nil {
+		if bs.MaxBytes < 0 || bs.MaxBytes > tmTypes.MaxBlockSizeBytes {
+			return fmt.Errorf("block max bytes out of [0, %d]: %d", tmTypes.MaxBlockSizeBytes, bs.MaxBytes)
+		}
+		if bs.MaxTxs < 0 {
+			return fmt.Errorf("negative block max txs: %d", bs.MaxTxs)
+		}
+		if bs.MaxGas < 0 {
+			return fmt.Errorf("negative block max gas: %d", bs.MaxGas)
+		}
+		maxBlockBytes = bs.MaxBytes
+	}
+	if ts := params.TxSize; ts != nil {
+		if ts.MaxBytes < 0 {
+			return fmt.Errorf("negative tx max bytes: %d", ts.MaxBytes)
+		}
+		if maxBlockBytes > 0 && ts.MaxBytes > maxBlockBytes {
+			return fmt.Errorf("tx max bytes %d above the block max bytes %d", ts.MaxBytes, maxBlockBytes)
+		}
+		if ts.MaxGas < 0 {
+			return fmt.Errorf("negative tx max gas: %d", ts.MaxGas)
+		}
+	}
+	if bg := params.BlockGossip; bg != nil {
+		if bg.BlockPartSizeBytes <= 0 || bg.BlockPartSizeBytes > tmTypes.MaxBlockSizeBytes {
+			return fmt.Errorf("block part size out of (0, %d]: %d", tmTypes.MaxBlockSizeBytes, bg.BlockPartSizeBytes)
+		}
+	}
+	return nil
+}
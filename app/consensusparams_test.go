@@ -0,0 +1,44 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestConsensusParamUpdates(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{}
+	assert.Nil(app.takeConsensusParamUpdates())
+
+	params := &abciTypes.ConsensusParams{
+		BlockSize: &abciTypes.BlockSize{MaxBytes: 4194304, MaxGas: 100000000},
+		TxSize:    &abciTypes.TxSize{MaxBytes: 65536},
+	}
+	assert.Nil(app.SetConsensusParams(params))
+
+	// returned by the next EndBlock only
+	assert.Equal(params, app.takeConsensusParamUpdates())
+	assert.Nil(app.takeConsensusParamUpdates())
+}
+
+func TestInvalidConsensusParams(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{}
+	for _, params := range []*abciTypes.ConsensusParams{
+		nil,
+		{},
+		{BlockSize: &abciTypes.BlockSize{MaxBytes: -1}},
+		{BlockSize: &abciTypes.BlockSize{MaxBytes: 200 * 1024 * 1024}},
+		{BlockSize: &abciTypes.BlockSize{MaxGas: -1}},
+		{BlockSize: &abciTypes.BlockSize{MaxBytes: 1024}, TxSize: &abciTypes.TxSize{MaxBytes: 2048}},
+		{BlockGossip: &abciTypes.BlockGossip{BlockPartSizeBytes: 0}},
+	} {
+		assert.NotNil(app.SetConsensusParams(params), "%v", params)
+	}
+	assert.Nil(app.takeConsensusParamUpdates())
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// decodedTx is the result of the travis_decodeTx query. A sender which can't be
+// recovered is left out, with the reason in SenderError.
+type decodedTx struct {
+	Hash        common.Hash     `json:"hash"`
+	From        *common.Address `json:"from"`
+	SenderError string          `json:"senderError,omitempty"`
+	To          *common.Address `json:"to"`
+	Nonce       hexutil.Uint64  `json:"nonce"`
+	Value       *hexutil.Big    `json:"value"`
+	Gas         hexutil.Uint64  `json:"gas"`
+	GasPrice    *hexutil.Big    `json:"gasPrice"`
+	Data        hexutil.Bytes   `json:"data"`
+}
+
+// decodeRawTx answers travis_decodeTx: the fields of a raw tx given in hex, with
+// the sender recovered by the signer CheckTx verifies the tx with
+func (app *EthermintApplication) decodeRawTx(params []interface{}) (*decodedTx, error) {
+	if len(params) != 1 {
+		return nil, fmt.Errorf("expected 1 param, got %d", len(params))
+	}
+	raw, ok := params[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid raw transaction: %v", params[0])
+	}
+	if !strings.HasPrefix(raw, "0x") && !strings.HasPrefix(raw, "0X") {
+		raw = "0x" + raw
+	}
+	txBytes, err := hexutil.Decode(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid raw transaction hex: %v", err)
+	}
+	tx, err := decodeTx(txBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid raw transaction: %v", err)
+	}
+
+	decoded := &decodedTx{
+		Hash:     tx.Hash(),
+		To:       tx.To(),
+		Nonce:    hexutil.Uint64(tx.Nonce()),
+		Value:    (*hexutil.Big)(tx.Value()),
+		Gas:      hexutil.Uint64(tx.Gas()),
+		GasPrice: (*hexutil.Big)(tx.GasPrice()),
+		Data:     tx.Data(),
+	}
+	// the sender cache is left alone, the tx may never be submitted
+	if from, err := ethTypes.Sender(app.signer(tx), tx); err != nil {
+		decoded.SenderError = err.Error()
+	} else {
+		decoded.From = &from
+	}
+	return decoded, nil
+}
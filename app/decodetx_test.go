@@ -0,0 +1,66 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestDecodeRawTx(t *testing.T) {
+	assert := assert.New(t)
+
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	signer := ethTypes.NewEIP155Signer(big.NewInt(777))
+	tx, err := ethTypes.SignTx(ethTypes.NewTransaction(3, common.HexToAddress("0x2000000000000000000000000000000000000002"),
+		big.NewInt(1000), 21000, big.NewInt(2e9), []byte{0xca, 0xfe}), signer, key)
+	assert.Nil(err)
+	raw, err := rlp.EncodeToBytes(tx)
+	assert.Nil(err)
+
+	app := &EthermintApplication{}
+	app.SetSignerResolver(func(*ethTypes.Transaction) ethTypes.Signer { return signer })
+
+	decoded, err := app.decodeRawTx([]interface{}{hexutil.Encode(raw)})
+	assert.Nil(err)
+	assert.Equal(tx.Hash(), decoded.Hash)
+	assert.Equal(from, *decoded.From)
+	assert.Empty(decoded.SenderError)
+	assert.Equal(common.HexToAddress("0x2000000000000000000000000000000000000002"), *decoded.To)
+	assert.Equal(hexutil.Uint64(3), decoded.Nonce)
+	assert.Equal("1000", decoded.Value.ToInt().String())
+	assert.Equal(hexutil.Uint64(21000), decoded.Gas)
+	assert.Equal("2000000000", decoded.GasPrice.ToInt().String())
+	assert.Equal(hexutil.Bytes{0xca, 0xfe}, decoded.Data)
+
+	// the 0x prefix is optional
+	decoded, err = app.decodeRawTx([]interface{}{common.Bytes2Hex(raw)})
+	assert.Nil(err)
+	assert.Equal(from, *decoded.From)
+
+	// a tx signed for another chain is decoded without its sender
+	app.SetSignerResolver(func(*ethTypes.Transaction) ethTypes.Signer {
+		return ethTypes.NewEIP155Signer(big.NewInt(1))
+	})
+	decoded, err = app.decodeRawTx([]interface{}{hexutil.Encode(raw)})
+	assert.Nil(err)
+	assert.Nil(decoded.From)
+	assert.NotEmpty(decoded.SenderError)
+
+	for _, params := range [][]interface{}{
+		nil,
+		{1},
+		{"0xzz"},
+		{"0x" + common.Bytes2Hex(raw[:len(raw)-1])},
+	} {
+		_, err := app.decodeRawTx(params)
+		assert.NotNil(err, "%v", params)
+	}
+}
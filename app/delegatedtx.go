@@ -0,0 +1,79 @@
+package app
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// delegatedFeeTxType is the envelope type of the delegated fee txs
+const delegatedFeeTxType = 0x16
+
+// delegatedFeeTx carries a legacy tx signed by its sender, whose gas is paid by
+// a payer countersigning it, e.g. a relayer. The value is still paid by the sender.
+type delegatedFeeTx struct {
+	// rlp of the signed legacy tx
+	Inner []byte
+	Payer common.Address
+	// payer signature of payerSigHash
+	V, R, S *big.Int
+}
+
+// decodeDelegatedFeeTx decodes a delegated fee envelope and its inner tx
+func decodeDelegatedFeeTx(b []byte) (*delegatedFeeTx, *ethTypes.Transaction, error) {
+	dtx := new(delegatedFeeTx)
+	if err := rlp.DecodeBytes(b[1:], dtx); err != nil {
+		return nil, nil, err
+	}
+	inner, err := decodeTx(dtx.Inner)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dtx, inner, nil
+}
+
+// payerSigHash returns the hash signed by the payer, binding the inner tx to the payer
+func payerSigHash(inner *ethTypes.Transaction, payer common.Address) (common.Hash, error) {
+	payload, err := rlp.EncodeToBytes([]interface{}{inner.Hash(), payer})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash([]byte{delegatedFeeTxType}, payload), nil
+}
+
+// verifyPayer checks the payer signature of the inner tx
+func (dtx *delegatedFeeTx) verifyPayer(inner *ethTypes.Transaction) error {
+	sighash, err := payerSigHash(inner, dtx.Payer)
+	if err != nil {
+		return err
+	}
+	payer, err := recoverSigner(sighash, dtx.V, dtx.R, dtx.S)
+	if err != nil {
+		return err
+	}
+	if payer != dtx.Payer {
+		return fmt.Errorf("payer signature from %s, expected %s", payer.Hex(), dtx.Payer.Hex())
+	}
+	return nil
+}
+
+// openDelegatedFeeTx decodes a delegated fee envelope and checks the signature of
+// its payer. The inner tx is returned with the payer, to be checked and executed
+// as a legacy tx whose gas the payer pays.
+func openDelegatedFeeTx(b []byte) (*ethTypes.Transaction, common.Address, uint32, error) {
+	dtx, inner, err := decodeDelegatedFeeTx(b)
+	if err != nil {
+		return nil, common.Address{}, errors.CodeTypeEncodingErr, err
+	}
+	if err := dtx.verifyPayer(inner); err != nil {
+		return nil, common.Address{}, errors.CodeTypeInvalidPayerSig, err
+	}
+	return inner, dtx.Payer, abciTypes.CodeTypeOK, nil
+}
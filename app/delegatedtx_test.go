@@ -0,0 +1,154 @@
+package app
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// delegatedEnvelope wraps a tx signed by its sender into an envelope countersigned by payerKey
+func delegatedEnvelope(t *testing.T, inner *ethTypes.Transaction, payer common.Address, payerKey *ecdsa.PrivateKey) []byte {
+	innerBytes, err := rlp.EncodeToBytes(inner)
+	assert.Nil(t, err)
+	sighash, err := payerSigHash(inner, payer)
+	assert.Nil(t, err)
+	sig, err := crypto.Sign(sighash[:], payerKey)
+	assert.Nil(t, err)
+
+	payload, err := rlp.EncodeToBytes(&delegatedFeeTx{
+		Inner: innerBytes,
+		Payer: payer,
+		R:     new(big.Int).SetBytes(sig[:32]),
+		S:     new(big.Int).SetBytes(sig[32:64]),
+		V:     big.NewInt(int64(sig[64])),
+	})
+	assert.Nil(t, err)
+	return append([]byte{delegatedFeeTxType}, payload...)
+}
+
+func TestDelegatedFeeTx(t *testing.T) {
+	assert := assert.New(t)
+
+	signer := ethTypes.NewEIP155Signer(big.NewInt(777))
+	senderKey, _ := crypto.GenerateKey()
+	payerKey, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(senderKey.PublicKey)
+	payer := crypto.PubkeyToAddress(payerKey.PublicKey)
+
+	inner, err := ethTypes.SignTx(pricedTx(0, 1), signer, senderKey)
+	assert.Nil(err)
+	envelope := delegatedEnvelope(t, inner, payer, payerKey)
+	assert.True(isTypedTxEnvelope(envelope))
+
+	// the envelope opens into the inner tx, checked as a legacy one, and its payer
+	decoded, decodedPayer, code, err := openTypedTx(envelope)
+	assert.Nil(err)
+	assert.Equal(abciTypes.CodeTypeOK, code)
+	assert.Equal(inner.Hash(), decoded.Hash())
+	assert.Equal(payer, decodedPayer)
+	sender, err := ethTypes.Sender(signer, decoded)
+	assert.Nil(err)
+	assert.Equal(from, sender)
+
+	// the sender only holds the value, the payer covers the gas
+	st := newTestState()
+	st.AddBalance(payer, gasCost(inner))
+	st.AddBalance(from, inner.Value())
+	assert.Equal(abciTypes.CodeTypeOK, checkSponsoredBalance(st, from, payer, decoded).Code)
+
+	// and the value is paid by the sender
+	st.SubBalance(from, inner.Value())
+	assert.Equal(errors.CodeTypeBaseInvalidInput, checkSponsoredBalance(st, from, payer, decoded).Code)
+}
+
+func TestDelegatedFeeTxUnderfundedPayer(t *testing.T) {
+	assert := assert.New(t)
+
+	signer := ethTypes.NewEIP155Signer(big.NewInt(777))
+	senderKey, _ := crypto.GenerateKey()
+	payerKey, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(senderKey.PublicKey)
+	payer := crypto.PubkeyToAddress(payerKey.PublicKey)
+
+	inner, _ := ethTypes.SignTx(pricedTx(0, 1), signer, senderKey)
+	decoded, _, _, err := openTypedTx(delegatedEnvelope(t, inner, payer, payerKey))
+	assert.Nil(err)
+
+	// a funded sender doesn't make up for the payer
+	st := newTestState()
+	st.AddBalance(from, inner.Cost())
+	st.AddBalance(payer, new(big.Int).Sub(gasCost(inner), big.NewInt(1)))
+	assert.Equal(errors.CodeTypeBaseInvalidInput, checkSponsoredBalance(st, from, payer, decoded).Code)
+}
+
+func TestDelegatedFeeTxInvalidPayerSignature(t *testing.T) {
+	assert := assert.New(t)
+
+	signer := ethTypes.NewEIP155Signer(big.NewInt(777))
+	senderKey, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	payer := common.HexToAddress("0x3000000000000000000000000000000000000003")
+
+	// signed by another key than the one of the payer
+	inner, _ := ethTypes.SignTx(pricedTx(0, 1), signer, senderKey)
+	_, _, code, err := openTypedTx(delegatedEnvelope(t, inner, payer, otherKey))
+	assert.NotNil(err)
+	assert.Equal(errors.CodeTypeInvalidPayerSig, code)
+}
+
+func TestRevalidateDelegatedFeeTx(t *testing.T) {
+	assert := assert.New(t)
+
+	signer := ethTypes.NewEIP155Signer(big.NewInt(777))
+	senderKey, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(senderKey.PublicKey)
+	payer := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	inner, _ := ethTypes.SignTx(pricedTx(0, 1), signer, senderKey)
+
+	// the checks of a legacy tx, the gas being paid by the delegated payer
+	validate := func(tx *ethTypes.Transaction, delegatedPayer common.Address,
+		st *state.StateDB) (common.Address, common.Address, uint64, abciTypes.ResponseCheckTx) {
+
+		from, _ := ethTypes.Sender(signer, tx)
+		if st.GetNonce(from) != tx.Nonce() {
+			return from, common.Address{}, st.GetNonce(from), abciTypes.ResponseCheckTx{Code: errors.CodeTypeBadNonce}
+		}
+		payer := from
+		if delegatedPayer != (common.Address{}) {
+			payer = delegatedPayer
+		}
+		return from, payer, tx.Nonce(), checkSponsoredBalance(st, from, payer, tx)
+	}
+
+	base := newTestState()
+	base.AddBalance(payer, gasCost(inner))
+	base.AddBalance(from, inner.Value())
+	pool := newTxPool(base)
+	pool.addDelegated(from, payer, inner)
+
+	// the delegated tx is checked and debited with its payer
+	st, kept, evicted := revalidatePool(pool, validate)
+	assert.Empty(evicted)
+	assert.Len(kept.txs, 1)
+	assert.Equal(payer, kept.txs[0].payer)
+	assert.Equal(0, st.GetBalance(payer).Sign())
+	assert.Equal(0, st.GetBalance(from).Sign())
+	assert.Equal(uint64(1), st.GetNonce(from))
+
+	// and evicted once the payer is out of funds
+	base.SubBalance(payer, big.NewInt(1))
+	_, kept, evicted = revalidatePool(pool, validate)
+	assert.Equal([]common.Hash{inner.Hash()}, evicted)
+	assert.Empty(kept.txs)
+}
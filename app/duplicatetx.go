@@ -0,0 +1,30 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// markDelivered records the delivery of a tx in the current block and rejects
+// a tx appearing a second time in it, so that it can't be executed twice
+func (app *EthermintApplication) markDelivered(tx *ethTypes.Transaction) abciTypes.ResponseDeliverTx {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	hash := tx.Hash()
+	if _, ok := app.delivered[hash]; ok {
+		return abciTypes.ResponseDeliverTx{
+			Code: errors.CodeTypeDuplicateTx,
+			Log:  fmt.Sprintf("Transaction %s already delivered in this block", hash.Hex())}
+	}
+	if app.delivered == nil {
+		app.delivered = make(map[common.Hash]struct{})
+	}
+	app.delivered[hash] = struct{}{}
+	return abciTypes.ResponseDeliverTx{Code: abciTypes.CodeTypeOK}
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestDuplicateDeliverTx(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{logger: tmLog.NewNopLogger()}
+	tx, other := pricedTx(0, 1), pricedTx(1, 1)
+
+	// the first delivery of the block goes through
+	assert.Equal(abciTypes.CodeTypeOK, app.markDelivered(tx).Code)
+	assert.Equal(abciTypes.CodeTypeOK, app.markDelivered(other).Code)
+
+	// the second is rejected before reaching the backend, there is none
+	res := app.DeliverTx(tx)
+	assert.Equal(errors.CodeTypeDuplicateTx, res.Code)
+	assert.Contains(res.Log, tx.Hash().Hex())
+
+	// the next block can include it again
+	app.resetBlockCounters()
+	assert.Equal(abciTypes.CodeTypeOK, app.markDelivered(tx).Code)
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// checkDust rejects a transfer below the dust threshold which would create a new
+// account. Contract creations and calls, and transfers to existing accounts are exempt.
+func (app *EthermintApplication) checkDust(currentState *state.StateDB, tx *ethTypes.Transaction) abciTypes.ResponseCheckTx {
+	app.mu.Lock()
+	threshold := app.dustThreshold
+	app.mu.Unlock()
+
+	to := tx.To()
+	if threshold == nil || to == nil || currentState.Exist(*to) {
+		return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+	}
+	if tx.Value().Cmp(threshold) < 0 {
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeDustValue,
+			Log: fmt.Sprintf(
+				"Value %s to new account %s is below the dust threshold %s",
+				tx.Value(), to.Hex(), threshold)}
+	}
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestCheckDust(t *testing.T) {
+	assert := assert.New(t)
+
+	st := newTestState()
+	existing := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	fresh := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	st.AddBalance(existing, big.NewInt(1))
+
+	app := &EthermintApplication{}
+	assert.Nil(app.setOption("dust_threshold", "1000"))
+
+	// a sub-dust transfer creating an account is rejected
+	tx := ethTypes.NewTransaction(0, fresh, big.NewInt(999), 21000, big.NewInt(1), nil)
+	assert.Equal(errors.CodeTypeDustValue, app.checkDust(st, tx).Code)
+
+	// a normal one is accepted
+	tx = ethTypes.NewTransaction(0, fresh, big.NewInt(1000), 21000, big.NewInt(1), nil)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkDust(st, tx).Code)
+
+	// existing accounts and contract creations are exempt
+	tx = ethTypes.NewTransaction(0, existing, big.NewInt(1), 21000, big.NewInt(1), nil)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkDust(st, tx).Code)
+	tx = ethTypes.NewContractCreation(0, big.NewInt(0), 53000, big.NewInt(1), []byte{0x00})
+	assert.Equal(abciTypes.CodeTypeOK, app.checkDust(st, tx).Code)
+
+	// unsetting the threshold disables the check
+	assert.Nil(app.setOption("dust_threshold", ""))
+	tx = ethTypes.NewTransaction(0, fresh, big.NewInt(1), 21000, big.NewInt(1), nil)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkDust(st, tx).Code)
+}
@@ -0,0 +1,138 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// callArgs is the call object of a travis_estimateGas query
+type callArgs struct {
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Gas      hexutil.Uint64  `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Value    *hexutil.Big    `json:"value"`
+	Data     hexutil.Bytes   `json:"data"`
+}
+
+// parseEstimateArgs reads the call to estimate from the query params,
+// either a call object or a raw signed tx
+func (app *EthermintApplication) parseEstimateArgs(params []interface{}) (callArgs, error) {
+	var args callArgs
+	if len(params) != 1 {
+		return args, fmt.Errorf("expected a call object or a raw transaction")
+	}
+
+	if raw, ok := params[0].(string); ok {
+		b, err := hexutil.Decode(raw)
+		if err != nil {
+			return args, err
+		}
+		tx, err := decodeTx(b)
+		if err != nil {
+			return args, err
+		}
+		from, err := ethTypes.Sender(app.signer(tx), tx)
+		if err != nil {
+			return args, err
+		}
+		return callArgs{
+			From:     from,
+			To:       tx.To(),
+			Gas:      hexutil.Uint64(tx.Gas()),
+			GasPrice: (*hexutil.Big)(tx.GasPrice()),
+			Value:    (*hexutil.Big)(tx.Value()),
+			Data:     tx.Data(),
+		}, nil
+	}
+
+	b, err := json.Marshal(params[0])
+	if err != nil {
+		return args, err
+	}
+	err = json.Unmarshal(b, &args)
+	return args, err
+}
+
+// estimateGas answers the travis_estimateGas query against the latest committed state
+func (app *EthermintApplication) estimateGas(params []interface{}) (hexutil.Uint64, error) {
+	args, err := app.parseEstimateArgs(params)
+	if err != nil {
+		return 0, err
+	}
+	blockchain := app.backend.Ethereum().BlockChain()
+	st, err := blockchain.State()
+	if err != nil {
+		return 0, err
+	}
+	gas, err := estimateGas(args, st, blockchain.CurrentBlock().Header(), blockchain,
+		blockchain.Config(), app.backend.GasLimit())
+	return hexutil.Uint64(gas), err
+}
+
+// estimateGas binary searches the lowest gas limit the call succeeds with, capped by
+// the gas of the call or the block gas limit. Every attempt runs on a copy of st.
+func estimateGas(args callArgs, st *state.StateDB, header *ethTypes.Header,
+	chain core.ChainContext, config *params.ChainConfig, gasCap uint64) (uint64, error) {
+
+	lo, hi := params.TxGas-1, gasCap
+	if uint64(args.Gas) >= params.TxGas {
+		hi = uint64(args.Gas)
+	}
+	allowance := hi
+
+	gasPrice := new(big.Int)
+	if args.GasPrice != nil {
+		gasPrice = args.GasPrice.ToInt()
+	}
+	value := new(big.Int)
+	if args.Value != nil {
+		value = args.Value.ToInt()
+	}
+
+	executable := func(gas uint64) (bool, error) {
+		msg := ethTypes.NewMessage(args.From, args.To, 0, value, gas, gasPrice, args.Data, false)
+		context := core.NewEVMContext(msg, header, chain, &header.Coinbase)
+		evm := vm.NewEVM(context, st.Copy(), config, vm.Config{})
+		_, _, failed, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(math.MaxUint64))
+		if err != nil {
+			// consensus errors, e.g. insufficient funds, don't depend on the gas
+			return false, err
+		}
+		return !failed, nil
+	}
+
+	for lo+1 < hi {
+		mid := (hi + lo) / 2
+		ok, err := executable(mid)
+		if err != nil && err != core.ErrIntrinsicGas {
+			return 0, err
+		}
+		if ok {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+
+	if hi == allowance {
+		ok, err := executable(hi)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			return 0, fmt.Errorf("gas required exceeds allowance (%d) or always failing transaction", allowance)
+		}
+	}
+	return hi, nil
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// testChain is a chain context without history
+type testChain struct{}
+
+func (testChain) Engine() consensus.Engine                                   { return nil }
+func (testChain) GetHeader(hash common.Hash, number uint64) *ethTypes.Header { return nil }
+
+func TestEstimateGas(t *testing.T) {
+	assert := assert.New(t)
+
+	st := newTestState()
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	st.AddBalance(from, big.NewInt(1000000))
+
+	// SSTORE(0, 1)
+	store := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	st.SetCode(store, []byte{0x60, 0x01, 0x60, 0x00, 0x55, 0x00})
+	// REVERT(0, 0)
+	revert := common.HexToAddress("0x4000000000000000000000000000000000000004")
+	st.SetCode(revert, []byte{0x60, 0x00, 0x60, 0x00, 0xfd})
+
+	header := &ethTypes.Header{
+		Number:     big.NewInt(1),
+		Time:       big.NewInt(0),
+		Difficulty: big.NewInt(0),
+		GasLimit:   8000000,
+	}
+	estimate := func(args callArgs) (uint64, error) {
+		return estimateGas(args, st, header, testChain{}, params.TestChainConfig, header.GasLimit)
+	}
+
+	gas, err := estimate(callArgs{From: from, To: &to, Value: (*hexutil.Big)(big.NewInt(1))})
+	assert.Nil(err)
+	assert.Equal(params.TxGas, gas)
+
+	// 21000 + 2 * 3 for the pushes + 20000 for the new storage slot
+	gas, err = estimate(callArgs{From: from, To: &store})
+	assert.Nil(err)
+	assert.Equal(uint64(41006), gas)
+
+	_, err = estimate(callArgs{From: from, To: &revert})
+	assert.NotNil(err)
+
+	// the state isn't mutated by the estimation
+	assert.Equal(common.Hash{}, st.GetState(store, common.Hash{}))
+	assert.Equal(big.NewInt(1000000), st.GetBalance(from))
+	assert.Equal(big.NewInt(0), st.GetBalance(to))
+}
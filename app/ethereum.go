@@ -3,20 +3,26 @@ package app
 import (
 	"bytes"
 	"encoding/json"
+	goerr "errors"
 	"fmt"
 	"math/big"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/rpc"
+	lru "github.com/hashicorp/golang-lru"
 	abciTypes "github.com/tendermint/tendermint/abci/types"
 	tmLog "github.com/tendermint/tendermint/libs/log"
 
 	"github.com/CyberMiles/travis/api"
 	"github.com/CyberMiles/travis/errors"
 	"github.com/CyberMiles/travis/utils"
+	"github.com/CyberMiles/travis/vm/ethereum"
 	emtTypes "github.com/CyberMiles/travis/vm/types"
 )
 
@@ -28,25 +34,214 @@ type FromTo struct {
 // EthermintApplication implements an ABCI application
 // #stable - 0.4.0
 type EthermintApplication struct {
+	// 1 in txLogSample txs is logged, accessed atomically so kept first for
+	// the 64-bit alignment
+	txLogSample uint64
+	txLogCount  uint64
+
+	// set in maintenance mode, accessed atomically
+	paused uint32
+
+	// PruneNotify is called at the end of each Commit with the state root of the
+	// committed block, final once committed. The app doesn't prune by itself, a
+	// pruner keeping the last K roots can drop the ones older than that.
+	PruneNotify func(safeRoot common.Hash, height int64)
+
+	// mu guards the state shared between the ABCI connections
+	mu sync.Mutex
+	// commitMtx serializes Commit and Close
+	commitMtx sync.Mutex
+	closed    bool
+	// the CheckTx state is only rebuilt by FlushState, guarded by commitMtx
+	batchedCommit bool
+	// persists the backend state on Close
+	flush func() error
 
 	// backend handles the ethereum state machine
 	// and wrangles other services started by an ethereum node (eg. tx pool)
 	backend *api.Backend // backend ethereum struct
 
-	checkTxState *state.StateDB
+	// checkTxStateMtx guards checkTxState against the queries reading it,
+	// and keeps it in line with the pool it's built from
+	checkTxStateMtx sync.Mutex
+	checkTxState    *state.StateDB
 
 	// an ethereum rpc client we can forward queries to
 	rpcClient *rpc.Client
+	// methods Query forwards to the rpc client, guarded by mu
+	rpcFilter rpcMethodFilter
+	// proxied eth_subscribe topics, set up on the first Subscribe
+	subscriptions *subscriptionManager
 
 	// strategy for validator compensation
 	strategy *emtTypes.Strategy
 
+	// rewardStrategy distributes the block rewards in EndBlock, none are when nil
+	rewardStrategy  emtTypes.RewardStrategy
+	warnedNoRewards bool
+
+	// current validator set
+	validators []abciTypes.Validator
+	// seed of the current block, guarded by mu
+	seed randomSeed
+	// consensus params update returned by the next EndBlock, guarded by mu
+	consensusParamUpdates *abciTypes.ConsensusParams
+
 	logger tmLog.Logger
 
-	lowPriceTransactions map[FromTo]*ethTypes.Transaction
+	// lowPriceTransactions and checkFailedCount are guarded by mu
+	lowPriceTransactions map[FromTo]*lowPriceTx
+
+	// recipients exempted from the minimum gas price, guarded by mu
+	freeTxTo map[common.Address]struct{}
+	// gas price demanded by some recipients over the minimum one, guarded by mu
+	recipientGasPriceFloors map[common.Address]*big.Int
+	// priority of some senders in the proposal order over the gas price, guarded by mu
+	senderPriorities map[common.Address]uint64
+	// validates the recipients of the txs, guarded by mu
+	addressValidator AddressValidator
+	// caps the number of accounts of the state, guarded by mu
+	accounts accountCeiling
+	// zero gas price txs allowed per account, guarded by mu
+	freeTxQuota freeTxQuota
+	// pays the gas of the txs it sponsors, zero when disabled, guarded by mu.
+	// DeliverTx asks it too, so it follows the paymaster chain param.
+	paymaster common.Address
+	// asks the paymaster, guarded by mu
+	sponsor sponsorCall
+	// sponsorship decisions by tx hash, guarded by mu
+	sponsorships *lru.Cache
+	// sponsorship calls of CheckTx since the last Commit, guarded by mu
+	paymasterCalls int
+	// enables the queries altering the node state, like travis_resetNonce, guarded by mu
+	adminQueries bool
+	// max size in bytes of a query response, 0 for no limit, guarded by mu
+	maxQueryResponseSize int
+
+	// how long a low price entry is kept before being pruned; 0 keeps it until the
+	// low price window resets. Guarded by mu.
+	lowPriceTxTTL time.Duration
+	// how often the low price allowance renews, guarded by mu
+	lowPriceWindow lowPriceWindow
 
 	// record count of failed CheckTx of each from account; used to feed in the nonce check
 	checkFailedCount map[common.Address]uint64
+
+	// failed CheckTx of the new txs of each sender since the last Commit, and the
+	// count past which the sender is rejected early; 0 disables it. Guarded by mu.
+	failedCheckTx    failedCheckTxCounts
+	maxFailedCheckTx uint64
+
+	// time source of the time dependent checks
+	clock Clock
+
+	// decide on the admission of the txs passing the consensus checks
+	admissionPolicies []AdmissionPolicy
+
+	// resolves the signer of a tx, the EIP155 signer of the network by default,
+	// guarded by signerMtx
+	signerResolver SignerResolver
+	// recently recovered senders, guarded by signerMtx
+	senders   *senderCache
+	signerMtx sync.RWMutex
+	// traces the CheckTx of the configured tx hashes
+	checkTraces checkTracer
+
+	// txs admitted by CheckTx since the last Commit
+	pool *txPool
+	// maximum number of resident txs, the cheapest is evicted beyond it; 0 disables it,
+	// guarded by mu
+	mempoolCap int
+	// txs evicted from the pool, rejected on their next recheck, until their nonce
+	// is committed
+	evicted map[common.Hash]*pendingTx
+	// nonces of the txs of each sender in the mempool, not committed yet
+	pendingBySender map[common.Address]map[common.Hash]uint64
+	// maximum number of txs of a sender in the mempool; 0 disables it, guarded by mu
+	maxPendingPerSender int
+	// txs held until the nonce gap before them is filled, by sender
+	futureTxs map[common.Address][]*ethTypes.Transaction
+	// resubmits a promoted future tx, broadcasts it to tendermint by default
+	resubmit func(tx *ethTypes.Transaction)
+	// notified when a held tx is replaced by another with the same nonce, guarded by mu
+	onTxReplaced TxReplacedHandler
+
+	// txs delivered in the current block
+	block     blockCounters
+	delivered map[common.Hash]struct{}
+	// hashes of the txs of the current block in delivery order, and the tx order
+	// roots of the recently committed blocks by height, guarded by mu
+	txOrder      []common.Hash
+	txOrderRoots map[uint64][]byte
+	// records the delivered txs, guarded by mu
+	auditSink AuditSink
+	// fast-fails DeliverTx after consecutive backend errors, guarded by mu
+	breaker deliveryBreaker
+	// gas prices of the txs of the last committed blocks, guarded by mu
+	gasOracle gasPriceOracle
+	// tx fees of the current and recently committed blocks, guarded by mu
+	fees feeLedger
+
+	// latency of Commit and recently committed blocks
+	commitStats *commitStats
+	// gauges of the CheckTx bookkeeping, guarded by mu
+	metrics *Metrics
+	// consumers of the committed blocks
+	blockFeed blockFeed
+	// side chain events of the backend, the CheckTx state is rebuilt on a reorg
+	reorgSub event.Subscription
+	// write-ahead log of the commits, nil when disabled, guarded by commitMtx
+	wal *commitWAL
+
+	// fails some operations on purpose for chaos testing
+	faults faultInjector
+
+	// balances allocated by the genesis, known once InitChain ran, guarded by mu
+	genesisSupply *genesisSupply
+	// set once InitChain ran, guarded by mu
+	chainInitialized bool
+
+	// rewards distributed since the node started
+	totalRewards *big.Int
+	// rewards distributed in the last ended block, guarded by mu
+	lastRewards *blockRewards
+
+	// liveness faults of each validator, keyed by hex address
+	liveness map[string]*validatorLiveness
+
+	// while fewer than gasPriceGraceThreshold txs wait in the mempool, the minimum
+	// gas price is relaxed down to gasPriceGracePercent of it; 0 disables it.
+	// Guarded by mu.
+	gasPriceGracePercent   uint64
+	gasPriceGraceThreshold int
+
+	// minimum value of a transfer creating a new account; nil disables it, guarded by mu
+	dustThreshold *big.Int
+
+	// how the txs of an account to itself are checked, guarded by mu
+	selfTxPolicy selfTxPolicy
+
+	// maximum gas limit of a single tx, below the block gas limit; 0 disables it,
+	// guarded by mu
+	maxTxGas uint64
+
+	// reject txs whose gas limit exceeds this multiple of their intrinsic gas; 0 disables it,
+	// guarded by mu
+	maxGasIntrinsicRatio uint64
+
+	// percentage of the intrinsic gas the gas limit of a tx must exceed it by; 0 disables it,
+	// guarded by mu
+	intrinsicGasMargin uint64
+
+	// whether the first block built after a start was seeded with the adjusted gas limit
+	gasLimitSeeded bool
+
+	// senders which failed the balance check since the last Commit, guarded by mu
+	underfunded underfundedSenders
+
+	// activation height of EIP-3607 (reject txs from senders with code); nil disables it,
+	// guarded by mu
+	eip3607Block *big.Int
 }
 
 // NewEthermintApplication creates a fully initialised instance of EthermintApplication
@@ -58,20 +253,42 @@ func NewEthermintApplication(backend *api.Backend,
 	if state == nil {
 		panic("Error getting latest state")
 	}
+	root := backend.Ethereum().BlockChain().CurrentBlock().Root()
+	checkTxState, err := verifyCheckTxState(state.StateDB, root, blockStateOf(backend))
+	if err != nil {
+		return nil, err
+	}
 
 	app := &EthermintApplication{
 		backend:              backend,
+		flush:                backend.Flush,
 		rpcClient:            client,
-		checkTxState:         state.StateDB,
+		checkTxState:         checkTxState,
+		pool:                 newTxPool(checkTxState.Copy()),
+		clock:                systemClock{},
+		senders:              newSenderCache(defaultSenderCacheSize),
+		evicted:              make(map[common.Hash]*pendingTx),
+		futureTxs:            make(map[common.Address][]*ethTypes.Transaction),
+		pendingBySender:      make(map[common.Address]map[common.Hash]uint64),
 		strategy:             strategy,
-		lowPriceTransactions: make(map[FromTo]*ethTypes.Transaction),
+		rewardStrategy:       ethereum.EthashRewardStrategy{},
+		lowPriceTransactions: make(map[FromTo]*lowPriceTx),
 		checkFailedCount:     make(map[common.Address]uint64),
+		liveness:             make(map[string]*validatorLiveness),
+		totalRewards:         new(big.Int),
+		commitStats:          newCommitStats(),
 	}
 
+	app.admissionPolicies = []AdmissionPolicy{app.LowPricePolicy()}
+
 	if err := app.backend.InitEthState(app.Receiver()); err != nil {
 		return nil, err
 	}
 
+	sideEvents := make(chan core.ChainSideEvent, reorgEventBuffer)
+	app.reorgSub = backend.Ethereum().BlockChain().SubscribeChainSideEvent(sideEvents)
+	go app.watchReorgs(sideEvents, app.reorgSub, backend.ResetState)
+
 	return app, nil
 }
 
@@ -81,8 +298,17 @@ func (app *EthermintApplication) SetLogger(log tmLog.Logger) {
 	app.logger = log
 }
 
+// SetRewardStrategy sets the strategy distributing the block rewards in EndBlock
+// #unstable
+func (app *EthermintApplication) SetRewardStrategy(strategy emtTypes.RewardStrategy) {
+	app.rewardStrategy = strategy
+}
+
 var bigZero = big.NewInt(0)
 
+// errNilTx is returned for a nil tx handed to CheckTx or DeliverTx
+var errNilTx = goerr.New("nil transaction")
+
 // maxTransactionSize is 32KB in order to prevent DOS attacks
 const maxTransactionSize = 32768
 
@@ -91,28 +317,8 @@ const maxTransactionSize = 32768
 
 func (app *EthermintApplication) Info(req abciTypes.RequestInfo) abciTypes.ResponseInfo {
 	blockchain := app.backend.Ethereum().BlockChain()
-	currentBlock := blockchain.CurrentBlock()
-	height := currentBlock.Number()
-	hash := currentBlock.Hash()
-
-	app.logger.Debug("Info", "height", height) // nolint: errcheck
-
-	// This check determines whether it is the first time ethermint gets started.
-	// If it is the first time, then we have to respond with an empty hash, since
-	// that is what tendermint expects.
-	if height.Cmp(bigZero) == 0 {
-		return abciTypes.ResponseInfo{
-			Data:             "ABCIEthereum",
-			LastBlockHeight:  height.Int64(),
-			LastBlockAppHash: []byte{},
-		}
-	}
-
-	return abciTypes.ResponseInfo{
-		Data:             "ABCIEthereum",
-		LastBlockHeight:  height.Int64(),
-		LastBlockAppHash: hash[:],
-	}
+	app.logger.Debug("Info", "height", blockchain.CurrentBlock().Number()) // nolint: errcheck
+	return chainInfo(blockchain)
 }
 
 // SetOption sets a configuration option
@@ -120,32 +326,113 @@ func (app *EthermintApplication) Info(req abciTypes.RequestInfo) abciTypes.Respo
 func (app *EthermintApplication) SetOption(req abciTypes.RequestSetOption) abciTypes.ResponseSetOption {
 
 	app.logger.Debug("SetOption", "key", req.GetKey(), "value", req.GetValue()) // nolint: errcheck
+	if err := app.setOption(req.GetKey(), req.GetValue()); err != nil {
+		return abciTypes.ResponseSetOption{Code: errors.CodeTypeBaseInvalidInput,
+			Log: err.Error()}
+	}
 	return abciTypes.ResponseSetOption{}
 }
 
-// InitChain initializes the validator set
+// InitChain initializes the validator set and the balances allocated in the app state.
+// It's a no-op once the chain is initialized.
 // #stable - 0.4.0
 func (app *EthermintApplication) InitChain(req abciTypes.RequestInitChain) abciTypes.ResponseInitChain {
 
 	app.logger.Debug("InitChain") // nolint: errcheck
+	if height, ok := app.claimInitChain(); !ok {
+		// a replay mustn't apply the validators and the allocations again
+		app.logger.Error("Ignoring InitChain, the chain is initialized already", "height", height) // nolint: errcheck
+		return abciTypes.ResponseInitChain{}
+	}
+	allocs, err := parseGenesisAlloc(req.GetAppStateBytes())
+	if err != nil {
+		// the chain can't start from an invalid genesis
+		panic(fmt.Sprintf("Invalid genesis app state: %v", err))
+	}
+	if err := app.verifyGenesisAlloc(req.GetAppStateBytes(), allocs); err != nil {
+		panic(fmt.Sprintf("Invalid genesis alloc: %v", err))
+	}
+	if len(allocs) > 0 {
+		app.initGenesisAlloc(allocs)
+	}
+
 	app.SetValidators(req.GetValidators())
 	return abciTypes.ResponseInitChain{}
 }
 
 // CheckTx checks a transaction is valid but does not mutate the state
 // #stable - 0.4.0
-func (app *EthermintApplication) CheckTx(tx *ethTypes.Transaction) abciTypes.ResponseCheckTx {
-	app.logger.Debug("CheckTx: Received valid transaction", "tx", tx) // nolint: errcheck
+func (app *EthermintApplication) CheckTx(tx *ethTypes.Transaction, checkType CheckTxType) abciTypes.ResponseCheckTx {
+	return app.checkTx(tx, common.Address{}, checkType, false)
+}
+
+// checkTx runs CheckTx on a tx whose gas is paid by payer, e.g. the inner tx of a
+// delegated fee tx, the zero address for the other txs. checkTxStateMtx is taken
+// for the stateful checks unless the caller holds it already.
+func (app *EthermintApplication) checkTx(tx *ethTypes.Transaction, payer common.Address,
+	checkType CheckTxType, held bool) (resp abciTypes.ResponseCheckTx) {
+
+	if tx == nil {
+		return abciTypes.ResponseCheckTx{Code: errors.CodeTypeBaseInvalidInput, Log: errNilTx.Error()}
+	}
+	app.checkTraces.begin(tx.Hash(), checkType)
+	defer func() { app.checkTraces.end(tx.Hash(), resp) }()
+
+	if resp := app.traceStep(tx, "paused", app.checkPaused()); resp.Code != abciTypes.CodeTypeOK {
+		return resp
+	}
+	app.logTx("CheckTx: Received valid transaction", tx, "type", checkType)
+
+	if checkType == CheckTxNew {
+		if resp := app.traceStep(tx, "rate_limit", app.checkRateLimited(tx)); resp.Code != abciTypes.CodeTypeOK {
+			return resp
+		}
+	}
 
-	return app.validateTx(tx)
+	if !held {
+		app.checkTxStateMtx.Lock()
+		defer app.checkTxStateMtx.Unlock()
+	}
+	resp = app.validateTx(tx, payer, checkType)
+	if resp.Code != abciTypes.CodeTypeOK {
+		// a resident tx failing its recheck leaves the mempool
+		app.releasePending(tx)
+		if checkType == CheckTxNew {
+			app.recordFailedCheckTx(tx, resp)
+		}
+	}
+	return resp
 }
 
 // DeliverTx executes a transaction against the latest state
 // #stable - 0.4.0
 func (app *EthermintApplication) DeliverTx(tx *ethTypes.Transaction) abciTypes.ResponseDeliverTx {
-	app.logger.Debug("DeliverTx: Received valid transaction", "tx", tx) // nolint: errcheck
+	return app.deliverTx(tx, common.Address{})
+}
 
-	res := app.backend.DeliverTx(tx)
+// deliverTx executes a tx with its gas paid by payer. For the zero address the
+// paymaster is asked, the sender paying when it declines.
+func (app *EthermintApplication) deliverTx(tx *ethTypes.Transaction, payer common.Address) abciTypes.ResponseDeliverTx {
+	if tx == nil {
+		return abciTypes.ResponseDeliverTx{Code: errors.CodeTypeBaseInvalidInput, Log: errNilTx.Error()}
+	}
+	app.logTx("DeliverTx: Received valid transaction", tx)
+	app.recordTxOrder(tx)
+
+	if res := app.markDelivered(tx); res.Code != abciTypes.CodeTypeOK {
+		app.logger.Error("DeliverTx: Duplicate tx in block", "hash", tx.Hash().Hex()) // nolint: errcheck
+		return res
+	}
+	candidates := app.newAccounts(tx)
+	if payer == (common.Address{}) {
+		payer = app.deliveryGasPayer(tx)
+	}
+	res := app.deliverThroughBreaker(tx, func(tx *ethTypes.Transaction) abciTypes.ResponseDeliverTx {
+		return app.deliverToBackend(tx, payer)
+	})
+	if res.Code == errors.CodeTypeBreakerOpen {
+		return res
+	}
 	if res.IsErr() {
 		// nolint: errcheck
 		app.logger.Error("DeliverTx: Error delivering tx to ethereum backend", "tx", tx,
@@ -153,9 +440,20 @@ func (app *EthermintApplication) DeliverTx(tx *ethTypes.Transaction) abciTypes.R
 		return res
 	}
 	app.CollectTx(tx)
+	app.countNewAccounts(candidates)
+	app.consumeFreeTxQuota(tx, app.now())
+	app.recordGasPriceSample(tx)
+	app.recordBlockFee(tx, uint64(res.GasUsed))
+	index := app.countDeliveredTx(uint64(res.GasUsed))
+	if sink := app.getAuditSink(); sink != nil {
+		app.auditTx(sink, app.workingHeight().Int64(), index, tx, uint64(res.GasUsed))
+	}
+	app.releasePending(tx)
 
 	return abciTypes.ResponseDeliverTx{
-		Code: abciTypes.CodeTypeOK,
+		Code:    abciTypes.CodeTypeOK,
+		Log:     res.Log,
+		GasUsed: res.GasUsed,
 	}
 }
 
@@ -169,8 +467,18 @@ func (app *EthermintApplication) BeginBlock(beginBlock abciTypes.RequestBeginBlo
 
 	app.logger.Debug("BeginBlock") // nolint: errcheck
 
+	if err := app.seedGasLimit(); err != nil {
+		app.logger.Error("Error seeding the gas limit", "err", err) // nolint: errcheck
+	}
+	app.syncPaymaster()
+
 	// update the eth header with the tendermint header
-	app.backend.UpdateHeaderWithTimeInfo(beginBlock.GetHeader())
+	header := beginBlock.GetHeader()
+	parent := app.backend.Ethereum().BlockChain().CurrentBlock()
+	header.Time = app.checkHeaderTime(header.Time, parent.Time().Int64())
+	app.backend.UpdateHeaderWithTimeInfo(header)
+	app.recordRandomSeed(beginBlock.GetHeader())
+	app.recordLiveness(beginBlock)
 	return abciTypes.ResponseBeginBlock{}
 }
 
@@ -178,33 +486,91 @@ func (app *EthermintApplication) BeginBlock(beginBlock abciTypes.RequestBeginBlo
 // #stable - 0.4.0
 func (app *EthermintApplication) EndBlock(endBlock abciTypes.RequestEndBlock) abciTypes.ResponseEndBlock {
 
-	app.logger.Debug("EndBlock", "height", endBlock.GetHeight()) // nolint: errcheck
-	app.backend.AccumulateRewards(app.backend.Ethereum().BlockChain().Config(), app.strategy)
+	block := app.blockCounters()
+	// nolint: errcheck
+	app.logger.Debug("EndBlock", "height", endBlock.GetHeight(),
+		"txs", block.TxCount, "gasUsed", block.GasUsed)
+	if app.rewardStrategy == nil && !app.warnedNoRewards {
+		app.warnedNoRewards = true
+		app.logger.Info("No reward strategy set, the block rewards are skipped") // nolint: errcheck
+	}
+	rewards := app.backend.AccumulateRewards(app.backend.Ethereum().BlockChain().Config(), app.rewardStrategy, app.validators)
+	app.addRewards(rewards)
+	app.recordLastRewards(endBlock.GetHeight(), rewards)
 
 	app.backend.EndBlock()
+	app.adjustGasLimit(block.GasUsed)
 
-	return app.GetUpdatedValidators()
+	res := app.GetUpdatedValidators()
+	res.ValidatorUpdates = app.updateValidators(res.ValidatorUpdates)
+	res.ConsensusParamUpdates = app.takeConsensusParamUpdates()
+	return res
 }
 
 // Commit commits the block and returns a hash of the current state
 // #stable - 0.4.0
 func (app *EthermintApplication) Commit() abciTypes.ResponseCommit {
 	app.logger.Debug("Commit") // nolint: errcheck
-	blockHash, err := app.backend.Commit(app.Receiver())
-	if err != nil {
-		// nolint: errcheck
-		app.logger.Error("Error getting latest ethereum state", "err", err)
+	app.commitMtx.Lock()
+	defer app.commitMtx.Unlock()
+	if app.closed {
+		app.logger.Error("Commit on a closed application") // nolint: errcheck
 		return abciTypes.ResponseCommit{}
 	}
 
-	state, err := app.backend.ResetState()
+	start := app.now()
+	blockHash, err := app.commitCheckTxState(func() (common.Hash, error) {
+		if err := app.faults.inject(faultCommit); err != nil {
+			return common.Hash{}, err
+		}
+		parent := app.backend.Ethereum().BlockChain().CurrentBlock()
+		if err := app.logCommit("begin", parent.NumberU64()+1, parent.Root()); err != nil {
+			return common.Hash{}, err
+		}
+		blockHash, err := app.backend.Commit(app.Receiver())
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("error getting latest ethereum state: %v", err)
+		}
+		// a missing end record is resolved on restart, the block is committed
+		head := app.backend.Ethereum().BlockChain().CurrentBlock()
+		if err := app.logCommit("end", head.NumberU64(), head.Root()); err != nil {
+			app.logger.Error("Error logging the commit", "err", err) // nolint: errcheck
+		}
+		if app.batchedCommit {
+			return blockHash, nil
+		}
+		if err := app.faults.inject(faultResetState); err != nil {
+			return common.Hash{}, fmt.Errorf("error getting latest state: %v", err)
+		}
+		managed, err := app.backend.ResetState()
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("error getting latest state: %v", err)
+		}
+		app.resetCheckTxState(managed.StateDB)
+		return blockHash, nil
+	})
 	if err != nil {
-		app.logger.Error("Error getting latest state", "err", err) // nolint: errcheck
+		app.logger.Error("Error committing the block", "err", err) // nolint: errcheck
 		return abciTypes.ResponseCommit{}
 	}
-	app.checkTxState = state.StateDB
-
-	app.lowPriceTransactions = make(map[FromTo]*ethTypes.Transaction)
+	app.resetBlockCounters()
+	app.commitGasPriceSamples()
+
+	committed := app.backend.Ethereum().BlockChain().CurrentBlock()
+	height := committed.NumberU64()
+	app.recordCommit(height, blockHash, app.now().Sub(start))
+	app.commitTxOrder(height)
+	app.commitBlockFees(height)
+
+	app.rollLowPriceWindow(height, app.now())
+	app.resetUnderfunded()
+	app.resetPaymasterCalls()
+	app.syncPaymaster()
+	app.resetFailedCheckTx()
+	app.resetDeliveryBreaker()
+	app.notifyPrune(committed.Root(), int64(height))
+	app.publishBlockEvent(height, blockHash)
+	app.reportMapSizes()
 
 	return abciTypes.ResponseCommit{
 		Data: blockHash[:],
@@ -215,20 +581,48 @@ func (app *EthermintApplication) Commit() abciTypes.ResponseCommit {
 // #stable - 0.4.0
 func (app *EthermintApplication) Query(query abciTypes.RequestQuery) abciTypes.ResponseQuery {
 	app.logger.Debug("Query") // nolint: errcheck
+	structured := query.Path == queryPathV2
 	var in jsonRequest
 	if err := json.Unmarshal(query.Data, &in); err != nil {
-		return abciTypes.ResponseQuery{Code: errors.CodeTypeInternalErr,
-			Log: err.Error()}
+		return queryFailure(structured, errors.CodeTypeInternalErr, rpcParseError, err)
 	}
-	var result interface{}
-	if err := app.rpcClient.Call(&result, in.Method, in.Params...); err != nil {
-		return abciTypes.ResponseQuery{Code: errors.CodeTypeInternalErr,
-			Log: err.Error()}
+	if in.Method == "" {
+		return queryFailure(structured, errors.CodeTypeBaseInvalidInput, rpcInvalidRequest, goerr.New("missing method"))
+	}
+	if in.Height != nil {
+		head := app.backend.Ethereum().BlockChain().CurrentBlock().NumberU64()
+		params, err := paramsAtHeight(in, head)
+		if err != nil {
+			return queryFailure(structured, errors.CodeTypeBaseInvalidInput, rpcInvalidParams, err)
+		}
+		in.Params = params
+	}
+	result, handled, err := app.localQuery(in)
+	if err == errAdminQueryDisabled {
+		return queryFailure(structured, errors.CodeTypeUnauthorized, rpcMethodNotFound, err)
+	}
+	if err != nil {
+		return queryFailure(structured, errors.CodeTypeInternalErr, rpcInternalError, err)
+	}
+	if !handled {
+		if !app.rpcMethodAllowed(in.Method) {
+			return queryFailure(structured, errors.CodeTypeUnauthorized, rpcMethodNotFound,
+				fmt.Errorf("method %s is not allowed", in.Method))
+		}
+		if err := app.rpcClient.Call(&result, in.Method, in.Params...); err != nil {
+			return queryFailure(structured, errors.CodeTypeInternalErr, rpcErrorCode(err), err)
+		}
 	}
 	bytes, err := json.Marshal(result)
 	if err != nil {
-		return abciTypes.ResponseQuery{Code: errors.CodeTypeInternalErr,
-			Log: err.Error()}
+		return queryFailure(structured, errors.CodeTypeInternalErr, rpcInternalError, err)
+	}
+	app.mu.Lock()
+	maxSize := app.maxQueryResponseSize
+	app.mu.Unlock()
+	if maxSize > 0 && len(bytes) > maxSize {
+		return queryFailure(structured, errors.CodeTypeResponseTooLarge, rpcServerError,
+			fmt.Errorf("response too large: %d bytes, max %d", len(bytes), maxSize))
 	}
 	return abciTypes.ResponseQuery{Code: abciTypes.CodeTypeOK, Value: bytes}
 }
@@ -237,83 +631,163 @@ func (app *EthermintApplication) Query(query abciTypes.RequestQuery) abciTypes.R
 
 // validateTx checks the validity of a tx against the blockchain's current state.
 // it duplicates the logic in ethereum's tx_pool
-func (app *EthermintApplication) validateTx(tx *ethTypes.Transaction) abciTypes.ResponseCheckTx {
+// A recheck re-validates the tx against the current state without the admission
+// policies, applying it again only once Commit reset the state.
+// The gas of a delegated fee tx is paid by its payer, the zero address otherwise.
+// Its sender isn't judged on the balance checks, which the payer may have failed,
+// and its nonce gaps aren't held: the envelope would be lost on resubmission.
+func (app *EthermintApplication) validateTx(tx *ethTypes.Transaction, delegatedPayer common.Address,
+	checkType CheckTxType) abciTypes.ResponseCheckTx {
+
+	delegated := delegatedPayer != (common.Address{})
+	if !delegated {
+		if resp := app.traceStep(tx, "underfunded", app.checkUnderfunded(tx)); resp.Code != abciTypes.CodeTypeOK {
+			return resp
+		}
+	}
+
+	app.pruneLowPriceTransactions(app.now())
 
-	currentState, from, nonce, resp := app.basicCheck(tx)
+	from, payer, nonce, resp := app.validateSponsoredTxState(tx, delegatedPayer, app.checkTxState)
+	if resp.Code == errors.CodeTypeBadNonce && checkType == CheckTxNew && !delegated {
+		return app.queueFutureTx(from, nonce, app.checkTxState.GetBalance(from), tx, resp)
+	}
+	if resp.Code == errors.CodeTypeBaseInvalidInput && from != (common.Address{}) && !delegated {
+		// only a failed balance check passes the sender through
+		app.recordUnderfunded(from)
+	}
 	if resp.Code != abciTypes.CodeTypeOK {
 		return resp
 	}
 
+	if checkType == CheckTxNew {
+		if resp := app.traceStep(tx, "pending_cap", app.checkPendingCap(from, tx)); resp.Code != abciTypes.CodeTypeOK {
+			return resp
+		}
+		if resp := app.traceStep(tx, "price", app.admit(tx, app.checkTxState)); resp.Code != abciTypes.CodeTypeOK {
+			return resp
+		}
+	}
+	if resp := app.traceStep(tx, "mempool_capacity", app.checkMempoolCapacity(tx, checkType)); resp.Code != abciTypes.CodeTypeOK {
+		return resp
+	}
+
+	if resp := applySponsoredTx(app.checkTxState, from, payer, nonce, tx, checkType); resp.Code != abciTypes.CodeTypeOK {
+		return resp
+	}
+	utils.NonceCheckedTx.Add(tx.Hash())
+
+	app.addPending(from, tx)
+	// a resident passing its recheck is tracked again, the pool being reset on Commit
+	app.recordDelegatedPending(from, delegatedPayer, tx)
+	if checkType == CheckTxNew {
+		app.promoteFutureTx(from, nonce+1)
+	}
+
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
+
+// validateTxState runs the checks of validateTx that only depend on the given state,
+// without touching the mempool bookkeeping. The account paying the gas is returned
+// along with the sender.
+func (app *EthermintApplication) validateTxState(tx *ethTypes.Transaction,
+	currentState *state.StateDB) (from, payer common.Address, nonce uint64, resp abciTypes.ResponseCheckTx) {
+
+	return app.validateSponsoredTxState(tx, common.Address{}, currentState)
+}
+
+// validateSponsoredTxState is validateTxState with the gas of the tx paid by the
+// payer of a delegated fee tx. For the zero address the paymaster is asked, the
+// sender paying when it declines.
+func (app *EthermintApplication) validateSponsoredTxState(tx *ethTypes.Transaction, delegatedPayer common.Address,
+	currentState *state.StateDB) (from, payer common.Address, nonce uint64, resp abciTypes.ResponseCheckTx) {
+
+	// the sender and its nonce are passed through on a nonce mismatch
+	// or when the balance doesn't cover the tx cost
+	_, from, nonce, resp = app.basicCheckWithState(tx, currentState)
+	if resp.Code != abciTypes.CodeTypeOK {
+		return from, common.Address{}, nonce, resp
+	}
+
+	if resp := app.traceStep(tx, "max_gas", app.checkMaxTxGas(tx)); resp.Code != abciTypes.CodeTypeOK {
+		return common.Address{}, common.Address{}, 0, resp
+	}
+
+	height := app.workingHeight()
+	if resp := app.traceStep(tx, "sender_code", app.checkSenderCode(currentState, from, height)); resp.Code != abciTypes.CodeTypeOK {
+		return common.Address{}, common.Address{}, 0, resp
+	}
+
 	// Iterate TravisTxAddrs to prevent transfer transaction
 	for _, tAddr := range utils.TravisTxAddrs {
 		if bytes.Equal(from[:], tAddr.Bytes()) {
-			return abciTypes.ResponseCheckTx{
-				Code: errors.CodeTypeInternalErr,
-				Log: fmt.Sprintf(
-					"Failed as there has been a stake/governance operation in current block")}
+			return common.Address{}, common.Address{}, 0,
+				app.traceStep(tx, "travis_tx", abciTypes.ResponseCheckTx{
+					Code: errors.CodeTypeInternalErr,
+					Log: fmt.Sprintf(
+						"Failed as there has been a stake/governance operation in current block")})
 		}
 	}
 
-	// Transactor should have enough funds to cover the costs
-	currentBalance := currentState.GetBalance(from)
+	payer = delegatedPayer
+	if payer == (common.Address{}) {
+		payer = app.gasPayer(currentState, from, tx)
+	}
+	if resp := app.traceStep(tx, "balance", checkSponsoredBalance(currentState, from, payer, tx)); resp.Code != abciTypes.CodeTypeOK {
+		return from, common.Address{}, nonce, resp
+	}
 
-	// cost == V + GP * GL
-	if currentBalance.Cmp(tx.Cost()) < 0 {
-		return abciTypes.ResponseCheckTx{
-			// TODO: Add errors.CodeTypeInsufficientFunds ?
-			Code: errors.CodeTypeBaseInvalidInput,
-			Log: fmt.Sprintf(
-				"Current balance: %s, tx cost: %s",
-				currentBalance, tx.Cost())}
+	if resp := app.traceStep(tx, "dust", app.checkDust(currentState, tx)); resp.Code != abciTypes.CodeTypeOK {
+		return common.Address{}, common.Address{}, 0, resp
 	}
 
-	intrGas, err := core.IntrinsicGas(tx.Data(), tx.To() == nil, true) // homestead == true
-	if err != nil {
-		return abciTypes.ResponseCheckTx{
-			Code: errors.CodeTypeBaseInvalidInput,
-			Log:  err.Error()}
+	if resp := app.traceStep(tx, "self_tx", app.checkSelfTx(from, tx)); resp.Code != abciTypes.CodeTypeOK {
+		return common.Address{}, common.Address{}, 0, resp
 	}
-	if tx.Gas() < intrGas {
-		return abciTypes.ResponseCheckTx{
-			Code: errors.CodeTypeBaseInvalidInput,
-			Log:  core.ErrIntrinsicGas.Error()}
-	}
-
-	// Iterate over all transactions to check if the gas price is too low for the
-	// non-first transaction with the same from/to address
-	// Todo performance maybe
-	var to common.Address
-	if tx.To() != nil {
-		to = *tx.To()
-	}
-	ft := FromTo{
-		from: from,
-		to:   to,
-	}
-	minGasPrice := new(big.Int).SetUint64(utils.GetParams().GasPrice)
-	if _, ok := app.lowPriceTransactions[ft]; ok {
-		if tx.GasPrice().Cmp(minGasPrice) < 0 {
-			// add failed count
-			// this map will keep growing because the nonce check will use it ongoing
-			app.checkFailedCount[from] = app.checkFailedCount[from] + 1
-			return abciTypes.ResponseCheckTx{Code: errors.CodeLowGasPriceErr, Log: "The gas price is too low for transaction"}
-		}
+
+	if resp := app.traceStep(tx, "recipient_gas_price", app.checkRecipientGasPrice(tx)); resp.Code != abciTypes.CodeTypeOK {
+		return common.Address{}, common.Address{}, 0, resp
 	}
-	if tx.GasPrice().Cmp(minGasPrice) < 0 {
-		app.lowPriceTransactions[ft] = tx
+
+	if resp := app.traceStep(tx, "recipient_address", app.checkRecipientAddress(tx)); resp.Code != abciTypes.CodeTypeOK {
+		return common.Address{}, common.Address{}, 0, resp
 	}
 
-	utils.NonceCheckedTx[tx.Hash()] = true
+	if resp := app.traceStep(tx, "account_ceiling", app.checkAccountCeiling(currentState, tx)); resp.Code != abciTypes.CodeTypeOK {
+		return common.Address{}, common.Address{}, 0, resp
+	}
 
-	// Update ether balances
-	// amount + gasprice * gaslimit
-	currentState.SubBalance(from, tx.Cost())
-	// tx.To() returns a pointer to a common address. It returns nil
-	// if it is a contract creation transaction.
-	if to := tx.To(); to != nil {
-		currentState.AddBalance(*to, tx.Value())
+	intrGas, err := intrinsicGas(tx.Data(), tx.To() == nil, app.backend.Ethereum().BlockChain().Config(), height)
+	if err != nil {
+		return common.Address{}, common.Address{}, 0,
+			app.traceStep(tx, "intrinsic_gas", abciTypes.ResponseCheckTx{
+				Code: errors.CodeTypeBaseInvalidInput,
+				Log:  err.Error()})
+	}
+	if resp := app.traceStep(tx, "intrinsic_gas", app.checkIntrinsicGas(tx, intrGas)); resp.Code != abciTypes.CodeTypeOK {
+		return common.Address{}, common.Address{}, 0, resp
+	}
+	if resp := app.traceStep(tx, "gas_ratio", app.checkGasRatio(tx, intrGas)); resp.Code != abciTypes.CodeTypeOK {
+		return common.Address{}, common.Address{}, 0, resp
 	}
-	currentState.SetNonce(from, nonce+1)
 
+	return from, payer, nonce, abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
+
+// checkBalance makes sure the transactor has enough funds to cover the costs
+func checkBalance(currentState *state.StateDB, from common.Address, tx *ethTypes.Transaction) abciTypes.ResponseCheckTx {
+	// GetBalance hands out the live balance of the state object,
+	// work on a copy so that no adjustment can leak into the state
+	currentBalance := new(big.Int).Set(currentState.GetBalance(from))
+
+	// cost == V + GP * GL
+	if currentBalance.Cmp(tx.Cost()) < 0 {
+		return abciTypes.ResponseCheckTx{
+			// TODO: Add errors.CodeTypeInsufficientFunds ?
+			Code: errors.CodeTypeBaseInvalidInput,
+			Log: fmt.Sprintf(
+				"Current balance: %s, tx cost: %s",
+				currentBalance, tx.Cost())}
+	}
 	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
 }
@@ -0,0 +1,110 @@
+package app
+
+import (
+	goerr "errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// faultInjectionEnv enables the fault_* options, which are refused otherwise
+const faultInjectionEnv = "TRAVIS_FAULT_INJECTION"
+
+// faultInjectionEnabled is read once from faultInjectionEnv
+var faultInjectionEnabled = os.Getenv(faultInjectionEnv) != ""
+
+var errFaultInjectionDisabled = fmt.Errorf("fault injection is disabled, set %s to enable it", faultInjectionEnv)
+
+// faultPoint is an operation a failure can be injected in
+type faultPoint string
+
+const (
+	// faultCommit fails Commit before the block is committed
+	faultCommit faultPoint = "commit"
+	// faultDeliverTx fails the delivery of a tx to the backend
+	faultDeliverTx faultPoint = "deliver_tx"
+	// faultResetState fails getting the latest state once the block is committed
+	faultResetState faultPoint = "reset_state"
+)
+
+// faultInjector fails the operations at the fault points with the configured
+// probabilities, for chaos testing the recovery paths. The draws are made from a
+// seeded source so that a run can be replayed.
+type faultInjector struct {
+	mu            sync.Mutex
+	probabilities map[faultPoint]float64
+	rng           *rand.Rand
+}
+
+// setProbability sets the probability of failing at a fault point; 0 disables it
+func (f *faultInjector) setProbability(point faultPoint, value string) error {
+	if !faultInjectionEnabled {
+		return errFaultInjectionDisabled
+	}
+	p, err := strconv.ParseFloat(value, 64)
+	if err != nil || p < 0 || p > 1 {
+		return fmt.Errorf("invalid probability: %s", value)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.probabilities == nil {
+		f.probabilities = make(map[faultPoint]float64)
+	}
+	f.probabilities[point] = p
+	return nil
+}
+
+// seed restarts the draws from the given seed
+func (f *faultInjector) seed(value string) error {
+	if !faultInjectionEnabled {
+		return errFaultInjectionDisabled
+	}
+	seed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid seed: %s", value)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rng = rand.New(rand.NewSource(seed))
+	return nil
+}
+
+// inject returns an error when a failure is drawn at the fault point
+func (f *faultInjector) inject(point faultPoint) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p := f.probabilities[point]
+	if p == 0 {
+		return nil
+	}
+	if f.rng == nil {
+		f.rng = rand.New(rand.NewSource(1))
+	}
+	if f.rng.Float64() >= p {
+		return nil
+	}
+	return goerr.New("injected fault: " + string(point))
+}
+
+// deliverToBackend delivers a tx to the backend, unless a failure is injected.
+// The gas is paid by payer, by the sender when payer is the zero address.
+func (app *EthermintApplication) deliverToBackend(tx *ethTypes.Transaction, payer common.Address) abciTypes.ResponseDeliverTx {
+	if err := app.faults.inject(faultDeliverTx); err != nil {
+		return abciTypes.ResponseDeliverTx{Code: errors.CodeTypeInternalErr, Log: err.Error()}
+	}
+	if payer != (common.Address{}) {
+		return app.backend.DeliverSponsoredTx(tx, payer)
+	}
+	return app.backend.DeliverTx(tx)
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tmLog "github.com/tendermint/tendermint/libs/log"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestFaultInjection(t *testing.T) {
+	assert := assert.New(t)
+
+	enabled := faultInjectionEnabled
+	defer func() { faultInjectionEnabled = enabled }()
+
+	app := &EthermintApplication{logger: tmLog.NewNopLogger(), checkTxState: newTestState()}
+	checkTxState := app.checkTxState
+
+	faultInjectionEnabled = false
+	assert.Equal(errFaultInjectionDisabled, app.setOption("fault_commit", "1"))
+	assert.Nil(app.faults.inject(faultCommit))
+
+	faultInjectionEnabled = true
+	assert.NotNil(app.setOption("fault_commit", "1.5"))
+
+	// the failed delivery isn't accounted in the block
+	assert.Nil(app.setOption("fault_deliver_tx", "1"))
+	resp := app.DeliverTx(pricedTx(0, 1))
+	assert.Equal(errors.CodeTypeInternalErr, resp.Code)
+	assert.Equal("injected fault: deliver_tx", resp.Log)
+	assert.Equal(uint64(0), app.blockCounters().TxCount)
+
+	// a failed commit leaves the block and the CheckTx state alone
+	app.countDeliveredTx(21000)
+	assert.Nil(app.setOption("fault_commit", "1"))
+	assert.Empty(app.Commit().Data)
+	assert.Equal(uint64(1), app.blockCounters().TxCount)
+	assert.True(checkTxState == app.checkTxState)
+
+	// the draws are replayed from the seed
+	draws := func() []bool {
+		assert.Nil(app.setOption("fault_reset_state", "0.5"))
+		assert.Nil(app.setOption("fault_seed", "42"))
+		var failed []bool
+		for i := 0; i < 100; i++ {
+			failed = append(failed, app.faults.inject(faultResetState) != nil)
+		}
+		return failed
+	}
+	first := draws()
+	assert.Equal(first, draws())
+	assert.Contains(first, true)
+	assert.Contains(first, false)
+}
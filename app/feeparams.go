@@ -0,0 +1,25 @@
+package app
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/CyberMiles/travis/utils"
+)
+
+// feeParams are the fee parameters CheckTx admits the txs with
+type feeParams struct {
+	// minimum gas price currently required, relaxed while the mempool is quiet
+	MinGasPrice *hexutil.Big `json:"minGasPrice"`
+	// minimum gas price of the chain params, required once the mempool is busy
+	ParamsGasPrice *hexutil.Big `json:"paramsGasPrice"`
+}
+
+// feeParams returns the current fee parameters
+func (app *EthermintApplication) feeParams() feeParams {
+	return feeParams{
+		MinGasPrice:    (*hexutil.Big)(app.minGasPrice()),
+		ParamsGasPrice: (*hexutil.Big)(new(big.Int).SetUint64(utils.GetParams().GasPrice)),
+	}
+}
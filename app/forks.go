@@ -0,0 +1,45 @@
+package app
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// isForked returns whether a fork scheduled at block s is active at the given head block.
+func isForked(s, head *big.Int) bool {
+	if s == nil || head == nil {
+		return false
+	}
+	return s.Cmp(head) <= 0
+}
+
+// workingHeight returns the number of the block currently being built
+func (app *EthermintApplication) workingHeight() *big.Int {
+	currentBlock := app.backend.Ethereum().BlockChain().CurrentBlock()
+	return new(big.Int).Add(currentBlock.Number(), big.NewInt(1))
+}
+
+// checkSenderCode implements EIP-3607: once active, transactions from
+// accounts with deployed code are rejected
+func (app *EthermintApplication) checkSenderCode(currentState *state.StateDB,
+	from common.Address, height *big.Int) abciTypes.ResponseCheckTx {
+
+	app.mu.Lock()
+	eip3607Block := app.eip3607Block
+	app.mu.Unlock()
+
+	if isForked(eip3607Block, height) && currentState.GetCodeSize(from) > 0 {
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeSenderHasCode,
+			Log: fmt.Sprintf(
+				"Sender not an eoa: address %s, codehash: %s",
+				from.Hex(), currentState.GetCodeHash(from).Hex())}
+	}
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/ethdb"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func newTestState() *state.StateDB {
+	st, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	return st
+}
+
+func TestCheckSenderCode(t *testing.T) {
+	assert := assert.New(t)
+
+	st := newTestState()
+	contract := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	eoa := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	st.SetCode(contract, []byte{0x60, 0x00, 0x60, 0x00, 0xf3})
+
+	app := &EthermintApplication{eip3607Block: big.NewInt(10)}
+
+	// before the fork, a sender with code is still accepted
+	assert.Equal(abciTypes.CodeTypeOK, app.checkSenderCode(st, contract, big.NewInt(9)).Code)
+
+	// at and after the fork it is rejected
+	assert.Equal(errors.CodeTypeSenderHasCode, app.checkSenderCode(st, contract, big.NewInt(10)).Code)
+	assert.Equal(errors.CodeTypeSenderHasCode, app.checkSenderCode(st, contract, big.NewInt(11)).Code)
+
+	// plain accounts are never affected
+	assert.Equal(abciTypes.CodeTypeOK, app.checkSenderCode(st, eoa, big.NewInt(11)).Code)
+
+	// without an activation height the check is disabled
+	app.eip3607Block = nil
+	assert.Equal(abciTypes.CodeTypeOK, app.checkSenderCode(st, contract, big.NewInt(11)).Code)
+}
+
+func TestSenderCodeOptionWhileChecking(t *testing.T) {
+	assert := assert.New(t)
+
+	st := newTestState()
+	eoa := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	app := &EthermintApplication{}
+
+	// run with -race, SetOption moves the fork while CheckTx reads it
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			assert.Nil(app.setOption("eip3607_block", "10"))
+			assert.Nil(app.setOption("eip3607_block", ""))
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		assert.Equal(abciTypes.CodeTypeOK, app.checkSenderCode(st, eoa, big.NewInt(11)).Code)
+	}
+	<-done
+}
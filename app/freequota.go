@@ -0,0 +1,65 @@
+package app
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// defaultFreeTxQuotaInterval is the period the free tx quota refills at by default
+const defaultFreeTxQuotaInterval = 24 * time.Hour
+
+// freeTxQuota lets each account send a few zero gas price txs per interval
+type freeTxQuota struct {
+	// zero gas price txs allowed per account and interval; 0 disables it
+	limit uint64
+	// refill period, defaultFreeTxQuotaInterval if not set
+	interval time.Duration
+	// start of the current period and txs delivered in it by account
+	since time.Time
+	used  map[common.Address]uint64
+}
+
+// refill resets the quota of all the accounts once the period is over
+func (q *freeTxQuota) refill(now time.Time) {
+	interval := q.interval
+	if interval <= 0 {
+		interval = defaultFreeTxQuotaInterval
+	}
+	if q.used == nil || now.Sub(q.since) >= interval {
+		q.used = make(map[common.Address]uint64)
+		q.since = now
+	}
+}
+
+// hasFreeTxQuota tells whether the sender may still send a zero gas price tx, the
+// caller holds mu
+func (app *EthermintApplication) hasFreeTxQuota(from common.Address,
+	tx *ethTypes.Transaction, now time.Time) bool {
+
+	if app.freeTxQuota.limit == 0 || tx.GasPrice().Sign() != 0 {
+		return false
+	}
+	app.freeTxQuota.refill(now)
+	return app.freeTxQuota.used[from] < app.freeTxQuota.limit
+}
+
+// consumeFreeTxQuota charges a delivered zero gas price tx to the quota of its sender
+func (app *EthermintApplication) consumeFreeTxQuota(tx *ethTypes.Transaction, now time.Time) {
+	if tx.GasPrice().Sign() != 0 {
+		return
+	}
+	from, err := app.sender(tx)
+	if err != nil {
+		return
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	if app.freeTxQuota.limit == 0 {
+		return
+	}
+	app.freeTxQuota.refill(now)
+	app.freeTxQuota.used[from]++
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestFreeTxQuota(t *testing.T) {
+	assert := assert.New(t)
+
+	app := newLowPriceTestApp()
+	signer := ethTypes.NewEIP155Signer(big.NewInt(777))
+	app.SetSignerResolver(func(tx *ethTypes.Transaction) ethTypes.Signer {
+		return signer
+	})
+	assert.Nil(app.setOption("free_tx_quota", "2"))
+	assert.Nil(app.setOption("free_tx_quota_interval", "3600"))
+
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	nonce := uint64(0)
+	freeTx := func() *ethTypes.Transaction {
+		tx, err := ethTypes.SignTx(
+			ethTypes.NewTransaction(nonce, to, big.NewInt(1), 21000, big.NewInt(0), nil), signer, key)
+		assert.Nil(err)
+		nonce++
+		return tx
+	}
+	now := time.Unix(1500000000, 0)
+
+	// zero gas price txs pass while the quota lasts, it's charged on delivery
+	for i := 0; i < 2; i++ {
+		tx := freeTx()
+		assert.Equal(abciTypes.CodeTypeOK, app.checkLowPrice(from, tx, now).Code)
+		app.consumeFreeTxQuota(tx, now)
+	}
+	assert.Empty(app.lowPriceTransactions)
+
+	// exhausted, the low price rules apply again: the first tx to the recipient
+	// is let through, the next one is rejected
+	assert.Equal(abciTypes.CodeTypeOK, app.checkLowPrice(from, freeTx(), now).Code)
+	assert.Len(app.lowPriceTransactions, 1)
+	assert.Equal(errors.CodeLowGasPriceErr, app.checkLowPrice(from, freeTx(), now).Code)
+
+	// another account has its own quota
+	other := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	assert.Equal(abciTypes.CodeTypeOK, app.checkLowPrice(other, freeTx(), now).Code)
+
+	// refilled after the interval
+	later := now.Add(time.Hour)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkLowPrice(from, freeTx(), later).Code)
+}
+
+func TestFreeTxQuotaDisabled(t *testing.T) {
+	assert := assert.New(t)
+
+	app := newLowPriceTestApp()
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	now := time.Now()
+
+	assert.False(app.hasFreeTxQuota(from, ethTypes.NewTransaction(0, to, big.NewInt(1), 21000, big.NewInt(0), nil), now))
+
+	// only zero gas price txs are covered
+	assert.Nil(app.setOption("free_tx_quota", "5"))
+	assert.False(app.hasFreeTxQuota(from, ethTypes.NewTransaction(0, to, big.NewInt(1), 21000, big.NewInt(1), nil), now))
+	assert.True(app.hasFreeTxQuota(from, ethTypes.NewTransaction(0, to, big.NewInt(1), 21000, big.NewInt(0), nil), now))
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// parseAddressList parses a comma separated list of addresses; an empty value
+// gives an empty list
+func parseAddressList(value string) ([]common.Address, error) {
+	var addrs []common.Address
+	for _, hex := range strings.Split(value, ",") {
+		hex = strings.TrimSpace(hex)
+		if hex == "" {
+			continue
+		}
+		if !common.IsHexAddress(hex) {
+			return nil, fmt.Errorf("invalid address: %s", hex)
+		}
+		addrs = append(addrs, common.HexToAddress(hex))
+	}
+	return addrs, nil
+}
+
+// setFreeTxTo replaces the recipients which can be called below the minimum gas price
+func (app *EthermintApplication) setFreeTxTo(addrs []common.Address) {
+	free := make(map[common.Address]struct{}, len(addrs))
+	for _, addr := range addrs {
+		free[addr] = struct{}{}
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.freeTxTo = free
+}
+
+// isFreeTx tells whether the tx calls a whitelisted recipient, the caller holds mu.
+// Contract creations are never free.
+func (app *EthermintApplication) isFreeTx(tx *ethTypes.Transaction) bool {
+	if tx.To() == nil {
+		return false
+	}
+	_, ok := app.freeTxTo[*tx.To()]
+	return ok
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+	"github.com/CyberMiles/travis/utils"
+)
+
+func TestFreeTxTo(t *testing.T) {
+	assert := assert.New(t)
+
+	gasPrice := utils.GetParams().GasPrice
+	defer func() { utils.GetParams().GasPrice = gasPrice }()
+	utils.GetParams().GasPrice = 5e9
+
+	app := newLowPriceTestApp()
+	voting := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	other := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	assert.Nil(app.setOption("free_tx_to", voting.Hex()))
+	assert.NotNil(app.setOption("free_tx_to", "0x12"))
+
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	now := time.Unix(1500000000, 0)
+	free := func(nonce uint64, to common.Address) *ethTypes.Transaction {
+		return ethTypes.NewTransaction(nonce, to, big.NewInt(0), 21000, big.NewInt(0), nil)
+	}
+
+	// a whitelisted recipient is callable for free, over and over
+	assert.Equal(abciTypes.CodeTypeOK, app.checkLowPrice(from, free(0, voting), now).Code)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkLowPrice(from, free(1, voting), now).Code)
+	assert.Empty(app.lowPriceTransactions)
+
+	// any other recipient falls back to the low price rule
+	assert.Equal(abciTypes.CodeTypeOK, app.checkLowPrice(from, free(2, other), now).Code)
+	assert.Equal(errors.CodeLowGasPriceErr, app.checkLowPrice(from, free(3, other), now).Code)
+
+	// as does a contract creation
+	creation := ethTypes.NewContractCreation(4, big.NewInt(0), 53000, big.NewInt(0), nil)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkLowPrice(from, creation, now).Code)
+
+	// clearing the whitelist
+	assert.Nil(app.setOption("free_tx_to", ""))
+	assert.Equal(abciTypes.CodeTypeOK, app.checkLowPrice(from, free(5, voting), now).Code)
+	assert.Equal(errors.CodeLowGasPriceErr, app.checkLowPrice(from, free(6, voting), now).Code)
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+const (
+	// maxNonceGap is how far ahead of the sender's nonce a tx is held
+	maxNonceGap = 16
+	// maxFutureTxsPerSender caps the held txs of a sender, the oldest is dropped beyond it
+	maxFutureTxsPerSender = 8
+	// maxFutureSenders caps the senders holding txs, see evictFutureSender
+	maxFutureSenders = 1024
+)
+
+// queueFutureTx holds a tx whose nonce is slightly ahead of the sender's nonce until
+// the gap is filled. Any other nonce mismatch is returned as is. The balance of the
+// sender, net of its admitted txs, must cover the tx along with the other held txs.
+// Once maxFutureSenders senders hold txs, a new sender has to evict one of them.
+func (app *EthermintApplication) queueFutureTx(from common.Address, nonce uint64, balance *big.Int,
+	tx *ethTypes.Transaction, resp abciTypes.ResponseCheckTx) abciTypes.ResponseCheckTx {
+
+	if tx.Nonce() <= nonce || tx.Nonce()-nonce > maxNonceGap {
+		return resp
+	}
+
+	app.mu.Lock()
+	queued := app.futureTxs[from]
+	if cost := queuedCost(queued, tx); balance.Cmp(cost) < 0 {
+		app.mu.Unlock()
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeBaseInvalidInput,
+			Log: fmt.Sprintf(
+				"Current balance: %s, cost of the queued txs: %s", balance, cost)}
+	}
+	if _, ok := app.futureTxs[from]; !ok && len(app.futureTxs) >= maxFutureSenders {
+		if !app.evictFutureSender(tx) {
+			app.mu.Unlock()
+			return resp
+		}
+	}
+	var replaced *ethTypes.Transaction
+	for i, qtx := range queued {
+		if qtx.Nonce() == tx.Nonce() {
+			queued[i] = tx
+			replaced = qtx
+			break
+		}
+	}
+	if replaced == nil {
+		queued = append(queued, tx)
+		if len(queued) > maxFutureTxsPerSender {
+			queued = queued[1:]
+		}
+	}
+	app.futureTxs[from] = queued
+	onTxReplaced := app.onTxReplaced
+	app.mu.Unlock()
+
+	if replaced != nil && onTxReplaced != nil {
+		onTxReplaced(replaced.Hash(), tx.Hash())
+	}
+
+	return abciTypes.ResponseCheckTx{
+		Code: errors.CodeTypeFutureNonce,
+		Log: fmt.Sprintf(
+			"Nonce %d queued until nonce %d is filled", tx.Nonce(), nonce)}
+}
+
+// evictFutureSender drops the held txs of the sender holding the cheapest one, to make
+// room for tx, unless tx is cheaper still. mu is held.
+func (app *EthermintApplication) evictFutureSender(tx *ethTypes.Transaction) bool {
+	var (
+		victim   common.Address
+		cheapest *ethTypes.Transaction
+	)
+	for from, queued := range app.futureTxs {
+		for _, qtx := range queued {
+			if cheapest == nil || cheaper(qtx, cheapest) {
+				victim, cheapest = from, qtx
+			}
+		}
+	}
+	if cheapest == nil || !cheaper(cheapest, tx) {
+		return false
+	}
+	delete(app.futureTxs, victim)
+	return true
+}
+
+// queuedCost sums the cost of a tx and of the held txs it doesn't replace
+func queuedCost(queued []*ethTypes.Transaction, tx *ethTypes.Transaction) *big.Int {
+	cost := tx.Cost()
+	for _, qtx := range queued {
+		if qtx.Nonce() != tx.Nonce() {
+			cost.Add(cost, qtx.Cost())
+		}
+	}
+	return cost
+}
+
+// promoteFutureTx resubmits the held tx of the sender with the given nonce, if any,
+// and drops the held txs made obsolete by it
+func (app *EthermintApplication) promoteFutureTx(from common.Address, nonce uint64) {
+	app.mu.Lock()
+	var next *ethTypes.Transaction
+	kept := app.futureTxs[from][:0]
+	for _, qtx := range app.futureTxs[from] {
+		switch {
+		case qtx.Nonce() == nonce:
+			next = qtx
+		case qtx.Nonce() > nonce:
+			kept = append(kept, qtx)
+		}
+	}
+	if len(kept) == 0 {
+		delete(app.futureTxs, from)
+	} else {
+		app.futureTxs[from] = kept
+	}
+	app.mu.Unlock()
+
+	if next == nil {
+		return
+	}
+	if app.resubmit != nil {
+		app.resubmit(next)
+		return
+	}
+	// CheckTx runs under the mempool lock, the tx can only re-enter it asynchronously
+	go func() {
+		if _, err := app.backend.BroadcastTxSync(next); err != nil {
+			app.logger.Error("Error resubmitting queued tx", "hash", next.Hash().Hex(), "err", err) // nolint: errcheck
+		}
+	}()
+}
@@ -0,0 +1,154 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// plentyBalance covers the cost of any number of held test txs
+var plentyBalance = big.NewInt(1e18)
+
+func newFutureTxTestApp() (*EthermintApplication, *[]*ethTypes.Transaction) {
+	var resubmitted []*ethTypes.Transaction
+	app := &EthermintApplication{
+		futureTxs: make(map[common.Address][]*ethTypes.Transaction),
+		resubmit: func(tx *ethTypes.Transaction) {
+			resubmitted = append(resubmitted, tx)
+		},
+	}
+	return app, &resubmitted
+}
+
+func TestFutureNonceQueued(t *testing.T) {
+	assert := assert.New(t)
+
+	app, resubmitted := newFutureTxTestApp()
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	badNonce := abciTypes.ResponseCheckTx{Code: errors.CodeTypeBadNonce}
+
+	// nonce 1 arrives while the sender is at nonce 0
+	tx1 := pricedTx(1, 1)
+	assert.Equal(errors.CodeTypeFutureNonce, app.queueFutureTx(from, 0, plentyBalance, tx1, badNonce).Code)
+
+	// nonce 0 gets admitted, which resubmits nonce 1
+	app.promoteFutureTx(from, 1)
+	assert.Equal([]*ethTypes.Transaction{tx1}, *resubmitted)
+	assert.Empty(app.futureTxs)
+
+	// stale and far ahead nonces are still rejected
+	assert.Equal(errors.CodeTypeBadNonce, app.queueFutureTx(from, 5, plentyBalance, pricedTx(3, 1), badNonce).Code)
+	assert.Equal(errors.CodeTypeBadNonce, app.queueFutureTx(from, 5, plentyBalance, pricedTx(5+maxNonceGap+1, 1), badNonce).Code)
+}
+
+func TestFutureNonceQueueCap(t *testing.T) {
+	assert := assert.New(t)
+
+	app, _ := newFutureTxTestApp()
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	badNonce := abciTypes.ResponseCheckTx{Code: errors.CodeTypeBadNonce}
+
+	for nonce := uint64(1); nonce <= maxFutureTxsPerSender+2; nonce++ {
+		app.queueFutureTx(from, 0, plentyBalance, pricedTx(nonce, 1), badNonce)
+	}
+	queued := app.futureTxs[from]
+	assert.Len(queued, maxFutureTxsPerSender)
+	// the oldest were dropped
+	assert.Equal(uint64(3), queued[0].Nonce())
+}
+
+func TestFutureSendersCap(t *testing.T) {
+	assert := assert.New(t)
+
+	app, _ := newFutureTxTestApp()
+	badNonce := abciTypes.ResponseCheckTx{Code: errors.CodeTypeBadNonce}
+	sender := func(i int) common.Address {
+		return common.BigToAddress(big.NewInt(int64(i + 1)))
+	}
+
+	// the first sender holds the cheapest tx
+	for i := 0; i < maxFutureSenders; i++ {
+		price := int64(2)
+		if i == 0 {
+			price = 1
+		}
+		assert.Equal(errors.CodeTypeFutureNonce, app.queueFutureTx(sender(i), 0, plentyBalance, pricedTx(1, price), badNonce).Code)
+	}
+	assert.Len(app.futureTxs, maxFutureSenders)
+
+	// a new sender has to outbid the cheapest held tx
+	newcomer := sender(maxFutureSenders)
+	assert.Equal(errors.CodeTypeBadNonce, app.queueFutureTx(newcomer, 0, plentyBalance, pricedTx(1, 1), badNonce).Code)
+	assert.NotContains(app.futureTxs, newcomer)
+
+	// its tx then takes the place of the cheapest sender
+	assert.Equal(errors.CodeTypeFutureNonce, app.queueFutureTx(newcomer, 0, plentyBalance, pricedTx(1, 3), badNonce).Code)
+	assert.Len(app.futureTxs, maxFutureSenders)
+	assert.Contains(app.futureTxs, newcomer)
+	assert.NotContains(app.futureTxs, sender(0))
+
+	// the senders already holding txs are unaffected by the cap
+	assert.Equal(errors.CodeTypeFutureNonce, app.queueFutureTx(sender(1), 0, plentyBalance, pricedTx(2, 1), badNonce).Code)
+	assert.Len(app.futureTxs[sender(1)], 2)
+}
+
+func TestFutureTxReplacedHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	app, _ := newFutureTxTestApp()
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	badNonce := abciTypes.ResponseCheckTx{Code: errors.CodeTypeBadNonce}
+
+	var replaced [][2]common.Hash
+	app.SetTxReplacedHandler(func(oldHash, newHash common.Hash) {
+		replaced = append(replaced, [2]common.Hash{oldHash, newHash})
+	})
+
+	held, other, bump := pricedTx(2, 1), pricedTx(3, 1), pricedTx(2, 5)
+	app.queueFutureTx(from, 0, plentyBalance, held, badNonce)
+	app.queueFutureTx(from, 0, plentyBalance, other, badNonce)
+	assert.Empty(replaced)
+
+	// a fee bump of the held nonce replaces it
+	assert.Equal(errors.CodeTypeFutureNonce, app.queueFutureTx(from, 0, plentyBalance, bump, badNonce).Code)
+	assert.Equal([][2]common.Hash{{held.Hash(), bump.Hash()}}, replaced)
+	assert.Equal([]*ethTypes.Transaction{bump, other}, app.futureTxs[from])
+
+	// without a handler the replacement goes on silently
+	app.SetTxReplacedHandler(nil)
+	app.queueFutureTx(from, 0, plentyBalance, pricedTx(3, 7), badNonce)
+	assert.Len(replaced, 1)
+}
+
+func TestFutureTxsCoveredByBalance(t *testing.T) {
+	assert := assert.New(t)
+
+	app, _ := newFutureTxTestApp()
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	badNonce := abciTypes.ResponseCheckTx{Code: errors.CodeTypeBadNonce}
+
+	// the balance covers one of the txs only
+	tx1, tx2 := pricedTx(1, 10), pricedTx(2, 10)
+	balance := new(big.Int).Add(tx1.Cost(), big.NewInt(1))
+	assert.Equal(errors.CodeTypeFutureNonce, app.queueFutureTx(from, 0, balance, tx1, badNonce).Code)
+	resp := app.queueFutureTx(from, 0, balance, tx2, badNonce)
+	assert.Equal(errors.CodeTypeBaseInvalidInput, resp.Code)
+	assert.Len(app.futureTxs[from], 1)
+
+	// a replacement of the held nonce only counts once
+	bump := pricedTx(1, 10)
+	assert.Equal(errors.CodeTypeFutureNonce, app.queueFutureTx(from, 0, balance, bump, badNonce).Code)
+	assert.Len(app.futureTxs[from], 1)
+
+	// with more funds both are held
+	balance.Add(balance, tx2.Cost())
+	assert.Equal(errors.CodeTypeFutureNonce, app.queueFutureTx(from, 0, balance, tx2, badNonce).Code)
+	assert.Len(app.futureTxs[from], 2)
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// checkGasRatio rejects a tx whose gas limit exceeds the configured multiple of its
+// intrinsic gas, as it may be trying to reserve block space cheaply
+func (app *EthermintApplication) checkGasRatio(tx *ethTypes.Transaction, intrGas uint64) abciTypes.ResponseCheckTx {
+	app.mu.Lock()
+	ratio := app.maxGasIntrinsicRatio
+	app.mu.Unlock()
+
+	if ratio == 0 || intrGas == 0 || intrGas > math.MaxUint64/ratio {
+		return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+	}
+
+	if limit := intrGas * ratio; tx.Gas() > limit {
+		// nolint: errcheck
+		app.logger.Info("Rejecting tx with suspiciously high gas limit",
+			"hash", tx.Hash().Hex(), "gas", tx.Gas(), "intrinsic", intrGas, "ratio", ratio)
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeBaseInvalidInput,
+			Log: fmt.Sprintf(
+				"Gas limit %d exceeds %d times the intrinsic gas %d",
+				tx.Gas(), ratio, intrGas)}
+	}
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
+
+// checkIntrinsicGas rejects a tx whose gas limit doesn't cover its intrinsic gas
+// raised by the intrinsic gas margin, so that a borderline tx is turned down in
+// CheckTx rather than failing in DeliverTx
+func (app *EthermintApplication) checkIntrinsicGas(tx *ethTypes.Transaction, intrGas uint64) abciTypes.ResponseCheckTx {
+	if tx.Gas() < intrGas {
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeBaseInvalidInput,
+			Log:  core.ErrIntrinsicGas.Error()}
+	}
+	app.mu.Lock()
+	margin := app.intrinsicGasMargin
+	app.mu.Unlock()
+
+	if margin == 0 {
+		return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+	}
+	floor := addSat(intrGas, mulSat(intrGas, margin)/100)
+	if tx.Gas() < floor {
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeBaseInvalidInput,
+			Log: fmt.Sprintf(
+				"Gas limit %d below the intrinsic gas %d plus a %d%% margin",
+				tx.Gas(), intrGas, margin)}
+	}
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
+
+// checkMaxTxGas rejects a tx asking for more gas than the per-tx cap, so that
+// a single tx can't monopolize a block
+func (app *EthermintApplication) checkMaxTxGas(tx *ethTypes.Transaction) abciTypes.ResponseCheckTx {
+	app.mu.Lock()
+	maxTxGas := app.maxTxGas
+	app.mu.Unlock()
+
+	if maxTxGas > 0 && tx.Gas() > maxTxGas {
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeBaseInvalidInput,
+			Log: fmt.Sprintf(
+				"Gas limit %d exceeds the per transaction maximum %d",
+				tx.Gas(), maxTxGas)}
+	}
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
+
+// intrinsicGas computes the intrinsic gas of a tx under the fork rules of the chain
+// config active at height, as the EVM of the backend charges it
+func intrinsicGas(data []byte, contractCreation bool, config *params.ChainConfig, height *big.Int) (uint64, error) {
+	return core.IntrinsicGas(data, contractCreation, config.IsHomestead(height))
+}
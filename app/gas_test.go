@@ -0,0 +1,113 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestCheckGasRatio(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{logger: tmLog.NewNopLogger(), maxGasIntrinsicRatio: 10}
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+
+	data := []byte{0x01}
+	intrGas, err := core.IntrinsicGas(data, false, true)
+	assert.Nil(err)
+
+	// tiny data with a huge gas limit is rejected
+	greedy := ethTypes.NewTransaction(0, to, big.NewInt(0), 8000000, big.NewInt(1), data)
+	assert.Equal(errors.CodeTypeBaseInvalidInput, app.checkGasRatio(greedy, intrGas).Code)
+
+	// a gas limit within the allowed multiple is fine
+	normal := ethTypes.NewTransaction(0, to, big.NewInt(0), intrGas*10, big.NewInt(1), data)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkGasRatio(normal, intrGas).Code)
+
+	// the check is off by default
+	app.maxGasIntrinsicRatio = 0
+	assert.Equal(abciTypes.CodeTypeOK, app.checkGasRatio(greedy, intrGas).Code)
+}
+
+func TestIntrinsicGasAcrossHomestead(t *testing.T) {
+	assert := assert.New(t)
+
+	config := &params.ChainConfig{HomesteadBlock: big.NewInt(100)}
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = 0xff
+	}
+	create := ethTypes.NewContractCreation(0, big.NewInt(0), 100000, big.NewInt(1), data)
+
+	before, err := intrinsicGas(create.Data(), true, config, big.NewInt(99))
+	assert.Nil(err)
+	assert.Equal(params.TxGas+1000*params.TxDataNonZeroGas, before)
+
+	after, err := intrinsicGas(create.Data(), true, config, big.NewInt(100))
+	assert.Nil(err)
+	assert.Equal(params.TxGasContractCreation+1000*params.TxDataNonZeroGas, after)
+
+	// the creation pays for its calldata before the fork but not after it
+	assert.True(create.Gas() >= before)
+	assert.True(create.Gas() < after)
+
+	// the calldata is priced as the EVM does, whatever the height
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	call := ethTypes.NewTransaction(0, to, big.NewInt(0), 100000, big.NewInt(1), data)
+	gas, err := intrinsicGas(call.Data(), false, config, big.NewInt(1000000))
+	assert.Nil(err)
+	evmGas, err := core.IntrinsicGas(call.Data(), false, true)
+	assert.Nil(err)
+	assert.Equal(evmGas, gas)
+}
+
+func TestCheckMaxTxGas(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{}
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	// below the constant block gas limit of the backend
+	tx := ethTypes.NewTransaction(0, to, big.NewInt(0), 9000000, big.NewInt(1), nil)
+
+	// no cap configured
+	assert.Equal(abciTypes.CodeTypeOK, app.checkMaxTxGas(tx).Code)
+
+	assert.Nil(app.setOption("max_tx_gas", "8000000"))
+	assert.Equal(errors.CodeTypeBaseInvalidInput, app.checkMaxTxGas(tx).Code)
+
+	tx = ethTypes.NewTransaction(0, to, big.NewInt(0), 8000000, big.NewInt(1), nil)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkMaxTxGas(tx).Code)
+}
+
+func TestIntrinsicGasMargin(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{logger: tmLog.NewNopLogger()}
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	intrGas := params.TxGas
+
+	// just above the bare intrinsic gas
+	borderline := ethTypes.NewTransaction(0, to, big.NewInt(0), intrGas+100, big.NewInt(1), nil)
+	short := ethTypes.NewTransaction(0, to, big.NewInt(0), intrGas-1, big.NewInt(1), nil)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkIntrinsicGas(borderline, intrGas).Code)
+	assert.Equal(core.ErrIntrinsicGas.Error(), app.checkIntrinsicGas(short, intrGas).Log)
+
+	// a 10% margin asks for 23100 gas
+	assert.Nil(app.setOption("intrinsic_gas_margin", "10"))
+	assert.Equal(errors.CodeTypeBaseInvalidInput, app.checkIntrinsicGas(borderline, intrGas).Code)
+	assert.Equal(errors.CodeTypeBaseInvalidInput, app.checkIntrinsicGas(short, intrGas).Code)
+	enough := ethTypes.NewTransaction(0, to, big.NewInt(0), intrGas*110/100, big.NewInt(1), nil)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkIntrinsicGas(enough, intrGas).Code)
+
+	assert.NotNil(app.setOption("intrinsic_gas_margin", "-1"))
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/CyberMiles/travis/utils"
+)
+
+// gasLimitBounds drive the adjustment of the block gas limit, which is disabled
+// while target is 0
+type gasLimitBounds struct {
+	min    uint64
+	max    uint64
+	target uint64
+}
+
+// chainGasLimitBounds returns the bounds of the chain params. All the validators
+// must agree on them, so they're set at genesis or by a change param proposal.
+func chainGasLimitBounds() gasLimitBounds {
+	p := utils.GetParams()
+	return gasLimitBounds{min: p.GasLimitMin, max: p.GasLimitMax, target: p.GasLimitTarget}
+}
+
+// nextGasLimit computes the gas limit of the block following one with the given
+// gas limit and gas used, like the ethereum miners do: below the target the limit
+// climbs to it, above it the limit follows the fullness of the blocks. Either way
+// it moves by less than 1/1024 per block, within the bounds and never below the
+// protocol minimum, whatever the inputs.
+func nextGasLimit(limit, gasUsed uint64, bounds gasLimitBounds) uint64 {
+	// a block can't use more than its limit
+	if gasUsed > limit {
+		gasUsed = limit
+	}
+	contrib := addSat(gasUsed, gasUsed/2) / params.GasLimitBoundDivisor
+	decay := limit / params.GasLimitBoundDivisor
+	if decay > 0 {
+		decay--
+	}
+
+	next := addSat(subSat(limit, decay), contrib)
+	if next < bounds.target {
+		next = addSat(limit, decay)
+		if next > bounds.target {
+			next = bounds.target
+		}
+	}
+
+	lo := bounds.min
+	if lo < params.MinGasLimit {
+		lo = params.MinGasLimit
+	}
+	return clampUint64(next, lo, bounds.max)
+}
+
+// adjustGasLimit pushes the gas limit of the next block to the backend, from the
+// gas used by the current one
+func (app *EthermintApplication) adjustGasLimit(gasUsed uint64) {
+	bounds := chainGasLimitBounds()
+
+	if bounds.target == 0 {
+		return
+	}
+	app.backend.SetNextGasLimit(nextGasLimit(app.backend.HeaderGasLimit(), gasUsed, bounds))
+}
+
+// seedGasLimit gives the first block built after a start the adjusted gas limit.
+// The work state was initialized with the default one, it's rebuilt with the limit
+// following the last committed block so that every node agrees on it.
+func (app *EthermintApplication) seedGasLimit() error {
+	bounds := chainGasLimitBounds()
+
+	if bounds.target == 0 || app.gasLimitSeeded {
+		return nil
+	}
+	app.gasLimitSeeded = true
+
+	parent := app.backend.Ethereum().BlockChain().CurrentBlock()
+	app.backend.SetNextGasLimit(nextGasLimit(parent.GasLimit(), parent.GasUsed(), bounds))
+	return app.backend.InitEthState(app.Receiver())
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CyberMiles/travis/utils"
+)
+
+func TestNextGasLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	bounds := gasLimitBounds{min: 5000000, max: 8100000, target: 8000000}
+
+	// full blocks raise the limit past the target, up to the max
+	limit := bounds.target
+	for i := 0; i < 100; i++ {
+		next := nextGasLimit(limit, limit, bounds)
+		assert.True(next >= limit)
+		assert.True(next-limit <= limit/1024)
+		assert.True(next <= bounds.max)
+		limit = next
+	}
+	assert.Equal(bounds.max, limit)
+
+	// empty blocks lower it back to the target, not below
+	for i := 0; i < 100; i++ {
+		next := nextGasLimit(limit, 0, bounds)
+		assert.True(next <= limit)
+		assert.True(limit-next <= limit/1024)
+		assert.True(next >= bounds.target)
+		limit = next
+	}
+	assert.Equal(bounds.target, limit)
+
+	// below the target even empty blocks make it climb
+	limit = bounds.min
+	next := nextGasLimit(limit, 0, bounds)
+	assert.True(next > limit)
+	assert.True(next <= bounds.target)
+}
+
+func TestNextGasLimitWithoutTarget(t *testing.T) {
+	assert := assert.New(t)
+
+	bounds := gasLimitBounds{min: 1000000}
+	limit := uint64(2000000)
+	for i := 0; i < 2000; i++ {
+		limit = nextGasLimit(limit, 0, bounds)
+	}
+	assert.Equal(bounds.min, limit)
+}
+
+func TestGasLimitParams(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(utils.SetParam("gas_limit_min", "5000000"))
+	assert.True(utils.SetParam("gas_limit_max", "8100000"))
+	assert.True(utils.SetParam("gas_limit_target", "8000000"))
+	defer func() {
+		for _, name := range []string{"gas_limit_min", "gas_limit_max", "gas_limit_target"} {
+			utils.SetParam(name, "0")
+		}
+	}()
+	assert.Equal(gasLimitBounds{min: 5000000, max: 8100000, target: 8000000}, chainGasLimitBounds())
+	assert.False(utils.CheckParamType("gas_limit_target", "-1"))
+
+	// the options don't move the bounds anymore
+	app := &EthermintApplication{}
+	assert.NotNil(app.setOption("gas_limit_target", "9000000"))
+	assert.Equal(uint64(8000000), chainGasLimitBounds().target)
+}
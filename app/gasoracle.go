@@ -0,0 +1,105 @@
+package app
+
+import (
+	"math/big"
+	"sort"
+
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// defaultGasOracleBlocks is the number of committed blocks sampled by default
+	defaultGasOracleBlocks = 20
+	// defaultGasOraclePercentile is the percentile of the sampled prices suggested by default
+	defaultGasOraclePercentile = 60
+)
+
+// gasPriceOracle samples the gas prices of the txs delivered in the last
+// committed blocks to suggest a gas price
+type gasPriceOracle struct {
+	// committed blocks sampled, defaultGasOracleBlocks if not set
+	blocks int
+	// percentile of the sampled prices suggested, defaultGasOraclePercentile if not set
+	percentile uint64
+	// prices of the txs delivered in the current block
+	current []*big.Int
+	// prices of the last committed blocks, the oldest first
+	samples [][]*big.Int
+}
+
+func (o *gasPriceOracle) window() int {
+	if o.blocks <= 0 {
+		return defaultGasOracleBlocks
+	}
+	return o.blocks
+}
+
+// record samples the price of a tx delivered in the current block
+func (o *gasPriceOracle) record(price *big.Int) {
+	o.current = append(o.current, new(big.Int).Set(price))
+}
+
+// commitBlock closes the samples of the current block and drops the blocks
+// falling out of the window. The blocks without txs take a slot as well.
+func (o *gasPriceOracle) commitBlock() {
+	o.samples = append(o.samples, o.current)
+	o.current = nil
+	o.trim()
+}
+
+// trim keeps the samples of the last window blocks only
+func (o *gasPriceOracle) trim() {
+	if over := len(o.samples) - o.window(); over > 0 {
+		o.samples = append([][]*big.Int(nil), o.samples[over:]...)
+	}
+}
+
+// suggest returns the configured percentile of the sampled prices, nil without
+// any sample
+func (o *gasPriceOracle) suggest() *big.Int {
+	var prices []*big.Int
+	for _, block := range o.samples {
+		prices = append(prices, block...)
+	}
+	if len(prices) == 0 {
+		return nil
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Cmp(prices[j]) < 0 })
+
+	percentile := o.percentile
+	if percentile == 0 {
+		percentile = defaultGasOraclePercentile
+	}
+	return new(big.Int).Set(prices[(len(prices)-1)*int(percentile)/100])
+}
+
+// recordGasPriceSample samples the gas price of a delivered tx
+func (app *EthermintApplication) recordGasPriceSample(tx *ethTypes.Transaction) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.gasOracle.record(tx.GasPrice())
+}
+
+// commitGasPriceSamples closes the samples of the committed block
+func (app *EthermintApplication) commitGasPriceSamples() {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.gasOracle.commitBlock()
+}
+
+// suggestGasPrice returns the gas price suggested from the recently committed
+// blocks, never below the minimum gas price
+func (app *EthermintApplication) suggestGasPrice() gasPriceQuote {
+	minGasPrice := app.minGasPrice()
+
+	app.mu.Lock()
+	price := app.gasOracle.suggest()
+	app.mu.Unlock()
+
+	if price == nil || price.Cmp(minGasPrice) < 0 {
+		price = minGasPrice
+	}
+	return newGasPriceQuote(price)
+}
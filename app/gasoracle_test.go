@@ -0,0 +1,56 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func gweis(values ...int64) []*big.Int {
+	var prices []*big.Int
+	for _, gwei := range values {
+		prices = append(prices, new(big.Int).Mul(big.NewInt(gwei), big.NewInt(1e9)))
+	}
+	return prices
+}
+
+func TestGasPriceOracle(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{}
+	assert.Nil(app.setOption("gas_oracle_blocks", "2"))
+	assert.Nil(app.setOption("gas_oracle_percentile", "50"))
+	assert.NotNil(app.setOption("gas_oracle_percentile", "101"))
+	// a window which would be negative as an int is rejected
+	assert.NotNil(app.setOption("gas_oracle_blocks", "9223372036854775808"))
+	assert.NotNil(app.setOption("gas_oracle_blocks", "2147483648"))
+
+	// no sample yet, the minimum is suggested
+	assert.Equal(app.MinGasPriceGwei(), app.suggestGasPrice().Gwei)
+
+	for _, block := range [][]*big.Int{gweis(100, 100, 100), gweis(3, 1, 2), gweis(6, 5, 4)} {
+		for _, price := range block {
+			app.recordGasPriceSample(pricedTx(0, price.Int64()))
+		}
+		app.commitGasPriceSamples()
+	}
+	// the first block fell out of the window: median of 1..6
+	assert.Equal(2, len(app.gasOracle.samples))
+	assert.Equal("3", app.suggestGasPrice().Gwei)
+
+	assert.Nil(app.setOption("gas_oracle_percentile", "100"))
+	assert.Equal("6", app.suggestGasPrice().Gwei)
+
+	// an empty block takes a slot of the window
+	app.commitGasPriceSamples()
+	assert.Equal("6", app.suggestGasPrice().Gwei)
+	assert.Nil(app.setOption("gas_oracle_blocks", "1"))
+	assert.Equal(1, len(app.gasOracle.samples))
+	assert.Equal(app.MinGasPriceGwei(), app.suggestGasPrice().Gwei)
+
+	// the suggestion is never below the minimum gas price
+	app.recordGasPriceSample(pricedTx(0, 1))
+	app.commitGasPriceSamples()
+	assert.Equal(app.MinGasPriceGwei(), app.suggestGasPrice().Gwei)
+}
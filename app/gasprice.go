@@ -0,0 +1,87 @@
+package app
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// gasPriceQuote is a gas price both in wei and in gwei
+type gasPriceQuote struct {
+	Wei  *hexutil.Big `json:"wei"`
+	Gwei string       `json:"gwei"`
+}
+
+func newGasPriceQuote(wei *big.Int) gasPriceQuote {
+	return gasPriceQuote{Wei: (*hexutil.Big)(wei), Gwei: formatGwei(wei)}
+}
+
+// gasPriceView is the result of the travis_gasPrice query: the minimum gas price
+// and the prices suggested to get a tx included slowly, normally or fast
+type gasPriceView struct {
+	MinGasPrice gasPriceQuote `json:"minGasPrice"`
+	Slow        gasPriceQuote `json:"slow"`
+	Standard    gasPriceQuote `json:"standard"`
+	Fast        gasPriceQuote `json:"fast"`
+}
+
+// MinGasPriceGwei returns the gas price currently required by CheckTx in gwei
+// #unstable
+func (app *EthermintApplication) MinGasPriceGwei() string {
+	return formatGwei(app.minGasPrice())
+}
+
+// gasPriceView returns the minimum gas price with the tiers suggested from the
+// prices of the txs waiting in the mempool
+func (app *EthermintApplication) gasPriceView() gasPriceView {
+	minGasPrice := app.minGasPrice()
+
+	app.mu.Lock()
+	var prices []*big.Int
+	if app.pool != nil {
+		for _, ptx := range app.pool.priced {
+			prices = append(prices, ptx.tx.GasPrice())
+		}
+	}
+	app.mu.Unlock()
+
+	slow, standard, fast := gasPriceTiers(minGasPrice, prices)
+	return gasPriceView{
+		MinGasPrice: newGasPriceQuote(minGasPrice),
+		Slow:        newGasPriceQuote(slow),
+		Standard:    newGasPriceQuote(standard),
+		Fast:        newGasPriceQuote(fast),
+	}
+}
+
+// gasPriceTiers returns the 25th, 50th and 75th percentiles of the pending prices,
+// none of them below the minimum gas price. With an empty mempool all the tiers
+// are the minimum.
+func gasPriceTiers(minGasPrice *big.Int, prices []*big.Int) (slow, standard, fast *big.Int) {
+	sorted := make([]*big.Int, 0, len(prices))
+	for _, price := range prices {
+		if price.Cmp(minGasPrice) > 0 {
+			sorted = append(sorted, price)
+		} else {
+			sorted = append(sorted, minGasPrice)
+		}
+	}
+	if len(sorted) == 0 {
+		return new(big.Int).Set(minGasPrice), new(big.Int).Set(minGasPrice), new(big.Int).Set(minGasPrice)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+	percentile := func(p int) *big.Int {
+		return new(big.Int).Set(sorted[(len(sorted)-1)*p/100])
+	}
+	return percentile(25), percentile(50), percentile(75)
+}
+
+// formatGwei formats an amount of wei in gwei, without losing the fractional part
+func formatGwei(wei *big.Int) string {
+	gwei := new(big.Rat).SetFrac(wei, big.NewInt(params.Shannon)).FloatString(9)
+	gwei = strings.TrimRight(gwei, "0")
+	return strings.TrimSuffix(gwei, ".")
+}
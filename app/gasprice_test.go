@@ -0,0 +1,56 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatGwei(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("2", formatGwei(big.NewInt(2e9)))
+	assert.Equal("0", formatGwei(big.NewInt(0)))
+	assert.Equal("1.5", formatGwei(big.NewInt(15e8)))
+	assert.Equal("0.000000001", formatGwei(big.NewInt(1)))
+	assert.Equal("100", formatGwei(big.NewInt(1e11)))
+}
+
+func TestGasPriceTiers(t *testing.T) {
+	assert := assert.New(t)
+	minGasPrice := big.NewInt(2e9)
+
+	// an empty mempool suggests the minimum
+	slow, standard, fast := gasPriceTiers(minGasPrice, nil)
+	assert.Equal(minGasPrice.String(), slow.String())
+	assert.Equal(minGasPrice.String(), standard.String())
+	assert.Equal(minGasPrice.String(), fast.String())
+
+	var prices []*big.Int
+	for _, gwei := range []int64{9, 1, 4, 7, 3, 10, 2, 6, 8, 5} {
+		prices = append(prices, new(big.Int).Mul(big.NewInt(gwei), big.NewInt(1e9)))
+	}
+	slow, standard, fast = gasPriceTiers(minGasPrice, prices)
+	assert.True(slow.Cmp(minGasPrice) >= 0)
+	assert.True(standard.Cmp(slow) >= 0)
+	assert.True(fast.Cmp(standard) >= 0)
+	assert.Equal("3", formatGwei(slow))
+	assert.Equal("5", formatGwei(standard))
+	assert.Equal("7", formatGwei(fast))
+
+	// the prices below the minimum don't drag the tiers under it
+	slow, _, _ = gasPriceTiers(minGasPrice, []*big.Int{big.NewInt(1), big.NewInt(1)})
+	assert.Equal(minGasPrice.String(), slow.String())
+	assert.Equal(int64(2e9), minGasPrice.Int64())
+}
+
+func TestGasPriceView(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{pool: newTxPool(newTestState())}
+	view := app.gasPriceView()
+	assert.Equal(view.MinGasPrice.Gwei, app.MinGasPriceGwei())
+	assert.Equal(view.MinGasPrice.Wei.String(), view.Slow.Wei.String())
+	assert.Equal(view.MinGasPrice.Wei.String(), view.Fast.Wei.String())
+}
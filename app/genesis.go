@@ -0,0 +1,173 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
+)
+
+// genesisState is the app state of the genesis
+type genesisState struct {
+	// balances by hex address, in decimal
+	Alloc map[string]string `json:"alloc"`
+	// optional sum of the allocated balances, in decimal, checked by InitChain
+	ExpectedTotal string `json:"expected_total,omitempty"`
+}
+
+// genesisAlloc is the initial balance of an account
+type genesisAlloc struct {
+	Address common.Address
+	Balance *big.Int
+}
+
+// parseGenesisAlloc parses the balance allocations of the genesis app state, sorted
+// by address. An empty app state allocates nothing.
+func parseGenesisAlloc(appState []byte) ([]genesisAlloc, error) {
+	if len(bytes.TrimSpace(appState)) == 0 {
+		return nil, nil
+	}
+
+	var genesis genesisState
+	if err := json.Unmarshal(appState, &genesis); err != nil {
+		return nil, fmt.Errorf("malformed app state: %v", err)
+	}
+
+	allocs := make([]genesisAlloc, 0, len(genesis.Alloc))
+	seen := make(map[common.Address]bool)
+	for hex, value := range genesis.Alloc {
+		if !common.IsHexAddress(hex) {
+			return nil, fmt.Errorf("invalid alloc address: %s", hex)
+		}
+		addr := common.HexToAddress(hex)
+		if seen[addr] {
+			return nil, fmt.Errorf("duplicate alloc address: %s", hex)
+		}
+		seen[addr] = true
+
+		balance, ok := new(big.Int).SetString(value, 10)
+		if !ok || balance.Sign() < 0 {
+			return nil, fmt.Errorf("invalid alloc balance of %s: %s", hex, value)
+		}
+		allocs = append(allocs, genesisAlloc{Address: addr, Balance: balance})
+	}
+
+	sort.Slice(allocs, func(i, j int) bool {
+		return bytes.Compare(allocs[i].Address[:], allocs[j].Address[:]) < 0
+	})
+	return allocs, nil
+}
+
+// parseGenesisExpectedTotal parses the expected sum of the allocated balances of
+// the genesis app state, nil when it's not given
+func parseGenesisExpectedTotal(appState []byte) (*big.Int, error) {
+	if len(bytes.TrimSpace(appState)) == 0 {
+		return nil, nil
+	}
+
+	var genesis genesisState
+	if err := json.Unmarshal(appState, &genesis); err != nil {
+		return nil, fmt.Errorf("malformed app state: %v", err)
+	}
+	if genesis.ExpectedTotal == "" {
+		return nil, nil
+	}
+	total, ok := new(big.Int).SetString(genesis.ExpectedTotal, 10)
+	if !ok || total.Sign() < 0 {
+		return nil, fmt.Errorf("invalid expected total: %s", genesis.ExpectedTotal)
+	}
+	return total, nil
+}
+
+// genesisAllocTotal returns the sum of the allocated balances
+func genesisAllocTotal(allocs []genesisAlloc) *big.Int {
+	total := new(big.Int)
+	for _, alloc := range allocs {
+		total.Add(total, alloc.Balance)
+	}
+	return total
+}
+
+// genesisSupply is the result of the travis_genesisSupply query
+type genesisSupply struct {
+	Accounts int          `json:"accounts"`
+	Total    *hexutil.Big `json:"total"`
+}
+
+// verifyGenesisAlloc records the sum of the allocated balances and checks it
+// against the expected total of the app state, if any
+func (app *EthermintApplication) verifyGenesisAlloc(appState []byte, allocs []genesisAlloc) error {
+	expected, err := parseGenesisExpectedTotal(appState)
+	if err != nil {
+		return err
+	}
+	total := genesisAllocTotal(allocs)
+	// nolint: errcheck
+	app.logger.Info("Genesis alloc", "accounts", len(allocs), "total", total)
+
+	app.mu.Lock()
+	app.genesisSupply = &genesisSupply{Accounts: len(allocs), Total: (*hexutil.Big)(total)}
+	app.mu.Unlock()
+
+	if expected != nil && total.Cmp(expected) != 0 {
+		return fmt.Errorf("allocated balances sum up to %s, expected %s", total, expected)
+	}
+	return nil
+}
+
+// genesisSupplyView returns the allocations of the genesis, nil unless InitChain
+// ran since the node started
+func (app *EthermintApplication) genesisSupplyView() *genesisSupply {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	return app.genesisSupply
+}
+
+// claimInitChain marks the chain initialized, false with the committed height when
+// it was already: InitChain ran since the node started, or the chain is past its
+// genesis. The flag isn't persisted, after a restart the height tells it.
+func (app *EthermintApplication) claimInitChain() (uint64, bool) {
+	var height uint64
+	if app.backend != nil {
+		height = app.backend.Ethereum().BlockChain().CurrentBlock().NumberU64()
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if app.chainInitialized || height != 0 {
+		return height, false
+	}
+	app.chainInitialized = true
+	return 0, true
+}
+
+// applyGenesisAlloc sets the allocated balances in a state
+func applyGenesisAlloc(st *state.StateDB, allocs []genesisAlloc) {
+	for _, alloc := range allocs {
+		st.SetBalance(alloc.Address, alloc.Balance)
+	}
+}
+
+// initGenesisAlloc applies the allocations to the state of the first block and to
+// the CheckTx state. It's a no-op past the genesis.
+func (app *EthermintApplication) initGenesisAlloc(allocs []genesisAlloc) {
+	if height := app.backend.Ethereum().BlockChain().CurrentBlock().NumberU64(); height != 0 {
+		// nolint: errcheck
+		app.logger.Error("Ignoring the genesis alloc past the genesis", "height", height)
+		return
+	}
+
+	applyGenesisAlloc(app.backend.DeliverTxState(), allocs)
+
+	app.checkTxStateMtx.Lock()
+	defer app.checkTxStateMtx.Unlock()
+	applyGenesisAlloc(app.checkTxState, allocs)
+	app.resetPending(app.checkTxState)
+}
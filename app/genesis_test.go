@@ -0,0 +1,108 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+)
+
+func TestGenesisAlloc(t *testing.T) {
+	assert := assert.New(t)
+
+	appState := []byte(`{"alloc": {
+		"0x2000000000000000000000000000000000000002": "500",
+		"0x1000000000000000000000000000000000000001": "1000000000000000000000"
+	}}`)
+	allocs, err := parseGenesisAlloc(appState)
+	assert.Nil(err)
+	assert.Len(allocs, 2)
+
+	st := newTestState()
+	applyGenesisAlloc(st, allocs)
+
+	balance, _ := new(big.Int).SetString("1000000000000000000000", 10)
+	assert.Equal(balance, st.GetBalance(common.HexToAddress("0x1000000000000000000000000000000000000001")))
+	assert.Equal(big.NewInt(500), st.GetBalance(common.HexToAddress("0x2000000000000000000000000000000000000002")))
+}
+
+func TestGenesisAllocInvalid(t *testing.T) {
+	assert := assert.New(t)
+
+	allocs, err := parseGenesisAlloc(nil)
+	assert.Nil(err)
+	assert.Empty(allocs)
+
+	for _, appState := range []string{
+		`{"alloc": `,
+		`{"alloc": {"0x12": "1"}}`,
+		`{"alloc": {"0x1000000000000000000000000000000000000001": "-1"}}`,
+		`{"alloc": {"0x1000000000000000000000000000000000000001": "0x10"}}`,
+		`{"alloc": {"0x1000000000000000000000000000000000000001": 1}}`,
+		`{"alloc": {
+			"0x00000000000000000000000000000000000000aa": "1",
+			"0x00000000000000000000000000000000000000AA": "2"
+		}}`,
+	} {
+		_, err := parseGenesisAlloc([]byte(appState))
+		assert.NotNil(err, appState)
+	}
+}
+
+func TestGenesisExpectedTotal(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{logger: tmLog.NewNopLogger()}
+	assert.Nil(app.genesisSupplyView())
+
+	alloc := `"alloc": {
+		"0x1000000000000000000000000000000000000001": "1000000000000000000000",
+		"0x2000000000000000000000000000000000000002": "500"
+	}`
+	correct := []byte(`{` + alloc + `, "expected_total": "1000000000000000000500"}`)
+	allocs, err := parseGenesisAlloc(correct)
+	assert.Nil(err)
+	assert.Nil(app.verifyGenesisAlloc(correct, allocs))
+	supply := app.genesisSupplyView()
+	assert.Equal(2, supply.Accounts)
+	assert.Equal("1000000000000000000500", supply.Total.ToInt().String())
+
+	// a mismatch fails the init, the sum is recorded anyway
+	incorrect := []byte(`{` + alloc + `, "expected_total": "1000000000000000000000"}`)
+	assert.NotNil(app.verifyGenesisAlloc(incorrect, allocs))
+	assert.Equal("1000000000000000000500", app.genesisSupplyView().Total.ToInt().String())
+
+	// the expected total is optional, and has to be a decimal amount
+	assert.Nil(app.verifyGenesisAlloc([]byte(`{`+alloc+`}`), allocs))
+	assert.Nil(app.verifyGenesisAlloc(nil, nil))
+	assert.Equal(0, app.genesisSupplyView().Accounts)
+	assert.NotNil(app.verifyGenesisAlloc([]byte(`{`+alloc+`, "expected_total": "0x10"}`), allocs))
+}
+
+func TestInitChainTwice(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{logger: tmLog.NewNopLogger()}
+	app.InitChain(abciTypes.RequestInitChain{
+		Validators:    []abciTypes.Validator{testValidator(1, 10)},
+		AppStateBytes: []byte(`{"alloc": {}, "expected_total": "0"}`),
+	})
+	assert.Equal([]abciTypes.Validator{testValidator(1, 10)}, app.validators)
+	supply := app.genesisSupplyView()
+	assert.Equal(0, supply.Accounts)
+
+	// a replay leaves the validators and the allocations alone, even an invalid
+	// genesis isn't looked at
+	app.InitChain(abciTypes.RequestInitChain{
+		Validators: []abciTypes.Validator{testValidator(2, 20)},
+		AppStateBytes: []byte(`{"alloc": {
+			"0x1000000000000000000000000000000000000001": "1000"
+		}, "expected_total": "1"}`),
+	})
+	assert.Equal([]abciTypes.Validator{testValidator(1, 10)}, app.validators)
+	assert.Equal(supply, app.genesisSupplyView())
+}
@@ -45,7 +45,7 @@ func (app BaseApp) checkHandler(ctx types.Context, store state.SimpleDB, tx *eth
 		return errors.CheckResult(err)
 	}
 
-	utils.NonceCheckedTx[tx.Hash()] = true
+	utils.NonceCheckedTx.Add(tx.Hash())
 	currentState.SetNonce(from, nonce+1)
 
 	return res.ToABCI()
@@ -59,10 +59,8 @@ func (app BaseApp) deliverHandler(ctx types.Context, store state.SimpleDB, tx *e
 		return errors.DeliverResult(err)
 	}
 
-	signer := ethTypes.NewEIP155Signer(tx.ChainId())
-
 	// Make sure the transaction is signed properly
-	from, err := ethTypes.Sender(signer, tx)
+	from, err := app.EthApp.sender(tx)
 	if err != nil {
 		return errors.DeliverResult(err)
 	}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"github.com/CyberMiles/travis/utils"
+)
+
+// clampHeaderTime bounds the time of a tendermint header to the time of the parent
+// block, so that the EVM time never goes backward, and to maxDrift seconds past it;
+// a maxDrift of 0 leaves it unbounded above. The bounds only depend on the chain, so
+// every node clamps a header alike.
+func clampHeaderTime(headerTime, parentTime int64, maxDrift uint64) int64 {
+	if headerTime < parentTime {
+		return parentTime
+	}
+	if maxDrift > 0 && uint64(headerTime-parentTime) > maxDrift {
+		return parentTime + int64(maxDrift)
+	}
+	return headerTime
+}
+
+// checkHeaderTime returns the time of the block being built from the time of the
+// tendermint header, clamped and logged when it's out of bounds. The drift bound is
+// a chain param, set at genesis or by a change param proposal, since the clamped time
+// goes into the block.
+func (app *EthermintApplication) checkHeaderTime(headerTime, parentTime int64) int64 {
+	clamped := clampHeaderTime(headerTime, parentTime, utils.GetParams().MaxHeaderTimeDrift)
+	if clamped != headerTime {
+		// nolint: errcheck
+		app.logger.Error("Clamping the header time", "time", headerTime,
+			"parent", parentTime, "clamped", clamped)
+	}
+	return clamped
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tmLog "github.com/tendermint/tendermint/libs/log"
+
+	"github.com/CyberMiles/travis/utils"
+)
+
+func TestHeaderTimeClamp(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{logger: tmLog.NewNopLogger()}
+	parent := int64(1500000000)
+
+	// a header time going backward is clamped to the parent time
+	assert.Equal(parent, app.checkHeaderTime(parent-100, parent))
+	assert.Equal(parent, app.checkHeaderTime(parent, parent))
+
+	// without a drift bound a far-future time is kept
+	assert.Equal(parent+86400, app.checkHeaderTime(parent+86400, parent))
+
+	// with one it's clamped to the bound
+	assert.True(utils.SetParam("max_header_time_drift", "60"))
+	defer utils.SetParam("max_header_time_drift", "0")
+	assert.Equal(parent+60, app.checkHeaderTime(parent+86400, parent))
+	assert.Equal(parent+60, app.checkHeaderTime(parent+60, parent))
+	assert.Equal(parent+5, app.checkHeaderTime(parent+5, parent))
+	assert.Equal(parent, app.checkHeaderTime(parent-1, parent))
+
+	assert.False(utils.CheckParamType("max_header_time_drift", "-1"))
+	// the option doesn't move the bound anymore
+	assert.NotNil(app.setOption("max_header_time_drift", "1"))
+	assert.Equal(parent+60, app.checkHeaderTime(parent+86400, parent))
+}
+
+func TestHeaderTimeClampOverflow(t *testing.T) {
+	assert := assert.New(t)
+
+	// a bound past the int64 range leaves the time as is
+	assert.Equal(int64(math.MaxInt64), clampHeaderTime(math.MaxInt64, 0, math.MaxUint64))
+	assert.Equal(int64(math.MaxInt64-1), clampHeaderTime(math.MaxInt64, 1, math.MaxInt64-2))
+}
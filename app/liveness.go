@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+)
+
+// validatorLiveness counts the liveness faults of a validator
+type validatorLiveness struct {
+	MissedBlocks int64 `json:"missed_blocks"`
+	Byzantine    int64 `json:"byzantine"`
+}
+
+// recordLiveness accumulates the missed blocks and byzantine evidence reported in BeginBlock
+func (app *EthermintApplication) recordLiveness(req abciTypes.RequestBeginBlock) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	for _, sv := range req.Validators {
+		if !sv.SignedLastBlock {
+			app.validatorLiveness(sv.Validator.Address).MissedBlocks++
+		}
+	}
+	for _, ev := range req.ByzantineValidators {
+		app.validatorLiveness(ev.Validator.Address).Byzantine++
+	}
+}
+
+// validatorLiveness returns the counters of a validator, creating them if needed.
+// app.mu must be held.
+func (app *EthermintApplication) validatorLiveness(address []byte) *validatorLiveness {
+	key := fmt.Sprintf("%X", address)
+	l, ok := app.liveness[key]
+	if !ok {
+		l = &validatorLiveness{}
+		app.liveness[key] = l
+	}
+	return l
+}
+
+// MissedBlocks returns how many blocks the validator has missed to sign
+// #unstable
+func (app *EthermintApplication) MissedBlocks(address []byte) int64 {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if l, ok := app.liveness[fmt.Sprintf("%X", address)]; ok {
+		return l.MissedBlocks
+	}
+	return 0
+}
+
+// ResetLiveness clears the counters of a validator, e.g. once it has been jailed
+// #unstable
+func (app *EthermintApplication) ResetLiveness(address []byte) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	delete(app.liveness, fmt.Sprintf("%X", address))
+}
+
+// livenessSnapshot returns a copy of the counters of all validators
+func (app *EthermintApplication) livenessSnapshot() map[string]validatorLiveness {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	snapshot := make(map[string]validatorLiveness, len(app.liveness))
+	for k, l := range app.liveness {
+		snapshot[k] = *l
+	}
+	return snapshot
+}
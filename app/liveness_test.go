@@ -0,0 +1,46 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+)
+
+func TestRecordLiveness(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{
+		logger:   tmLog.NewNopLogger(),
+		liveness: make(map[string]*validatorLiveness),
+	}
+	signer := []byte{0x01}
+	absent := []byte{0x02}
+
+	req := abciTypes.RequestBeginBlock{
+		Validators: []abciTypes.SigningValidator{
+			{Validator: abciTypes.Validator{Address: signer, Power: 10}, SignedLastBlock: true},
+			{Validator: abciTypes.Validator{Address: absent, Power: 10}, SignedLastBlock: false},
+		},
+	}
+	app.recordLiveness(req)
+	app.recordLiveness(req)
+
+	assert.Equal(int64(0), app.MissedBlocks(signer))
+	assert.Equal(int64(2), app.MissedBlocks(absent))
+
+	// the counters are served by the travis_missedBlocks query
+	data, _ := json.Marshal(jsonRequest{Method: "travis_missedBlocks"})
+	res := app.Query(abciTypes.RequestQuery{Data: data})
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+	var counters map[string]validatorLiveness
+	assert.Nil(json.Unmarshal(res.Value, &counters))
+	assert.Equal(int64(2), counters["02"].MissedBlocks)
+
+	// jailing the validator resets its counters
+	app.ResetLiveness(absent)
+	assert.Equal(int64(0), app.MissedBlocks(absent))
+}
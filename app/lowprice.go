@@ -0,0 +1,168 @@
+package app
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+	"github.com/CyberMiles/travis/utils"
+)
+
+// lowPriceTx records a transaction admitted with a gas price below the minimum
+// together with the time it was admitted
+type lowPriceTx struct {
+	tx    *ethTypes.Transaction
+	added time.Time
+}
+
+// checkLowPrice lets the first transaction of each from/to pair pay less than the
+// minimum gas price and rejects the following ones. The admitted tx itself still
+// passes when it's checked again, e.g. rechecked in a later block of the window.
+func (app *EthermintApplication) checkLowPrice(from common.Address,
+	tx *ethTypes.Transaction, now time.Time) abciTypes.ResponseCheckTx {
+
+	// Iterate over all transactions to check if the gas price is too low for the
+	// non-first transaction with the same from/to address
+	// Todo performance maybe
+	var to common.Address
+	if tx.To() != nil {
+		to = *tx.To()
+	}
+	ft := FromTo{
+		from: from,
+		to:   to,
+	}
+	minGasPrice := app.minGasPrice()
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if app.isFreeTx(tx) {
+		// whitelisted system contracts are callable at any gas price
+		return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+	}
+	if app.hasFreeTxQuota(from, tx, now) {
+		// zero gas price txs within the quota of the sender
+		return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+	}
+	if lpt, ok := app.lowPriceTransactions[ft]; ok {
+		if lpt.tx.Hash() == tx.Hash() {
+			return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+		}
+		if tx.GasPrice().Cmp(minGasPrice) < 0 {
+			// add failed count, the nonce check tolerates the skipped nonces
+			// until the next Commit
+			app.checkFailedCount[from] = app.checkFailedCount[from] + 1
+			return abciTypes.ResponseCheckTx{Code: errors.CodeLowGasPriceErr, Log: "The gas price is too low for transaction"}
+		}
+	}
+	if tx.GasPrice().Cmp(minGasPrice) < 0 {
+		app.lowPriceTransactions[ft] = &lowPriceTx{tx: tx, added: now}
+	}
+
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
+
+// pruneLowPriceTransactions evicts the entries older than the configured ttl,
+// so that a stale entry doesn't keep flagging txs until the next Commit
+func (app *EthermintApplication) pruneLowPriceTransactions(now time.Time) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if app.lowPriceTxTTL <= 0 {
+		return
+	}
+	for ft, lpt := range app.lowPriceTransactions {
+		if now.Sub(lpt.added) > app.lowPriceTxTTL {
+			delete(app.lowPriceTransactions, ft)
+		}
+	}
+}
+
+// lowPriceWindow is the cadence of the low price allowance of the from/to pairs.
+// The allowance renews at every Commit unless a number of blocks or an interval
+// is set, then when either has elapsed since the last reset.
+type lowPriceWindow struct {
+	blocks   uint64
+	interval time.Duration
+	// height and time of the last reset
+	lastHeight uint64
+	lastTime   time.Time
+}
+
+// elapsed tells whether the window is over at the given height and time
+func (w *lowPriceWindow) elapsed(height uint64, now time.Time) bool {
+	if w.blocks == 0 && w.interval == 0 {
+		return true
+	}
+	if w.lastTime.IsZero() {
+		// the window starts with the first block seen
+		w.lastHeight, w.lastTime = height, now
+		return false
+	}
+	if w.blocks > 0 && height-w.lastHeight >= w.blocks {
+		return true
+	}
+	return w.interval > 0 && now.Sub(w.lastTime) >= w.interval
+}
+
+// rollLowPriceWindow forgets the low price txs once the block committed at height
+// ends the window of the allowance
+func (app *EthermintApplication) rollLowPriceWindow(height uint64, now time.Time) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if !app.lowPriceWindow.elapsed(height, now) {
+		return
+	}
+	app.lowPriceTransactions = make(map[FromTo]*lowPriceTx)
+	app.lowPriceWindow.lastHeight, app.lowPriceWindow.lastTime = height, now
+}
+
+// resetFailedCounts forgets the nonces skipped by the rejected low price txs once a
+// block is committed. Those txs never made it into a block, the committed nonce of
+// their sender is the one the next txs are checked against.
+func (app *EthermintApplication) resetFailedCounts() {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.checkFailedCount = make(map[common.Address]uint64)
+}
+
+// failedCount returns the number of txs of the sender rejected for their low price
+func (app *EthermintApplication) failedCount(from common.Address) (uint64, bool) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	c, ok := app.checkFailedCount[from]
+	return c, ok
+}
+
+// minGasPrice returns the gas price floor, relaxed when the mempool is quiet
+func (app *EthermintApplication) minGasPrice() *big.Int {
+	app.mu.Lock()
+	percent, threshold := app.gasPriceGracePercent, app.gasPriceGraceThreshold
+	app.mu.Unlock()
+
+	minGasPrice := new(big.Int).SetUint64(utils.GetParams().GasPrice)
+	if percent == 0 || threshold <= 0 {
+		return minGasPrice
+	}
+	return relaxedGasPrice(minGasPrice, app.backend.MempoolSize(), threshold, percent)
+}
+
+// relaxedGasPrice scales the minimum gas price from floorPercent of it with an empty
+// mempool up to the full price once pending reaches threshold
+func relaxedGasPrice(minGasPrice *big.Int, pending, threshold int, floorPercent uint64) *big.Int {
+	if floorPercent == 0 || floorPercent >= 100 || threshold <= 0 || pending >= threshold {
+		return minGasPrice
+	}
+	percent := addSat(floorPercent, mulSat(100-floorPercent, uint64(pending))/uint64(threshold))
+	relaxed := new(big.Int).Mul(minGasPrice, new(big.Int).SetUint64(percent))
+	relaxed.Div(relaxed, big.NewInt(100))
+	return clampBig(relaxed, bigZero, minGasPrice)
+}
@@ -0,0 +1,181 @@
+package app
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func newLowPriceTestApp() *EthermintApplication {
+	return &EthermintApplication{
+		lowPriceTransactions: make(map[FromTo]*lowPriceTx),
+		checkFailedCount:     make(map[common.Address]uint64),
+	}
+}
+
+func TestLowPriceTransactionTTL(t *testing.T) {
+	assert := assert.New(t)
+
+	app := newLowPriceTestApp()
+	app.lowPriceTxTTL = 10 * time.Second
+
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	lowPrice := big.NewInt(1)
+	start := time.Unix(1500000000, 0)
+
+	tx1 := ethTypes.NewTransaction(0, to, big.NewInt(1), 21000, lowPrice, nil)
+	tx2 := ethTypes.NewTransaction(1, to, big.NewInt(1), 21000, lowPrice, nil)
+
+	// the first low price tx of a pair is let through, the second isn't
+	assert.Equal(abciTypes.CodeTypeOK, app.checkLowPrice(from, tx1, start).Code)
+	assert.Equal(errors.CodeLowGasPriceErr, app.checkLowPrice(from, tx2, start.Add(time.Second)).Code)
+
+	// within the ttl the entry is kept
+	app.pruneLowPriceTransactions(start.Add(5 * time.Second))
+	assert.Len(app.lowPriceTransactions, 1)
+
+	// past the ttl it is evicted and no longer triggers the rejection
+	now := start.Add(11 * time.Second)
+	app.pruneLowPriceTransactions(now)
+	assert.Len(app.lowPriceTransactions, 0)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkLowPrice(from, tx2, now).Code)
+}
+
+func TestLowPriceTransactionNoTTL(t *testing.T) {
+	assert := assert.New(t)
+
+	app := newLowPriceTestApp()
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	start := time.Unix(1500000000, 0)
+
+	tx := ethTypes.NewTransaction(0, to, big.NewInt(1), 21000, big.NewInt(1), nil)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkLowPrice(from, tx, start).Code)
+
+	// without a ttl entries live until Commit
+	app.pruneLowPriceTransactions(start.Add(24 * time.Hour))
+	assert.Len(app.lowPriceTransactions, 1)
+}
+
+func TestRelaxedGasPrice(t *testing.T) {
+	assert := assert.New(t)
+
+	minGasPrice := big.NewInt(2000000000)
+
+	// an empty mempool applies the full grace
+	assert.Equal(big.NewInt(1000000000), relaxedGasPrice(minGasPrice, 0, 100, 50))
+	// the floor tightens as the mempool fills up
+	assert.Equal(big.NewInt(1500000000), relaxedGasPrice(minGasPrice, 50, 100, 50))
+	// and is fully enforced past the threshold
+	assert.Equal(minGasPrice, relaxedGasPrice(minGasPrice, 100, 100, 50))
+	assert.Equal(minGasPrice, relaxedGasPrice(minGasPrice, 500, 100, 50))
+	// no grace configured
+	assert.Equal(minGasPrice, relaxedGasPrice(minGasPrice, 0, 100, 0))
+}
+
+// run with -race
+func TestLowPriceConcurrentAccess(t *testing.T) {
+	app := newLowPriceTestApp()
+	app.lowPriceTxTTL = time.Millisecond
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	start := time.Unix(1500000000, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			from := common.BytesToAddress([]byte{byte(i)})
+			for nonce := uint64(0); nonce < 100; nonce++ {
+				now := start.Add(time.Duration(nonce) * time.Millisecond)
+				tx := ethTypes.NewTransaction(nonce, to, big.NewInt(1), 21000, big.NewInt(1), nil)
+				app.pruneLowPriceTransactions(now)
+				app.checkLowPrice(from, tx, now)
+				app.failedCount(from)
+			}
+		}(i)
+	}
+	// commits
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			app.rollLowPriceWindow(uint64(i), start)
+		}
+	}()
+	wg.Wait()
+}
+
+func TestResetFailedCounts(t *testing.T) {
+	assert := assert.New(t)
+
+	app := newLowPriceTestApp()
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	now := time.Now()
+
+	// a second low price tx to the same recipient is rejected, the nonce check
+	// tolerates the nonce it skipped
+	app.checkLowPrice(from, ethTypes.NewTransaction(0, to, big.NewInt(1), 21000, big.NewInt(1), nil), now)
+	app.checkLowPrice(from, ethTypes.NewTransaction(1, to, big.NewInt(1), 21000, big.NewInt(1), nil), now)
+	c, ok := app.failedCount(from)
+	assert.True(ok)
+	assert.Equal(uint64(1), c)
+
+	// the rejected tx isn't committed, no phantom nonce advance is left
+	app.resetFailedCounts()
+	_, ok = app.failedCount(from)
+	assert.False(ok)
+}
+
+func TestLowPriceWindow(t *testing.T) {
+	assert := assert.New(t)
+
+	app := newLowPriceTestApp()
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	start := time.Unix(1500000000, 0)
+	tx := ethTypes.NewTransaction(0, to, big.NewInt(1), 21000, big.NewInt(1), nil)
+
+	// by default the allowance renews at every Commit
+	app.checkLowPrice(from, tx, start)
+	app.rollLowPriceWindow(1, start)
+	assert.Len(app.lowPriceTransactions, 0)
+
+	// every 3 blocks
+	assert.Nil(app.setOption("low_price_reset_blocks", "3"))
+	app.rollLowPriceWindow(1, start)
+	app.checkLowPrice(from, tx, start)
+	for height := uint64(2); height < 4; height++ {
+		app.rollLowPriceWindow(height, start)
+		assert.Len(app.lowPriceTransactions, 1, "height %d", height)
+		// the admitted tx passes its recheck, another low price tx doesn't
+		assert.Equal(abciTypes.CodeTypeOK, app.checkLowPrice(from, tx, start).Code)
+		next := ethTypes.NewTransaction(height, to, big.NewInt(1), 21000, big.NewInt(1), nil)
+		assert.Equal(errors.CodeLowGasPriceErr, app.checkLowPrice(from, next, start).Code)
+	}
+	app.rollLowPriceWindow(4, start)
+	assert.Len(app.lowPriceTransactions, 0)
+	assert.Equal(uint64(4), app.lowPriceWindow.lastHeight)
+
+	// or every 60 seconds, whichever comes first
+	assert.Nil(app.setOption("low_price_reset_interval", "60"))
+	app.checkLowPrice(from, tx, start)
+	app.rollLowPriceWindow(5, start.Add(59*time.Second))
+	assert.Len(app.lowPriceTransactions, 1)
+	app.rollLowPriceWindow(6, start.Add(60*time.Second))
+	assert.Len(app.lowPriceTransactions, 0)
+	assert.Equal(start.Add(60*time.Second), app.lowPriceWindow.lastTime)
+
+	assert.NotNil(app.setOption("low_price_reset_blocks", "-1"))
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"github.com/go-kit/kit/metrics"
+	prometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+
+	"github.com/CyberMiles/travis/utils"
+)
+
+// Metrics are the gauges of the CheckTx bookkeeping, sampled at the end of every
+// Commit. The maps are reset on Commit, a size growing from block to block
+// tells a leak.
+type Metrics struct {
+	// senders with failed CheckTx counted for the nonce check
+	CheckFailedCount metrics.Gauge
+	// from/to pairs granted their low price tx
+	LowPriceTransactions metrics.Gauge
+	// txs whose nonce was checked already
+	NonceCheckedTxs metrics.Gauge
+}
+
+// PrometheusMetrics returns the Metrics registered with the default prometheus
+// registry, served along with the tendermint metrics
+func PrometheusMetrics() *Metrics {
+	return &Metrics{
+		CheckFailedCount: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Subsystem: "travis",
+			Name:      "check_failed_count_size",
+			Help:      "Number of senders with failed CheckTx counted for the nonce check.",
+		}, []string{}),
+		LowPriceTransactions: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Subsystem: "travis",
+			Name:      "low_price_transactions_size",
+			Help:      "Number of from/to pairs granted their low price transaction.",
+		}, []string{}),
+		NonceCheckedTxs: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Subsystem: "travis",
+			Name:      "nonce_checked_txs_size",
+			Help:      "Number of transactions whose nonce was checked already.",
+		}, []string{}),
+	}
+}
+
+// SetMetrics sets the gauges updated on Commit; nil disables them
+// #unstable
+func (app *EthermintApplication) SetMetrics(m *Metrics) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.metrics = m
+}
+
+// reportMapSizes sets the gauges to the current size of the maps
+func (app *EthermintApplication) reportMapSizes() {
+	app.mu.Lock()
+	m := app.metrics
+	checkFailed := len(app.checkFailedCount)
+	lowPrice := len(app.lowPriceTransactions)
+	app.mu.Unlock()
+
+	if m == nil {
+		return
+	}
+	m.CheckFailedCount.Set(float64(checkFailed))
+	m.LowPriceTransactions.Set(float64(lowPrice))
+	m.NonceCheckedTxs.Set(float64(utils.NonceCheckedTx.Len()))
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-kit/kit/metrics"
+
+	"github.com/CyberMiles/travis/utils"
+)
+
+// testGauge records the last value set
+type testGauge struct {
+	value float64
+}
+
+func (g *testGauge) With(labelValues ...string) metrics.Gauge { return g }
+func (g *testGauge) Set(value float64)                        { g.value = value }
+func (g *testGauge) Add(delta float64)                        { g.value += delta }
+
+func TestMapSizeGauges(t *testing.T) {
+	assert := assert.New(t)
+
+	checkFailed, lowPrice, nonceChecked := &testGauge{}, &testGauge{}, &testGauge{}
+	app := newLowPriceTestApp()
+	app.SetMetrics(&Metrics{
+		CheckFailedCount:     checkFailed,
+		LowPriceTransactions: lowPrice,
+		NonceCheckedTxs:      nonceChecked,
+	})
+
+	// two pairs get their low price tx, the second tx of one fails
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	now := time.Unix(1500000000, 0)
+	for i, to := range []common.Address{
+		common.HexToAddress("0x2000000000000000000000000000000000000002"),
+		common.HexToAddress("0x3000000000000000000000000000000000000003"),
+		common.HexToAddress("0x2000000000000000000000000000000000000002"),
+	} {
+		tx := ethTypes.NewTransaction(uint64(i), to, big.NewInt(1), 21000, big.NewInt(1), nil)
+		app.checkLowPrice(from, tx, now)
+	}
+	utils.NonceCheckedTx.Add(common.HexToHash("0x01"))
+
+	app.reportMapSizes()
+	assert.Equal(float64(1), checkFailed.value)
+	assert.Equal(float64(2), lowPrice.value)
+	assert.Equal(float64(utils.NonceCheckedTx.Len()), nonceChecked.value)
+	assert.True(nonceChecked.value >= 1)
+
+	// the resets of Commit show up
+	app.resetFailedCounts()
+	app.rollLowPriceWindow(1, now)
+	app.reportMapSizes()
+	assert.Equal(float64(0), checkFailed.value)
+	assert.Equal(float64(0), lowPrice.value)
+
+	// disabled
+	app.SetMetrics(nil)
+	app.checkLowPrice(from, ethTypes.NewTransaction(3, from, big.NewInt(1), 21000, big.NewInt(1), nil), now)
+	app.reportMapSizes()
+	assert.Equal(float64(0), lowPrice.value)
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// nextNonce returns the nonce the next tx of the account should use: its committed
+// nonce, past the txs of the account admitted by CheckTx since the last Commit.
+// The txs held for a nonce gap don't count, the gap has to be filled first.
+func (app *EthermintApplication) nextNonce(addr common.Address) uint64 {
+	app.checkTxStateMtx.Lock()
+	defer app.checkTxStateMtx.Unlock()
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	var nonce uint64
+	if app.pool != nil && app.pool.base != nil {
+		nonce = app.pool.base.GetNonce(addr)
+	}
+	if app.checkTxState != nil {
+		if n := app.checkTxState.GetNonce(addr); n > nonce {
+			nonce = n
+		}
+	}
+	if app.pool != nil {
+		for _, ptx := range app.pool.bySender[addr] {
+			if n := ptx.tx.Nonce() + 1; n > nonce {
+				nonce = n
+			}
+		}
+	}
+	return nonce
+}
+
+// nextNonceQuery serves travis_nextNonce
+func (app *EthermintApplication) nextNonceQuery(params []interface{}) (hexutil.Uint64, error) {
+	if len(params) != 1 {
+		return 0, fmt.Errorf("expected 1 param, got %d", len(params))
+	}
+	hex, ok := params[0].(string)
+	if !ok || !common.IsHexAddress(hex) {
+		return 0, fmt.Errorf("invalid address: %v", params[0])
+	}
+	return hexutil.Uint64(app.nextNonce(common.HexToAddress(hex))), nil
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestNextNonce(t *testing.T) {
+	assert := assert.New(t)
+
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	committed := newTestState()
+	committed.AddBalance(from, big.NewInt(1000000))
+	committed.SetNonce(from, 3)
+
+	app := &EthermintApplication{
+		checkTxState: committed.Copy(),
+		pool:         newTxPool(committed.Copy()),
+	}
+	query := func() uint64 {
+		result, handled, err := app.localQuery(jsonRequest{
+			Method: "travis_nextNonce",
+			Params: []interface{}{from.Hex()},
+		})
+		assert.True(handled)
+		assert.Nil(err)
+		return uint64(result.(hexutil.Uint64))
+	}
+
+	// nothing pending, the committed nonce
+	assert.Equal(uint64(3), query())
+
+	// one tx admitted by CheckTx
+	tx := pricedTx(3, 1)
+	assert.Equal(abciTypes.CodeTypeOK, applySpeculativeTx(app.checkTxState, from, 3, tx, CheckTxNew).Code)
+	app.recordPending(from, tx)
+	assert.Equal(uint64(4), query())
+	assert.Equal(uint64(3), committed.GetNonce(from))
+
+	// a pending tx ahead of the CheckTx state still counts
+	app.recordPending(from, pricedTx(4, 1))
+	assert.Equal(uint64(5), query())
+
+	// the next Commit resets the tracking to the committed nonce
+	committed.SetNonce(from, 5)
+	app.checkTxState = committed.Copy()
+	app.resetPending(committed)
+	assert.Equal(uint64(5), query())
+
+	_, _, err := app.localQuery(jsonRequest{
+		Method: "travis_nextNonce",
+		Params: []interface{}{"0x12"},
+	})
+	assert.NotNil(err)
+}
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+
+	"github.com/CyberMiles/travis/utils"
+)
+
+// pruneNonceCheckedTxs drops the nonce-checked marks which can't be trusted once a
+// block is committed: the committed txs, the txs evicted from the mempool, and the
+// pending txs whose nonce is behind the committed nonce of their sender, e.g.
+// replayed after a reorg. Those get their nonce checked again if resubmitted.
+func pruneNonceCheckedTxs(checked *utils.NonceCheckedTxs, committed []common.Hash,
+	pending []*pendingTx, committedState *state.StateDB) {
+
+	for _, hash := range committed {
+		checked.Remove(hash)
+	}
+	for _, ptx := range pending {
+		if ptx.index < 0 || ptx.tx.Nonce() < committedState.GetNonce(ptx.from) {
+			checked.Remove(ptx.tx.Hash())
+		}
+	}
+}
+
+// resetNonceCheckedTxs prunes utils.NonceCheckedTx on Commit
+func (app *EthermintApplication) resetNonceCheckedTxs(committedState *state.StateDB) {
+	var committed []common.Hash
+	for _, tx := range app.backend.Ethereum().BlockChain().CurrentBlock().Transactions() {
+		committed = append(committed, tx.Hash())
+	}
+
+	app.mu.Lock()
+	pending := app.pool.txs
+	app.mu.Unlock()
+
+	pruneNonceCheckedTxs(utils.NonceCheckedTx, committed, pending, committedState)
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"container/heap"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/CyberMiles/travis/utils"
+)
+
+func TestPruneNonceCheckedTxs(t *testing.T) {
+	assert := assert.New(t)
+
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	committedTx, replayedTx, pendingTx2 := pricedTx(0, 1), pricedTx(1, 1), pricedTx(2, 1)
+
+	checked := utils.NewNonceCheckedTxs(16)
+	checked.Add(committedTx.Hash())
+	checked.Add(replayedTx.Hash())
+	checked.Add(pendingTx2.Hash())
+	pool := newTxPool(nil)
+	pool.add(from, replayedTx)
+	pool.add(from, pendingTx2)
+
+	// the committed chain moved the sender to nonce 2, e.g. after a reorg
+	// included another tx with nonce 1
+	st := newTestState()
+	st.SetNonce(from, 2)
+
+	pruneNonceCheckedTxs(checked, []common.Hash{committedTx.Hash()}, pool.txs, st)
+
+	// the replayed tx must have its nonce checked again
+	assert.False(checked.Contains(replayedTx.Hash()))
+	assert.False(checked.Contains(committedTx.Hash()))
+	// still valid pending txs keep their mark
+	assert.True(checked.Contains(pendingTx2.Hash()))
+}
+
+func TestNonceCheckedCacheSizeOption(t *testing.T) {
+	assert := assert.New(t)
+
+	defer utils.NonceCheckedTx.Resize(utils.DefaultNonceCheckedTxsSize)
+	utils.NonceCheckedTx.Reset()
+	for i := 0; i < 10; i++ {
+		utils.NonceCheckedTx.Add(common.BigToHash(big.NewInt(int64(i))))
+	}
+
+	app := &EthermintApplication{}
+	assert.Nil(app.setOption("nonce_checked_cache_size", "4"))
+	assert.Equal(4, utils.NonceCheckedTx.Len())
+	assert.True(utils.NonceCheckedTx.Contains(common.BigToHash(big.NewInt(9))))
+	assert.False(utils.NonceCheckedTx.Contains(common.BigToHash(big.NewInt(5))))
+	assert.NotNil(app.setOption("nonce_checked_cache_size", "-1"))
+}
+
+func TestPruneNonceCheckedTxsEvicted(t *testing.T) {
+	assert := assert.New(t)
+
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	cheapTx, pendingTx := pricedTx(0, 1), pricedTx(1, 2)
+
+	checked := utils.NewNonceCheckedTxs(16)
+	checked.Add(cheapTx.Hash())
+	checked.Add(pendingTx.Hash())
+	pool := newTxPool(nil)
+	pool.add(from, cheapTx)
+	pool.add(from, pendingTx)
+	// the cheapest tx is evicted and never committed
+	heap.Pop(&pool.priced)
+
+	pruneNonceCheckedTxs(checked, nil, pool.txs, newTestState())
+
+	// the evicted tx doesn't keep skipping the nonce check
+	assert.False(checked.Contains(cheapTx.Hash()))
+	assert.True(checked.Contains(pendingTx.Hash()))
+}
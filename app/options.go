@@ -0,0 +1,318 @@
+package app
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/CyberMiles/travis/utils"
+)
+
+// setOption applies a single SetOption key/value pair to the application
+func (app *EthermintApplication) setOption(key, value string) error {
+	switch key {
+	case "eip3607_block":
+		block, err := parseBlockNumber(value)
+		if err != nil {
+			return err
+		}
+		app.mu.Lock()
+		app.eip3607Block = block
+		app.mu.Unlock()
+	case "dust_threshold":
+		threshold, err := parseBigInt(value)
+		if err != nil {
+			return err
+		}
+		app.mu.Lock()
+		app.dustThreshold = threshold
+		app.mu.Unlock()
+	case "self_tx_policy":
+		policy, err := parseSelfTxPolicy(value)
+		if err != nil {
+			return err
+		}
+		app.mu.Lock()
+		app.selfTxPolicy = policy
+		app.mu.Unlock()
+	case "low_price_tx_ttl":
+		ttl, err := parseSeconds(value)
+		if err != nil {
+			return err
+		}
+		app.mu.Lock()
+		app.lowPriceTxTTL = ttl
+		app.mu.Unlock()
+	case "low_price_reset_blocks":
+		blocks, err := parseUint(value)
+		if err != nil {
+			return err
+		}
+		app.mu.Lock()
+		app.lowPriceWindow.blocks = blocks
+		app.mu.Unlock()
+	case "low_price_reset_interval":
+		interval, err := parseSeconds(value)
+		if err != nil {
+			return err
+		}
+		app.mu.Lock()
+		app.lowPriceWindow.interval = interval
+		app.mu.Unlock()
+	case "gas_price_grace_percent":
+		percent, err := parseUint(value)
+		if err != nil {
+			return err
+		}
+		if percent > 100 {
+			return fmt.Errorf("invalid percentage: %s", value)
+		}
+		app.mu.Lock()
+		app.gasPriceGracePercent = percent
+		app.mu.Unlock()
+	case "gas_price_grace_threshold":
+		threshold, err := parseInt(value)
+		if err != nil {
+			return err
+		}
+		app.mu.Lock()
+		app.gasPriceGraceThreshold = threshold
+		app.mu.Unlock()
+	case "mempool_cap":
+		capacity, err := parseInt(value)
+		if err != nil {
+			return err
+		}
+		app.mu.Lock()
+		app.mempoolCap = capacity
+		app.mu.Unlock()
+	case "max_tx_gas":
+		gas, err := parseUint(value)
+		if err != nil {
+			return err
+		}
+		app.mu.Lock()
+		app.maxTxGas = gas
+		app.mu.Unlock()
+	case "max_gas_intrinsic_ratio":
+		ratio, err := parseUint(value)
+		if err != nil {
+			return err
+		}
+		app.mu.Lock()
+		app.maxGasIntrinsicRatio = ratio
+		app.mu.Unlock()
+	case "intrinsic_gas_margin":
+		margin, err := parseUint(value)
+		if err != nil {
+			return err
+		}
+		app.mu.Lock()
+		app.intrinsicGasMargin = margin
+		app.mu.Unlock()
+	case "max_pending_per_sender":
+		limit, err := parseInt(value)
+		if err != nil {
+			return err
+		}
+		app.mu.Lock()
+		app.maxPendingPerSender = limit
+		app.mu.Unlock()
+	case "tx_log_sample":
+		sample, err := parseUint(value)
+		if err != nil {
+			return err
+		}
+		app.setTxLogSample(sample)
+	case "admin_queries":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean: %s", value)
+		}
+		app.mu.Lock()
+		app.adminQueries = enabled
+		app.mu.Unlock()
+	case "max_query_response_size":
+		size, err := parseInt(value)
+		if err != nil {
+			return err
+		}
+		app.mu.Lock()
+		app.maxQueryResponseSize = size
+		app.mu.Unlock()
+	case "paused":
+		paused, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean: %s", value)
+		}
+		app.SetPaused(paused)
+	case "sender_cache_size":
+		size, err := parseInt(value)
+		if err != nil {
+			return err
+		}
+		app.signerMtx.Lock()
+		if app.senders == nil {
+			app.senders = newSenderCache(size)
+		} else {
+			app.senders.resize(size)
+		}
+		app.signerMtx.Unlock()
+	case "max_accounts":
+		limit, err := parseUint(value)
+		if err != nil {
+			return err
+		}
+		return app.setMaxAccounts(limit)
+	case "max_failed_checktx":
+		limit, err := parseUint(value)
+		if err != nil {
+			return err
+		}
+		app.mu.Lock()
+		app.maxFailedCheckTx = limit
+		app.mu.Unlock()
+	case "rpc_allow_method":
+		methods, err := parseMethodList(value)
+		if err != nil {
+			return err
+		}
+		app.setRPCAllowMethods(methods)
+	case "rpc_deny_method":
+		methods, err := parseMethodList(value)
+		if err != nil {
+			return err
+		}
+		app.setRPCDenyMethods(methods)
+	case "free_tx_quota":
+		limit, err := parseUint(value)
+		if err != nil {
+			return err
+		}
+		app.mu.Lock()
+		app.freeTxQuota.limit = limit
+		app.mu.Unlock()
+	case "free_tx_quota_interval":
+		interval, err := parseSeconds(value)
+		if err != nil {
+			return err
+		}
+		app.mu.Lock()
+		app.freeTxQuota.interval = interval
+		app.mu.Unlock()
+	case "gas_oracle_blocks":
+		blocks, err := parseInt(value)
+		if err != nil {
+			return err
+		}
+		app.mu.Lock()
+		app.gasOracle.blocks = blocks
+		app.gasOracle.trim()
+		app.mu.Unlock()
+	case "gas_oracle_percentile":
+		percentile, err := parseUint(value)
+		if err != nil {
+			return err
+		}
+		if percentile > 100 {
+			return fmt.Errorf("invalid percentage: %s", value)
+		}
+		app.mu.Lock()
+		app.gasOracle.percentile = percentile
+		app.mu.Unlock()
+	case "nonce_checked_cache_size":
+		size, err := parseInt(value)
+		if err != nil {
+			return err
+		}
+		utils.NonceCheckedTx.Resize(size)
+	case "fault_commit":
+		return app.faults.setProbability(faultCommit, value)
+	case "fault_deliver_tx":
+		return app.faults.setProbability(faultDeliverTx, value)
+	case "fault_reset_state":
+		return app.faults.setProbability(faultResetState, value)
+	case "fault_seed":
+		return app.faults.seed(value)
+	case "trace_check":
+		filter, err := parseTxHashes(value)
+		if err != nil {
+			return err
+		}
+		app.checkTraces.setFilter(filter)
+	case "sender_priority":
+		priorities, err := parseSenderPriorities(value)
+		if err != nil {
+			return err
+		}
+		app.setSenderPriorities(priorities)
+	case "recipient_gas_price_floor":
+		floors, err := parseGasPriceFloors(value)
+		if err != nil {
+			return err
+		}
+		app.setRecipientGasPriceFloors(floors)
+	case "free_tx_to":
+		addrs, err := parseAddressList(value)
+		if err != nil {
+			return err
+		}
+		app.setFreeTxTo(addrs)
+	default:
+		return fmt.Errorf("unknown option: %s", key)
+	}
+	return nil
+}
+
+// parseBlockNumber parses a fork activation height; an empty value disables the fork
+func parseBlockNumber(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	block, ok := new(big.Int).SetString(value, 10)
+	if !ok || block.Sign() < 0 {
+		return nil, fmt.Errorf("invalid block number: %s", value)
+	}
+	return block, nil
+}
+
+// parseSeconds parses a non-negative number of seconds into a duration
+func parseSeconds(value string) (time.Duration, error) {
+	secs, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number of seconds: %s", value)
+	}
+	return time.Duration(secs) * time.Second, nil
+}
+
+// parseUint parses a non-negative integer option
+func parseUint(value string) (uint64, error) {
+	v, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid unsigned integer: %s", value)
+	}
+	return v, nil
+}
+
+// parseInt parses a non-negative integer option sized by an int, which is bounded
+// by math.MaxInt32 so the value keeps its sign on 32 bit platforms
+func parseInt(value string) (int, error) {
+	v, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid unsigned integer: %s", value)
+	}
+	return int(v), nil
+}
+
+// parseBigInt parses a non-negative amount; an empty value unsets it
+func parseBigInt(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	v, ok := new(big.Int).SetString(value, 10)
+	if !ok || v.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount: %s", value)
+	}
+	return v, nil
+}
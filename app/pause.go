@@ -0,0 +1,37 @@
+package app
+
+import (
+	"sync/atomic"
+
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// SetPaused toggles the maintenance mode, in which CheckTx rejects every tx while
+// the committed blocks are still delivered, so that the mempool drains before an
+// upgrade
+// #unstable
+func (app *EthermintApplication) SetPaused(paused bool) {
+	var flag uint32
+	if paused {
+		flag = 1
+	}
+	atomic.StoreUint32(&app.paused, flag)
+}
+
+// Paused tells whether the application is in maintenance mode
+// #unstable
+func (app *EthermintApplication) Paused() bool {
+	return atomic.LoadUint32(&app.paused) == 1
+}
+
+// checkPaused rejects any tx in maintenance mode
+func (app *EthermintApplication) checkPaused() abciTypes.ResponseCheckTx {
+	if app.Paused() {
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeServiceUnavailable,
+			Log:  "Node in maintenance mode, not accepting transactions"}
+	}
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
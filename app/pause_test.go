@@ -0,0 +1,34 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestPausedRejectsCheckTx(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{logger: tmLog.NewNopLogger()}
+	assert.False(app.Paused())
+
+	assert.Nil(app.setOption("paused", "true"))
+	assert.True(app.Paused())
+	resp := app.CheckTx(pricedTx(0, 1), CheckTxNew)
+	assert.Equal(errors.CodeTypeServiceUnavailable, resp.Code)
+
+	// the delivery bookkeeping isn't affected by the pause
+	app.countDeliveredTx(21000)
+	assert.Equal(uint64(1), app.blockCounters().TxCount)
+
+	assert.Nil(app.setOption("paused", "false"))
+	assert.False(app.Paused())
+	assert.Equal(abciTypes.CodeTypeOK, app.checkPaused().Code)
+
+	assert.NotNil(app.setOption("paused", "maybe"))
+}
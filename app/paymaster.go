@@ -0,0 +1,222 @@
+package app
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	lru "github.com/hashicorp/golang-lru"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+	"github.com/CyberMiles/travis/utils"
+)
+
+const (
+	// paymasterCallGas is the gas of the sponsorship call
+	paymasterCallGas uint64 = 100000
+	// sponsorshipsKept is the number of sponsorship decisions cached
+	sponsorshipsKept = 4096
+	// paymasterCallsPerBlock bounds the sponsorship calls of CheckTx between two
+	// commits, past it the senders pay their gas until the next block
+	paymasterCallsPerBlock = 256
+)
+
+// sponsorSelector is the selector of sponsor(address sender, bytes32 txHash), which
+// returns true when the paymaster pays the gas of the tx
+var sponsorSelector = crypto.Keccak256([]byte("sponsor(address,bytes32)"))[:4]
+
+// sponsorCall asks a paymaster whether it sponsors a tx of from, against a state
+type sponsorCall func(st *state.StateDB, paymaster, from common.Address, tx *ethTypes.Transaction) (bool, error)
+
+// paymasterSponsors runs the sponsorship call of the paymaster as a static call on st,
+// whose changes, e.g. the touch of the paymaster, are reverted afterwards. A failed
+// call, e.g. a revert, declines the tx along with the error.
+func paymasterSponsors(st *state.StateDB, header *ethTypes.Header, chain core.ChainContext,
+	config *params.ChainConfig, paymaster, from common.Address, tx *ethTypes.Transaction) (bool, error) {
+
+	hash := tx.Hash()
+	input := make([]byte, 0, len(sponsorSelector)+2*common.HashLength)
+	input = append(input, sponsorSelector...)
+	input = append(input, common.LeftPadBytes(from[:], common.HashLength)...)
+	input = append(input, hash[:]...)
+
+	snapshot := st.Snapshot()
+	defer st.RevertToSnapshot(snapshot)
+
+	msg := ethTypes.NewMessage(from, &paymaster, 0, new(big.Int), paymasterCallGas, new(big.Int), input, false)
+	context := core.NewEVMContext(msg, header, chain, &header.Coinbase)
+	evm := vm.NewEVM(context, st, config, vm.Config{})
+	ret, _, err := evm.StaticCall(vm.AccountRef(from), paymaster, input, paymasterCallGas)
+	if err != nil {
+		return false, err
+	}
+	return len(ret) == common.HashLength && common.BytesToHash(ret) == common.BigToHash(big.NewInt(1)), nil
+}
+
+// callPaymaster runs the sponsorship call against the head block of the backend
+func (app *EthermintApplication) callPaymaster(st *state.StateDB, paymaster, from common.Address,
+	tx *ethTypes.Transaction) (bool, error) {
+
+	blockchain := app.backend.Ethereum().BlockChain()
+	return paymasterSponsors(st, blockchain.CurrentBlock().Header(), blockchain, blockchain.Config(),
+		paymaster, from, tx)
+}
+
+// parsePaymaster parses the address of the paymaster; an empty value disables it
+func parsePaymaster(value string) (common.Address, error) {
+	if value == "" {
+		return common.Address{}, nil
+	}
+	if !common.IsHexAddress(value) {
+		return common.Address{}, fmt.Errorf("invalid address: %s", value)
+	}
+	return common.HexToAddress(value), nil
+}
+
+// setPaymaster sets the paymaster consulted by CheckTx and DeliverTx, the zero address
+// disables it. The decisions of the previous paymaster are forgotten.
+func (app *EthermintApplication) setPaymaster(paymaster common.Address) {
+	sponsorships, _ := lru.New(sponsorshipsKept)
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.paymaster = paymaster
+	app.sponsorships = sponsorships
+	if app.sponsor == nil {
+		app.sponsor = app.callPaymaster
+	}
+}
+
+// syncPaymaster follows the paymaster chain param, set at genesis or by a change param
+// proposal, so that every validator charges the same account in DeliverTx. It runs
+// in BeginBlock and Commit, along with the proposals changing the param.
+func (app *EthermintApplication) syncPaymaster() {
+	paymaster, err := parsePaymaster(utils.GetParams().Paymaster)
+	if err != nil {
+		// nolint: errcheck
+		app.logger.Error("Invalid paymaster param, disabling the paymaster", "err", err)
+	}
+
+	app.mu.Lock()
+	current, set := app.paymaster, app.sponsorships != nil
+	app.mu.Unlock()
+	if set && current == paymaster {
+		// keep the decisions of the paymaster
+		return
+	}
+	app.setPaymaster(paymaster)
+}
+
+// gasPayer returns the account paying the gas of a tx of from: the paymaster when
+// it sponsors the tx, from otherwise. The decisions are cached by tx hash, a recheck
+// doesn't call the paymaster again. Once paymasterCallsPerBlock calls are made in a
+// block, the other txs are left to their sender without asking, until the next block.
+func (app *EthermintApplication) gasPayer(st *state.StateDB, from common.Address, tx *ethTypes.Transaction) common.Address {
+	app.mu.Lock()
+	paymaster, sponsorships, sponsor := app.paymaster, app.sponsorships, app.sponsor
+	app.mu.Unlock()
+
+	if paymaster == (common.Address{}) || paymaster == from {
+		return from
+	}
+	if sponsored, ok := sponsorships.Get(tx.Hash()); ok {
+		if sponsored.(bool) {
+			return paymaster
+		}
+		return from
+	}
+
+	app.mu.Lock()
+	exhausted := app.paymasterCalls >= paymasterCallsPerBlock
+	if !exhausted {
+		app.paymasterCalls++
+	}
+	app.mu.Unlock()
+	if exhausted {
+		return from
+	}
+
+	sponsored, err := sponsor(st, paymaster, from, tx)
+	if err != nil {
+		app.logger.Info("Paymaster call failed", "hash", tx.Hash().Hex(), "err", err) // nolint: errcheck
+	}
+	sponsorships.Add(tx.Hash(), sponsored)
+	if sponsored {
+		return paymaster
+	}
+	return from
+}
+
+// resetPaymasterCalls starts the budget of sponsorship calls of the next block
+func (app *EthermintApplication) resetPaymasterCalls() {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.paymasterCalls = 0
+}
+
+// deliveryGasPayer returns the paymaster when it sponsors a delivered tx and can pay
+// its gas, the zero address when the sender pays. The paymaster is asked again
+// against the DeliverTx state rather than trusting the decision of CheckTx, so that
+// every validator charges the same account whether it checked the tx or not.
+func (app *EthermintApplication) deliveryGasPayer(tx *ethTypes.Transaction) common.Address {
+	app.mu.Lock()
+	paymaster, sponsor := app.paymaster, app.sponsor
+	app.mu.Unlock()
+
+	if paymaster == (common.Address{}) {
+		return common.Address{}
+	}
+	from, err := app.sender(tx)
+	if err != nil || from == paymaster {
+		return common.Address{}
+	}
+
+	st := app.DeliverTxState()
+	sponsored, err := sponsor(st, paymaster, from, tx)
+	if err != nil {
+		app.logger.Info("Paymaster call failed", "hash", tx.Hash().Hex(), "err", err) // nolint: errcheck
+	}
+	if !sponsored || st.GetBalance(paymaster).Cmp(gasCost(tx)) < 0 {
+		return common.Address{}
+	}
+	return paymaster
+}
+
+// gasCost returns gasprice * gaslimit
+func gasCost(tx *ethTypes.Transaction) *big.Int {
+	return new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas()))
+}
+
+// checkSponsoredBalance checks the balance of the payer covers the gas of the tx and
+// the balance of the sender its value. Unsponsored, both are the sender.
+func checkSponsoredBalance(currentState *state.StateDB, from, payer common.Address,
+	tx *ethTypes.Transaction) abciTypes.ResponseCheckTx {
+
+	if payer == from {
+		return checkBalance(currentState, from, tx)
+	}
+	if balance := currentState.GetBalance(from); balance.Cmp(tx.Value()) < 0 {
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeBaseInvalidInput,
+			Log: fmt.Sprintf(
+				"Current balance: %s, tx value: %s",
+				balance, tx.Value())}
+	}
+	if balance := currentState.GetBalance(payer); balance.Cmp(gasCost(tx)) < 0 {
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeBaseInvalidInput,
+			Log: fmt.Sprintf(
+				"Payer balance: %s, gas cost: %s",
+				balance, gasCost(tx))}
+	}
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
@@ -0,0 +1,203 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+
+	"github.com/CyberMiles/travis/errors"
+	"github.com/CyberMiles/travis/utils"
+)
+
+// stubPaymasterCode sponsors the txs of a single sender:
+// MSTORE(0, EQ(CALLDATALOAD(4), sponsored)) RETURN(0, 32)
+func stubPaymasterCode(sponsored common.Address) []byte {
+	code := []byte{0x60, 0x04, 0x35, 0x73}
+	code = append(code, sponsored[:]...)
+	return append(code, 0x14, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3)
+}
+
+func TestPaymasterSponsors(t *testing.T) {
+	assert := assert.New(t)
+
+	paymaster := common.HexToAddress("0x5000000000000000000000000000000000000005")
+	sponsored := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	declined := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	st := newTestState()
+	st.SetCode(paymaster, stubPaymasterCode(sponsored))
+
+	header := &ethTypes.Header{
+		Number:     big.NewInt(1),
+		Time:       big.NewInt(0),
+		Difficulty: big.NewInt(0),
+		GasLimit:   8000000,
+	}
+	call := func(paymaster, from common.Address) bool {
+		ok, err := paymasterSponsors(st, header, testChain{}, params.TestChainConfig, paymaster, from, pricedTx(0, 1))
+		assert.Nil(err)
+		return ok
+	}
+	assert.True(call(paymaster, sponsored))
+	assert.False(call(paymaster, declined))
+	// an account without code sponsors nothing
+	noCode := common.HexToAddress("0x6000000000000000000000000000000000000006")
+	assert.False(call(noCode, sponsored))
+	// and the call leaves no trace in the state
+	assert.False(st.Exist(noCode))
+}
+
+func TestPaymasterGasPayer(t *testing.T) {
+	assert := assert.New(t)
+
+	paymaster := common.HexToAddress("0x5000000000000000000000000000000000000005")
+	sponsored := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	declined := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	// the txs of the two senders, told apart by their hash
+	tx, declinedTx := pricedTx(0, 1), pricedTx(0, 2)
+
+	st := newTestState()
+	st.SetCode(paymaster, stubPaymasterCode(sponsored))
+	st.AddBalance(paymaster, gasCost(tx))
+	// the senders only hold the value of the tx
+	st.AddBalance(sponsored, tx.Value())
+	st.AddBalance(declined, declinedTx.Value())
+
+	app := &EthermintApplication{logger: tmLog.NewNopLogger()}
+	assert.Equal(sponsored, app.gasPayer(st, sponsored, tx))
+
+	app.setPaymaster(paymaster)
+	header := &ethTypes.Header{Number: big.NewInt(1), Time: big.NewInt(0), Difficulty: big.NewInt(0)}
+	calls := 0
+	app.sponsor = func(st *state.StateDB, paymaster, from common.Address, tx *ethTypes.Transaction) (bool, error) {
+		calls++
+		return paymasterSponsors(st, header, testChain{}, params.TestChainConfig, paymaster, from, tx)
+	}
+
+	// the paymaster pays the gas of the sponsored sender
+	assert.Equal(paymaster, app.gasPayer(st, sponsored, tx))
+	assert.Equal(abciTypes.CodeTypeOK, checkSponsoredBalance(st, sponsored, paymaster, tx).Code)
+	// and not of the other one, which can't afford it
+	assert.Equal(declined, app.gasPayer(st, declined, declinedTx))
+	assert.Equal(errors.CodeTypeBaseInvalidInput, checkSponsoredBalance(st, declined, declined, declinedTx).Code)
+	assert.Equal(2, calls)
+
+	// the decisions are cached by tx hash
+	assert.Equal(paymaster, app.gasPayer(st, sponsored, tx))
+	assert.Equal(declined, app.gasPayer(st, declined, declinedTx))
+	assert.Equal(2, calls)
+
+	// the debit of the sponsored tx splits between the paymaster and the sender
+	assert.Equal(abciTypes.CodeTypeOK, applySponsoredTx(st, sponsored, paymaster, 0, tx, CheckTxNew).Code)
+	assert.Equal(0, st.GetBalance(paymaster).Sign())
+	assert.Equal(0, st.GetBalance(sponsored).Sign())
+	assert.Equal(uint64(1), st.GetNonce(sponsored))
+	// the paymaster is out of funds for the next one
+	next := pricedTx(1, 1)
+	assert.Equal(errors.CodeTypeBaseInvalidInput, checkSponsoredBalance(st, sponsored, app.gasPayer(st, sponsored, next), next).Code)
+
+	// disabled, the decisions are forgotten
+	app.setPaymaster(common.Address{})
+	assert.Equal(sponsored, app.gasPayer(st, sponsored, tx))
+}
+
+func TestPaymasterParam(t *testing.T) {
+	assert := assert.New(t)
+
+	paymaster := common.HexToAddress("0x5000000000000000000000000000000000000005")
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	app := &EthermintApplication{logger: tmLog.NewNopLogger()}
+	assert.False(utils.CheckParamType("paymaster", "0x12"))
+	assert.True(utils.CheckParamType("paymaster", ""))
+	assert.NotNil(app.setOption("paymaster", paymaster.Hex()))
+
+	// the paymaster follows the chain param
+	assert.True(utils.SetParam("paymaster", paymaster.Hex()))
+	defer utils.SetParam("paymaster", "")
+	app.syncPaymaster()
+	calls := 0
+	app.sponsor = func(st *state.StateDB, paymaster, from common.Address, tx *ethTypes.Transaction) (bool, error) {
+		calls++
+		return true, nil
+	}
+	st := newTestState()
+	assert.Equal(paymaster, app.gasPayer(st, from, pricedTx(0, 1)))
+
+	// an unchanged param keeps the decisions
+	app.syncPaymaster()
+	assert.Equal(paymaster, app.gasPayer(st, from, pricedTx(0, 1)))
+	assert.Equal(1, calls)
+
+	// and a cleared one disables it
+	assert.True(utils.SetParam("paymaster", ""))
+	app.syncPaymaster()
+	assert.Equal(from, app.gasPayer(st, from, pricedTx(0, 1)))
+	assert.Equal(1, calls)
+}
+
+func TestPaymasterCallBudget(t *testing.T) {
+	assert := assert.New(t)
+
+	paymaster := common.HexToAddress("0x5000000000000000000000000000000000000005")
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	app := &EthermintApplication{logger: tmLog.NewNopLogger()}
+	app.setPaymaster(paymaster)
+	calls := 0
+	app.sponsor = func(st *state.StateDB, paymaster, from common.Address, tx *ethTypes.Transaction) (bool, error) {
+		calls++
+		return true, nil
+	}
+
+	st := newTestState()
+	for i := 0; i < paymasterCallsPerBlock; i++ {
+		assert.Equal(paymaster, app.gasPayer(st, from, pricedTx(uint64(i), 1)))
+	}
+	// past the budget the sender pays, without asking
+	late := pricedTx(paymasterCallsPerBlock, 1)
+	assert.Equal(from, app.gasPayer(st, from, late))
+	assert.Equal(paymasterCallsPerBlock, calls)
+	// the cached decisions are still served
+	assert.Equal(paymaster, app.gasPayer(st, from, pricedTx(0, 1)))
+
+	// the next block asks again
+	app.resetPaymasterCalls()
+	assert.Equal(paymaster, app.gasPayer(st, from, late))
+	assert.Equal(paymasterCallsPerBlock+1, calls)
+}
+
+func TestReplaySponsoredTx(t *testing.T) {
+	assert := assert.New(t)
+
+	paymaster := common.HexToAddress("0x5000000000000000000000000000000000000005")
+	sponsored := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	tx := pricedTx(0, 1)
+	base := newTestState()
+	base.AddBalance(paymaster, gasCost(tx))
+	base.AddBalance(sponsored, tx.Value())
+
+	validate := func(tx *ethTypes.Transaction,
+		st *state.StateDB) (common.Address, common.Address, uint64, abciTypes.ResponseCheckTx) {
+
+		return sponsored, paymaster, tx.Nonce(), checkSponsoredBalance(st, sponsored, paymaster, tx)
+	}
+
+	// the replay and the revalidation charge the gas to the paymaster
+	st := base.Copy()
+	assert.Equal([]uint32{abciTypes.CodeTypeOK}, codes(replayCheck(st, []*ethTypes.Transaction{tx}, validate)))
+	assert.Equal(0, st.GetBalance(paymaster).Sign())
+	assert.Equal(0, st.GetBalance(sponsored).Sign())
+
+	pool := newTxPool(base)
+	pool.add(sponsored, tx)
+	st, kept, evicted := revalidatePool(pool, unsponsored(validate))
+	assert.Empty(evicted)
+	assert.Len(kept.txs, 1)
+	assert.Equal(0, st.GetBalance(paymaster).Sign())
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// checkPendingCap rejects a new tx of a sender which already has the maximum number
+// of txs in the mempool. A resident tx being rechecked is let through.
+func (app *EthermintApplication) checkPendingCap(from common.Address, tx *ethTypes.Transaction) abciTypes.ResponseCheckTx {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if app.maxPendingPerSender <= 0 {
+		return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+	}
+	pending := app.pendingBySender[from]
+	if _, ok := pending[tx.Hash()]; !ok && len(pending) >= app.maxPendingPerSender {
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeTooManyPending,
+			Log: fmt.Sprintf(
+				"Sender has %d pending transactions, the maximum is %d",
+				len(pending), app.maxPendingPerSender)}
+	}
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
+
+// addPending counts a tx admitted in the mempool
+func (app *EthermintApplication) addPending(from common.Address, tx *ethTypes.Transaction) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	pending, ok := app.pendingBySender[from]
+	if !ok {
+		pending = make(map[common.Hash]uint64)
+		app.pendingBySender[from] = pending
+	}
+	pending[tx.Hash()] = tx.Nonce()
+}
+
+// releasePending frees the slot of a tx leaving the mempool, delivered in a block
+// or failing its recheck
+func (app *EthermintApplication) releasePending(tx *ethTypes.Transaction) {
+	from, err := app.sender(tx)
+	if err != nil {
+		// never admitted
+		return
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if pending, ok := app.pendingBySender[from]; ok {
+		delete(pending, tx.Hash())
+		if len(pending) == 0 {
+			delete(app.pendingBySender, from)
+		}
+	}
+}
+
+// prunePendingBySender drops on Commit the txs whose nonce has been used by a
+// committed tx, e.g. replaced ones, which left the mempool without being delivered
+func (app *EthermintApplication) prunePendingBySender(committedState *state.StateDB) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	for from, pending := range app.pendingBySender {
+		nonce := committedState.GetNonce(from)
+		for hash, txNonce := range pending {
+			if txNonce < nonce {
+				delete(pending, hash)
+			}
+		}
+		if len(pending) == 0 {
+			delete(app.pendingBySender, from)
+		}
+	}
+}
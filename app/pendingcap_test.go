@@ -0,0 +1,73 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func newPendingCapTestApp(limit int) (*EthermintApplication, ethTypes.Signer) {
+	signer := ethTypes.NewEIP155Signer(big.NewInt(777))
+	app := &EthermintApplication{
+		pendingBySender:     make(map[common.Address]map[common.Hash]uint64),
+		maxPendingPerSender: limit,
+	}
+	app.SetSignerResolver(func(tx *ethTypes.Transaction) ethTypes.Signer {
+		return signer
+	})
+	return app, signer
+}
+
+func TestPendingPerSenderCap(t *testing.T) {
+	assert := assert.New(t)
+
+	app, signer := newPendingCapTestApp(2)
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	var txs []*ethTypes.Transaction
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		tx, err := ethTypes.SignTx(pricedTx(nonce, 1), signer, key)
+		assert.Nil(err)
+		txs = append(txs, tx)
+	}
+
+	// admit up to the cap
+	for _, tx := range txs[:2] {
+		assert.Equal(abciTypes.CodeTypeOK, app.checkPendingCap(from, tx).Code)
+		app.addPending(from, tx)
+	}
+	assert.Equal(errors.CodeTypeTooManyPending, app.checkPendingCap(from, txs[2]).Code)
+	// a resident tx is still rechecked
+	assert.Equal(abciTypes.CodeTypeOK, app.checkPendingCap(from, txs[1]).Code)
+
+	// delivering a tx frees a slot
+	app.releasePending(txs[0])
+	assert.Equal(abciTypes.CodeTypeOK, app.checkPendingCap(from, txs[2]).Code)
+}
+
+func TestPrunePendingBySender(t *testing.T) {
+	assert := assert.New(t)
+
+	app, _ := newPendingCapTestApp(2)
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	replaced, next := pricedTx(0, 1), pricedTx(1, 1)
+	app.addPending(from, replaced)
+	app.addPending(from, next)
+
+	// another tx with nonce 0 got committed
+	st := newTestState()
+	st.SetNonce(from, 1)
+	app.prunePendingBySender(st)
+
+	assert.Len(app.pendingBySender[from], 1)
+	assert.Contains(app.pendingBySender[from], next.Hash())
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// pendingStateMethods are the rpc methods answered from the CheckTx state when
+// their block number is "pending"
+var pendingStateMethods = map[string]bool{
+	"eth_getBalance":          true,
+	"eth_getTransactionCount": true,
+	"eth_getCode":             true,
+	"eth_getStorageAt":        true,
+}
+
+// pendingStateQuery answers the state reads at the pending block from the CheckTx
+// state, so that they reflect the txs waiting in the mempool. CheckTx doesn't run
+// the EVM, only the balances and nonces are moved by the pending txs: the code and
+// storage read there are the committed ones, until the txs are delivered.
+func (app *EthermintApplication) pendingStateQuery(in jsonRequest) (result interface{}, handled bool, err error) {
+	idx, ok := blockParamIndex[in.Method]
+	if !pendingStateMethods[in.Method] || !ok || len(in.Params) != idx+1 || in.Params[idx] != "pending" {
+		return nil, false, nil
+	}
+	// a denied method is rejected by the rpc filter of Query
+	if !app.rpcMethodAllowed(in.Method) {
+		return nil, false, nil
+	}
+	hex, ok := in.Params[0].(string)
+	if !ok || !common.IsHexAddress(hex) {
+		return nil, true, fmt.Errorf("invalid address: %v", in.Params[0])
+	}
+	addr := common.HexToAddress(hex)
+	var key common.Hash
+	if in.Method == "eth_getStorageAt" {
+		position, ok := in.Params[1].(string)
+		if !ok {
+			return nil, true, fmt.Errorf("invalid storage position: %v", in.Params[1])
+		}
+		key = common.HexToHash(position)
+	}
+
+	app.checkTxStateMtx.Lock()
+	defer app.checkTxStateMtx.Unlock()
+
+	switch in.Method {
+	case "eth_getBalance":
+		return (*hexutil.Big)(new(big.Int).Set(app.checkTxState.GetBalance(addr))), true, nil
+	case "eth_getTransactionCount":
+		return hexutil.Uint64(app.checkTxState.GetNonce(addr)), true, nil
+	case "eth_getCode":
+		return hexutil.Bytes(common.CopyBytes(app.checkTxState.GetCode(addr))), true, nil
+	default:
+		value := app.checkTxState.GetState(addr, key)
+		return hexutil.Bytes(value[:]), true, nil
+	}
+}
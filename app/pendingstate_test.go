@@ -0,0 +1,69 @@
+package app
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+)
+
+func TestPendingStateQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	contract := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	slot := common.HexToHash("0x01")
+	st := newTestState()
+	st.AddBalance(from, big.NewInt(1000000))
+	st.SetCode(contract, []byte{0x60, 0x00})
+	st.SetState(contract, slot, common.HexToHash("0x2a"))
+
+	app := &EthermintApplication{logger: tmLog.NewNopLogger(), checkTxState: st}
+	query := func(method string, params ...interface{}) (interface{}, bool, error) {
+		return app.pendingStateQuery(jsonRequest{Method: method, Params: params})
+	}
+
+	// a tx admitted by CheckTx moves the pending balance and nonce
+	tx := pricedTx(0, 1)
+	applySpeculativeTx(st, from, 0, tx, CheckTxNew)
+	balance, handled, err := query("eth_getBalance", from.Hex(), "pending")
+	assert.True(handled)
+	assert.Nil(err)
+	assert.Equal(new(big.Int).Sub(big.NewInt(1000000), tx.Cost()).String(), balance.(*hexutil.Big).ToInt().String())
+	nonce, _, _ := query("eth_getTransactionCount", from.Hex(), "pending")
+	assert.Equal(hexutil.Uint64(1), nonce)
+
+	// code and storage are read from the same state
+	code, _, err := query("eth_getCode", contract.Hex(), "pending")
+	assert.Nil(err)
+	assert.Equal(hexutil.Bytes{0x60, 0x00}, code)
+	value, _, err := query("eth_getStorageAt", contract.Hex(), "0x1", "pending")
+	assert.Nil(err)
+	assert.Equal(hexutil.Bytes(common.HexToHash("0x2a").Bytes()), value)
+
+	// the other blocks are left to the rpc client
+	_, handled, _ = query("eth_getBalance", from.Hex(), "latest")
+	assert.False(handled)
+	_, handled, _ = query("eth_call", map[string]interface{}{}, "pending")
+	assert.False(handled)
+	_, handled, err = query("eth_getCode", "0x12", "pending")
+	assert.True(handled)
+	assert.NotNil(err)
+
+	// through Query
+	data, _ := json.Marshal(jsonRequest{Method: "eth_getStorageAt", Params: []interface{}{contract.Hex(), "0x1", "pending"}})
+	res := app.Query(abciTypes.RequestQuery{Data: data})
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+	assert.Equal(`"0x000000000000000000000000000000000000000000000000000000000000002a"`, string(res.Value))
+
+	// the rpc filter still applies
+	assert.Nil(app.setOption("rpc_deny_method", "eth_getCode"))
+	_, handled, _ = query("eth_getCode", contract.Hex(), "pending")
+	assert.False(handled)
+}
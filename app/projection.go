@@ -0,0 +1,60 @@
+package app
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+)
+
+// StateChange is a hypothetical change to an account used to project the state
+type StateChange struct {
+	Address common.Address
+	// Balance is added to the account balance, a negative value is subtracted
+	Balance *big.Int
+	// Nonce overrides the account nonce when set
+	Nonce *uint64
+}
+
+// ValidateAgainstProjection validates a tx against a copy of the CheckTx state
+// with the given changes applied, i.e. tells whether the tx would be valid if
+// those pending changes landed. Neither the CheckTx state nor the mempool
+// bookkeeping are modified.
+// #unstable
+func (app *EthermintApplication) ValidateAgainstProjection(tx *ethTypes.Transaction,
+	projectedChanges []StateChange) abciTypes.ResponseCheckTx {
+
+	app.checkTxStateMtx.Lock()
+	projected := app.checkTxState.Copy()
+	app.checkTxStateMtx.Unlock()
+
+	return validateAgainstProjection(projected, tx, projectedChanges, app.validateTxState)
+}
+
+// validateAgainstProjection validates the tx on projected once the changes are applied to it
+func validateAgainstProjection(projected *state.StateDB, tx *ethTypes.Transaction,
+	projectedChanges []StateChange, validate txValidator) abciTypes.ResponseCheckTx {
+
+	applyStateChanges(projected, projectedChanges)
+	_, _, _, resp := validate(tx, projected)
+	return resp
+}
+
+// applyStateChanges applies the hypothetical changes to the given state
+func applyStateChanges(st *state.StateDB, changes []StateChange) {
+	for _, change := range changes {
+		if change.Balance != nil {
+			switch change.Balance.Sign() {
+			case 1:
+				st.AddBalance(change.Address, change.Balance)
+			case -1:
+				st.SubBalance(change.Address, new(big.Int).Neg(change.Balance))
+			}
+		}
+		if change.Nonce != nil {
+			st.SetNonce(change.Address, *change.Nonce)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestProjectedIncomingTransfer(t *testing.T) {
+	assert := assert.New(t)
+
+	st := newTestState()
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	st.AddBalance(from, big.NewInt(1000))
+
+	// cost = 1000 + 21000 * 1
+	tx := ethTypes.NewTransaction(0, to, big.NewInt(1000), 21000, big.NewInt(1), nil)
+	assert.Equal(errors.CodeTypeBaseInvalidInput, checkBalance(st, from, tx).Code)
+
+	projected := st.Copy()
+	nonce := uint64(3)
+	applyStateChanges(projected, []StateChange{
+		{Address: from, Balance: big.NewInt(21000)},
+		{Address: to, Nonce: &nonce},
+	})
+	assert.Equal(abciTypes.CodeTypeOK, checkBalance(projected, from, tx).Code)
+	assert.Equal(nonce, projected.GetNonce(to))
+
+	// an outgoing change can make it underfunded again
+	applyStateChanges(projected, []StateChange{{Address: from, Balance: big.NewInt(-1)}})
+	assert.Equal(errors.CodeTypeBaseInvalidInput, checkBalance(projected, from, tx).Code)
+
+	// the original state is left untouched
+	assert.Equal(big.NewInt(1000), st.GetBalance(from))
+	assert.Equal(errors.CodeTypeBaseInvalidInput, checkBalance(st, from, tx).Code)
+}
+
+func TestValidateAgainstProjection(t *testing.T) {
+	assert := assert.New(t)
+
+	signer := ethTypes.HomesteadSigner{}
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	validate := replayValidator(signer)
+
+	st := newTestState()
+	st.AddBalance(from, big.NewInt(1000))
+	tx, _ := ethTypes.SignTx(pricedTx(0, 1), signer, key)
+	assert.Equal(errors.CodeTypeBaseInvalidInput, validateAgainstProjection(st.Copy(), tx, nil, validate).Code)
+
+	// valid once the incoming transfer lands
+	incoming := []StateChange{{Address: from, Balance: big.NewInt(21000)}}
+	assert.Equal(abciTypes.CodeTypeOK, validateAgainstProjection(st.Copy(), tx, incoming, validate).Code)
+
+	// but not after a tx of the sender landed first
+	nonce := uint64(1)
+	landed := append(incoming, StateChange{Address: from, Nonce: &nonce})
+	assert.Equal(errors.CodeTypeBadNonce, validateAgainstProjection(st.Copy(), tx, landed, validate).Code)
+
+	assert.Equal(big.NewInt(1000), st.GetBalance(from))
+	assert.Equal(uint64(0), st.GetNonce(from))
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// parseSenderPriorities parses a comma separated list of address:priority pairs;
+// an empty value gives an empty map
+func parseSenderPriorities(value string) (map[common.Address]uint64, error) {
+	priorities := make(map[common.Address]uint64)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		parts := strings.Split(pair, ":")
+		if len(parts) != 2 || !common.IsHexAddress(parts[0]) {
+			return nil, fmt.Errorf("invalid sender priority: %s", pair)
+		}
+		priority, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sender priority: %s", pair)
+		}
+		priorities[common.HexToAddress(parts[0])] = priority
+	}
+	return priorities, nil
+}
+
+// setSenderPriorities replaces the priorities of the senders in the proposal order
+func (app *EthermintApplication) setSenderPriorities(priorities map[common.Address]uint64) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.senderPriorities = priorities
+}
+
+// SortCandidateTxs orders the candidate txs of a block proposal: the txs of a
+// sender go in nonce order, and among the next txs of every sender the highest
+// sender priority goes first, then the highest gas price, ties broken by hash.
+// The senders without a configured priority have priority 0. The order only
+// depends on the txs and the priorities, so every node with the same
+// priorities computes the same one. Txs without a valid signature are put
+// last, in their original order.
+// #unstable
+func (app *EthermintApplication) SortCandidateTxs(txs []*ethTypes.Transaction) []*ethTypes.Transaction {
+	bySender := make(map[common.Address][]*ethTypes.Transaction)
+	var unsigned []*ethTypes.Transaction
+	for _, tx := range txs {
+		from, err := app.sender(tx)
+		if err != nil {
+			unsigned = append(unsigned, tx)
+			continue
+		}
+		bySender[from] = append(bySender[from], tx)
+	}
+
+	app.mu.Lock()
+	heads := make(senderHeap, 0, len(bySender))
+	for from, group := range bySender {
+		heads = append(heads, senderTxs{priority: app.senderPriorities[from], txs: group})
+	}
+	app.mu.Unlock()
+
+	for _, head := range heads {
+		group := head.txs
+		sort.SliceStable(group, func(i, j int) bool {
+			if group[i].Nonce() != group[j].Nonce() {
+				return group[i].Nonce() < group[j].Nonce()
+			}
+			// a replaced nonce, the pricier first
+			return cheaper(group[j], group[i])
+		})
+	}
+	heap.Init(&heads)
+
+	sorted := make([]*ethTypes.Transaction, 0, len(txs))
+	for heads.Len() > 0 {
+		group := heads[0].txs
+		sorted = append(sorted, group[0])
+		if len(group) > 1 {
+			heads[0].txs = group[1:]
+			heap.Fix(&heads, 0)
+		} else {
+			heap.Pop(&heads)
+		}
+	}
+	return append(sorted, unsigned...)
+}
+
+// senderTxs are the nonce ordered txs of a sender and its priority
+type senderTxs struct {
+	priority uint64
+	txs      []*ethTypes.Transaction
+}
+
+// senderHeap is a max-heap of the txs of each sender by priority, then by the gas
+// price of their next tx
+type senderHeap []senderTxs
+
+func (h senderHeap) Len() int { return len(h) }
+
+func (h senderHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return cheaper(h[j].txs[0], h[i].txs[0])
+}
+
+func (h senderHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *senderHeap) Push(x interface{}) { *h = append(*h, x.(senderTxs)) }
+
+func (h *senderHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	group := old[n-1]
+	old[n-1] = senderTxs{}
+	*h = old[:n-1]
+	return group
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSortCandidateTxs(t *testing.T) {
+	assert := assert.New(t)
+
+	app, signer := newPendingCapTestApp(0)
+	keyA, _ := crypto.GenerateKey()
+	keyB, _ := crypto.GenerateKey()
+	keyC, _ := crypto.GenerateKey()
+	sign := func(key *ecdsa.PrivateKey, nonce uint64, price int64) *ethTypes.Transaction {
+		tx, err := ethTypes.SignTx(pricedTx(nonce, price), signer, key)
+		assert.Nil(err)
+		return tx
+	}
+
+	a0, a1, a2 := sign(keyA, 0, 1), sign(keyA, 1, 10), sign(keyA, 2, 3)
+	b0, b1 := sign(keyB, 0, 5), sign(keyB, 1, 2)
+	c0 := sign(keyC, 0, 4)
+	unsigned := pricedTx(0, 100)
+
+	// interleaved, nonces out of order
+	txs := []*ethTypes.Transaction{a2, b1, unsigned, a1, c0, b0, a0}
+	sorted := app.SortCandidateTxs(txs)
+
+	// b0 (5) leads; then c0 (4) beats b1 (2) and a0 (1); a0 unlocks a1 (10) and a2 (3)
+	expected := []*ethTypes.Transaction{b0, c0, b1, a0, a1, a2, unsigned}
+	assert.Equal(hashes(expected), hashes(sorted))
+	// the input is left alone
+	assert.Equal(a2.Hash(), txs[0].Hash())
+
+	// the same set in another arrival order gives the same proposal
+	shuffled := []*ethTypes.Transaction{a0, unsigned, b0, c0, a1, b1, a2}
+	assert.Equal(hashes(sorted), hashes(app.SortCandidateTxs(shuffled)))
+
+	assert.Empty(app.SortCandidateTxs(nil))
+}
+
+func hashes(txs []*ethTypes.Transaction) []common.Hash {
+	var res []common.Hash
+	for _, tx := range txs {
+		res = append(res, tx.Hash())
+	}
+	return res
+}
+
+// the price of the tx doesn't matter for the nonce order of a sender
+func TestSortCandidateTxsNonceFirst(t *testing.T) {
+	assert := assert.New(t)
+
+	app, signer := newPendingCapTestApp(0)
+	key, _ := crypto.GenerateKey()
+	var txs []*ethTypes.Transaction
+	for nonce := uint64(5); nonce > 0; nonce-- {
+		tx, err := ethTypes.SignTx(pricedTx(nonce-1, int64(nonce)), signer, key)
+		assert.Nil(err)
+		txs = append(txs, tx)
+	}
+	for i, tx := range app.SortCandidateTxs(txs) {
+		assert.Equal(uint64(i), tx.Nonce())
+	}
+}
+
+func TestSortCandidateTxsSenderPriority(t *testing.T) {
+	assert := assert.New(t)
+
+	app, signer := newPendingCapTestApp(0)
+	oracleKey, _ := crypto.GenerateKey()
+	normalKey, _ := crypto.GenerateKey()
+	sign := func(key *ecdsa.PrivateKey, nonce uint64, price int64) *ethTypes.Transaction {
+		tx, err := ethTypes.SignTx(pricedTx(nonce, price), signer, key)
+		assert.Nil(err)
+		return tx
+	}
+	o0, o1 := sign(oracleKey, 0, 5), sign(oracleKey, 1, 5)
+	n0, n1 := sign(normalKey, 0, 5), sign(normalKey, 1, 50)
+	txs := []*ethTypes.Transaction{n1, o1, n0, o0}
+
+	oracle := crypto.PubkeyToAddress(oracleKey.PublicKey)
+	assert.Nil(app.setOption("sender_priority", oracle.Hex()+":1"))
+	// the oracle goes first at equal gas price, and even against a pricier tx,
+	// its txs still in nonce order
+	expected := []*ethTypes.Transaction{o0, o1, n0, n1}
+	assert.Equal(hashes(expected), hashes(app.SortCandidateTxs(txs)))
+
+	// the highest priority leads
+	normal := crypto.PubkeyToAddress(normalKey.PublicKey)
+	assert.Nil(app.setOption("sender_priority", oracle.Hex()+":1,"+normal.Hex()+":2"))
+	expected = []*ethTypes.Transaction{n0, n1, o0, o1}
+	assert.Equal(hashes(expected), hashes(app.SortCandidateTxs(txs)))
+
+	assert.NotNil(app.setOption("sender_priority", oracle.Hex()+":high"))
+	assert.NotNil(app.setOption("sender_priority", "0x12:1"))
+}
@@ -0,0 +1,12 @@
+package app
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// notifyPrune signals the committed root to the PruneNotify callback, if any
+func (app *EthermintApplication) notifyPrune(root common.Hash, height int64) {
+	if app.PruneNotify != nil {
+		app.PruneNotify(root, height)
+	}
+}
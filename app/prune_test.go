@@ -0,0 +1,29 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestPruneNotify(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{}
+	// no callback installed
+	app.notifyPrune(common.HexToHash("0x01"), 1)
+
+	var roots []common.Hash
+	var heights []int64
+	app.PruneNotify = func(safeRoot common.Hash, height int64) {
+		roots = append(roots, safeRoot)
+		heights = append(heights, height)
+	}
+	app.notifyPrune(common.HexToHash("0x02"), 2)
+	app.notifyPrune(common.HexToHash("0x03"), 3)
+
+	assert.Equal([]common.Hash{common.HexToHash("0x02"), common.HexToHash("0x03")}, roots)
+	assert.Equal([]int64{2, 3}, heights)
+}
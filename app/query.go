@@ -0,0 +1,162 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+)
+
+// queryPathV2 is the query path on which failures are reported as a json-rpc
+// style error object in the response value
+const queryPathV2 = "/v2"
+
+// json-rpc error codes of the structured query failures
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+	rpcServerError    = -32000
+)
+
+// queryError is the json-rpc style error of a failed query
+type queryError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type queryErrorResponse struct {
+	Error queryError `json:"error"`
+}
+
+// queryFailure builds the response of a failed query. The error is only reported
+// in the value when a structured error is asked for, it's always in the log.
+func queryFailure(structured bool, code uint32, rpcCode int, err error) abciTypes.ResponseQuery {
+	res := abciTypes.ResponseQuery{Code: code, Log: err.Error()}
+	if structured {
+		res.Value, _ = json.Marshal(queryErrorResponse{
+			Error: queryError{Code: rpcCode, Message: err.Error()},
+		})
+	}
+	return res
+}
+
+// rpcErrorCode returns the json-rpc code of an error returned by the rpc client
+func rpcErrorCode(err error) int {
+	if rpcErr, ok := err.(rpc.Error); ok {
+		return rpcErr.ErrorCode()
+	}
+	return rpcServerError
+}
+
+// position of the block number parameter of the rpc methods which can be
+// evaluated at a past height
+var blockParamIndex = map[string]int{
+	"eth_getBalance":          1,
+	"eth_getCode":             1,
+	"eth_getTransactionCount": 1,
+	"eth_call":                1,
+	"eth_getStorageAt":        2,
+}
+
+// localQuery answers the travis_* methods served by the application itself
+// instead of being forwarded to the ethereum rpc client
+func (app *EthermintApplication) localQuery(in jsonRequest) (result interface{}, handled bool, err error) {
+	switch in.Method {
+	case "travis_missedBlocks":
+		return app.livenessSnapshot(), true, nil
+	case "travis_validators":
+		return app.validatorSet(), true, nil
+	case "travis_commitStats":
+		return app.commitStatsView(), true, nil
+	case "travis_estimateGas":
+		gas, err := app.estimateGas(in.Params)
+		return gas, true, err
+	case "travis_feeParams":
+		return app.feeParams(), true, nil
+	case "travis_gasPrice":
+		return app.gasPriceView(), true, nil
+	case "travis_suggestGasPrice":
+		return app.suggestGasPrice(), true, nil
+	case "travis_genesisSupply":
+		return app.genesisSupplyView(), true, nil
+	case "travis_lastRewards":
+		return app.lastBlockRewards(), true, nil
+	case "travis_decodeTx":
+		decoded, err := app.decodeRawTx(in.Params)
+		return decoded, true, err
+	case "travis_txReceipt":
+		receipt, err := app.txReceipt(in.Params)
+		return receipt, true, err
+	case "travis_resetNonce":
+		result, err := app.resetNonceQuery(in.Params)
+		return result, true, err
+	case "travis_pendingBalance":
+		balance, err := app.pendingBalance(in.Params)
+		return balance, true, err
+	case "travis_txOrderRoot":
+		root, err := app.txOrderRootQuery(in.Params)
+		return root, true, err
+	case "travis_blockFees":
+		fees, err := app.blockFeesQuery(in.Params)
+		return fees, true, err
+	case "travis_traceCheck":
+		traces, err := app.traceCheckQuery(in.Params)
+		return traces, true, err
+	case "travis_nextNonce":
+		nonce, err := app.nextNonceQuery(in.Params)
+		return nonce, true, err
+	case "travis_appHash":
+		hash, err := app.appHashQuery(in.Params)
+		return hash, true, err
+	case "travis_randomSeed":
+		return app.randomSeedView(), true, nil
+	case "travis_txpool":
+		listing, err := app.txPoolListing(in)
+		return listing, true, err
+	}
+	return app.pendingStateQuery(in)
+}
+
+// pendingBalance returns the balance of an account in the CheckTx state. Unlike
+// eth_getBalance it reflects the uncommitted CheckTx mutations, i.e. the cost of
+// the txs of the account waiting in the mempool is already debited.
+func (app *EthermintApplication) pendingBalance(params []interface{}) (*hexutil.Big, error) {
+	if len(params) != 1 {
+		return nil, fmt.Errorf("expected 1 param, got %d", len(params))
+	}
+	hex, ok := params[0].(string)
+	if !ok || !common.IsHexAddress(hex) {
+		return nil, fmt.Errorf("invalid address: %v", params[0])
+	}
+
+	app.checkTxStateMtx.Lock()
+	defer app.checkTxStateMtx.Unlock()
+	balance := new(big.Int).Set(app.checkTxState.GetBalance(common.HexToAddress(hex)))
+	return (*hexutil.Big)(balance), nil
+}
+
+// paramsAtHeight returns the params of the request with the block number
+// parameter pinned to the requested height
+func paramsAtHeight(in jsonRequest, head uint64) ([]interface{}, error) {
+	idx, ok := blockParamIndex[in.Method]
+	if !ok {
+		return nil, fmt.Errorf("method %s can't be queried at a height", in.Method)
+	}
+	if *in.Height > head {
+		return nil, fmt.Errorf("height %d is in the future, current height is %d", *in.Height, head)
+	}
+	if len(in.Params) < idx {
+		return nil, fmt.Errorf("method %s expects %d params before the block number", in.Method, idx)
+	}
+	params := make([]interface{}, idx+1)
+	copy(params, in.Params[:idx])
+	params[idx] = hexutil.Uint64(*in.Height)
+	return params, nil
+}
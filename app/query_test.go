@@ -0,0 +1,167 @@
+package app
+
+import (
+	"encoding/json"
+	goerr "errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+
+	"github.com/CyberMiles/travis/errors"
+	"github.com/CyberMiles/travis/utils"
+)
+
+func TestParamsAtHeight(t *testing.T) {
+	assert := assert.New(t)
+
+	addr := "0x1000000000000000000000000000000000000001"
+	height := uint64(5)
+
+	// a balance at an older height pins the block number
+	params, err := paramsAtHeight(jsonRequest{Method: "eth_getBalance",
+		Params: []interface{}{addr, "latest"}, Height: &height}, 10)
+	assert.Nil(err)
+	assert.Equal([]interface{}{addr, hexutil.Uint64(5)}, params)
+
+	// the block number is appended when missing
+	params, err = paramsAtHeight(jsonRequest{Method: "eth_getBalance",
+		Params: []interface{}{addr}, Height: &height}, 5)
+	assert.Nil(err)
+	assert.Equal([]interface{}{addr, hexutil.Uint64(5)}, params)
+
+	// heights in the future are rejected
+	_, err = paramsAtHeight(jsonRequest{Method: "eth_getBalance",
+		Params: []interface{}{addr}, Height: &height}, 4)
+	assert.NotNil(err)
+
+	// so are methods without a block number
+	_, err = paramsAtHeight(jsonRequest{Method: "eth_gasPrice", Height: &height}, 10)
+	assert.NotNil(err)
+}
+
+type testRPCError struct{}
+
+func (testRPCError) Error() string  { return "execution reverted" }
+func (testRPCError) ErrorCode() int { return -32015 }
+
+func TestStructuredQueryErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	decode := func(res abciTypes.ResponseQuery) queryError {
+		var payload queryErrorResponse
+		assert.Nil(json.Unmarshal(res.Value, &payload))
+		return payload.Error
+	}
+
+	// bad json
+	app := &EthermintApplication{logger: tmLog.NewNopLogger()}
+	res := app.Query(abciTypes.RequestQuery{Path: queryPathV2, Data: []byte("{")})
+	assert.Equal(errors.CodeTypeInternalErr, res.Code)
+	assert.Equal(rpcParseError, decode(res).Code)
+	assert.NotEmpty(decode(res).Message)
+
+	// the legacy path only sets the log
+	res = app.Query(abciTypes.RequestQuery{Data: []byte("{")})
+	assert.Equal(errors.CodeTypeInternalErr, res.Code)
+	assert.Empty(res.Value)
+	assert.NotEmpty(res.Log)
+
+	// invalid params
+	res = queryFailure(true, errors.CodeTypeBaseInvalidInput, rpcInvalidParams, goerr.New("height in the future"))
+	assert.Equal(errors.CodeTypeBaseInvalidInput, res.Code)
+	assert.Equal(queryError{Code: rpcInvalidParams, Message: "height in the future"}, decode(res))
+
+	// upstream rpc errors keep their code
+	res = queryFailure(true, errors.CodeTypeInternalErr, rpcErrorCode(testRPCError{}), testRPCError{})
+	assert.Equal(queryError{Code: -32015, Message: "execution reverted"}, decode(res))
+
+	// other errors are reported as server errors
+	assert.Equal(rpcServerError, rpcErrorCode(goerr.New("connection refused")))
+}
+
+func TestPendingBalance(t *testing.T) {
+	assert := assert.New(t)
+
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	committed := newTestState()
+	committed.AddBalance(from, big.NewInt(1000000))
+
+	app := &EthermintApplication{checkTxState: committed.Copy()}
+
+	// the debit of CheckTx admitting a tx of the account
+	tx := pricedTx(0, 1)
+	applySpeculativeTx(app.checkTxState, from, 0, tx, CheckTxNew)
+
+	result, handled, err := app.localQuery(jsonRequest{
+		Method: "travis_pendingBalance",
+		Params: []interface{}{from.Hex()},
+	})
+	assert.True(handled)
+	assert.Nil(err)
+	expected := new(big.Int).Sub(big.NewInt(1000000), tx.Cost())
+	assert.Equal(expected, result.(*hexutil.Big).ToInt())
+	assert.Equal(big.NewInt(1000000), committed.GetBalance(from))
+
+	_, _, err = app.localQuery(jsonRequest{
+		Method: "travis_pendingBalance",
+		Params: []interface{}{"0x12"},
+	})
+	assert.NotNil(err)
+}
+
+func TestFeeParams(t *testing.T) {
+	assert := assert.New(t)
+
+	gasPrice := utils.GetParams().GasPrice
+	defer func() { utils.GetParams().GasPrice = gasPrice }()
+	utils.GetParams().GasPrice = 5e9
+
+	app := &EthermintApplication{}
+	result, handled, err := app.localQuery(jsonRequest{Method: "travis_feeParams"})
+	assert.True(handled)
+	assert.Nil(err)
+
+	params := result.(feeParams)
+	assert.Equal(big.NewInt(5e9), params.MinGasPrice.ToInt())
+	assert.Equal(big.NewInt(5e9), params.ParamsGasPrice.ToInt())
+
+	// a change of the chain params is reflected
+	utils.GetParams().GasPrice = 1e9
+	params = app.feeParams()
+	assert.Equal(big.NewInt(1e9), params.MinGasPrice.ToInt())
+}
+
+func TestBaseAppQueryRouting(t *testing.T) {
+	assert := assert.New(t)
+
+	ethApp, closeClient := newRPCFilterTestApp(t)
+	defer closeClient()
+	app := &BaseApp{StoreApp: &StoreApp{}, EthApp: ethApp}
+
+	// the json-rpc requests reach the EthermintApplication, proxied or local
+	for _, path := range []string{"", "/", queryPathV2} {
+		res := app.Query(abciTypes.RequestQuery{Path: path, Data: []byte(`{"method":"eth_blockNumber"}`)})
+		assert.Equal(abciTypes.CodeTypeOK, res.Code, path)
+		assert.Equal(`"0x10"`, string(res.Value), path)
+	}
+	res := app.Query(abciTypes.RequestQuery{Data: []byte(`{"method":"travis_validators"}`)})
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+
+	// so do their failures and the response cap
+	res = app.Query(abciTypes.RequestQuery{Path: queryPathV2, Data: []byte(`{"params":[]}`)})
+	assert.Equal(errors.CodeTypeBaseInvalidInput, res.Code)
+	assert.Nil(ethApp.setOption("max_query_response_size", "2"))
+	res = app.Query(abciTypes.RequestQuery{Data: []byte(`{"method":"eth_blockNumber"}`)})
+	assert.Equal(errors.CodeTypeResponseTooLarge, res.Code)
+
+	// the store paths are still answered by the StoreApp, which rejects an empty key
+	res = app.Query(abciTypes.RequestQuery{Path: "/key"})
+	assert.Equal(errors.CodeTypeEncodingErr, res.Code)
+	assert.Equal("Query cannot be zero length", res.Log)
+}
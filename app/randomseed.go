@@ -0,0 +1,44 @@
+package app
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+)
+
+// randomSeed is the seed of a block, served by travis_randomSeed
+type randomSeed struct {
+	Height hexutil.Uint64 `json:"height"`
+	Seed   common.Hash    `json:"seed"`
+}
+
+// blockSeed derives the seed of a block from its header. It only depends on the
+// agreed upon header so it's the same on every node.
+func blockSeed(header abciTypes.Header) common.Hash {
+	return crypto.Keccak256Hash(header.LastBlockHash, header.Proposer.Address)
+}
+
+// recordRandomSeed computes the seed of the block started in BeginBlock
+func (app *EthermintApplication) recordRandomSeed(header abciTypes.Header) {
+	seed := blockSeed(header)
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.seed = randomSeed{Height: hexutil.Uint64(header.Height), Seed: seed}
+}
+
+// RandomSeed returns the seed of the current block and its height. The proposer
+// can try a few blocks to pick a seed, it mustn't guard high stakes.
+// #unstable
+func (app *EthermintApplication) RandomSeed() (common.Hash, int64) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return app.seed.Seed, int64(app.seed.Height)
+}
+
+func (app *EthermintApplication) randomSeedView() randomSeed {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return app.seed
+}
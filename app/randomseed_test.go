@@ -0,0 +1,48 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+)
+
+func TestRandomSeedDeterministic(t *testing.T) {
+	assert := assert.New(t)
+
+	header := abciTypes.Header{
+		Height:        7,
+		LastBlockHash: common.HexToHash("0x01").Bytes(),
+		Proposer:      abciTypes.Validator{Address: []byte{0xaa, 0xbb}, Power: 10},
+	}
+
+	// two nodes seeing the same header agree on the seed
+	app1 := &EthermintApplication{logger: tmLog.NewNopLogger()}
+	app2 := &EthermintApplication{logger: tmLog.NewNopLogger()}
+	app1.recordRandomSeed(header)
+	app2.recordRandomSeed(header)
+	seed1, height := app1.RandomSeed()
+	seed2, _ := app2.RandomSeed()
+	assert.Equal(seed1, seed2)
+	assert.Equal(int64(7), height)
+	assert.NotEqual(common.Hash{}, seed1)
+
+	// a different proposer or parent gives another seed
+	other := header
+	other.Proposer = abciTypes.Validator{Address: []byte{0xcc}}
+	assert.NotEqual(seed1, blockSeed(other))
+	other = header
+	other.LastBlockHash = common.HexToHash("0x02").Bytes()
+	assert.NotEqual(seed1, blockSeed(other))
+
+	// served by the query
+	res := app1.Query(abciTypes.RequestQuery{Data: []byte(`{"method":"travis_randomSeed"}`)})
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+	var view randomSeed
+	assert.Nil(json.Unmarshal(res.Value, &view))
+	assert.Equal(seed1, view.Seed)
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	lru "github.com/hashicorp/golang-lru"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// number of senders whose failed CheckTx are counted
+const failedCheckTxSendersKept = 16384
+
+// failedCheckTxCounts counts the failed CheckTx of the new txs of each sender since
+// the last Commit. The counts are kept in an LRU, a flood of distinct senders evicts
+// the oldest ones, which then get validated again until they fail maxFailedCheckTx
+// more times.
+type failedCheckTxCounts struct {
+	counts *lru.Cache
+}
+
+func (f *failedCheckTxCounts) add(addr common.Address) {
+	if f.counts == nil {
+		f.counts, _ = lru.New(failedCheckTxSendersKept)
+	}
+	f.counts.Add(addr, f.count(addr)+1)
+}
+
+func (f *failedCheckTxCounts) count(addr common.Address) uint64 {
+	if f.counts == nil {
+		return 0
+	}
+	if v, ok := f.counts.Peek(addr); ok {
+		return v.(uint64)
+	}
+	return 0
+}
+
+func (f *failedCheckTxCounts) reset() {
+	if f.counts != nil {
+		f.counts.Purge()
+	}
+}
+
+// checkRateLimited short-circuits the new txs of a sender whose txs failed
+// CheckTx maxFailedCheckTx times since the last Commit
+func (app *EthermintApplication) checkRateLimited(tx *ethTypes.Transaction) abciTypes.ResponseCheckTx {
+	app.mu.Lock()
+	limit := app.maxFailedCheckTx
+	app.mu.Unlock()
+	if limit == 0 {
+		return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+	}
+
+	from, err := app.sender(tx)
+	if err != nil {
+		// rejected by the signature check
+		return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	if failed := app.failedCheckTx.count(from); failed >= limit {
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeRateLimited,
+			Log: fmt.Sprintf(
+				"Sender has %d failed transactions in this block, retry after the next block", failed)}
+	}
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
+
+// recordFailedCheckTx counts a failed CheckTx of a new tx against its sender.
+// A tx held for its nonce gap hasn't failed.
+func (app *EthermintApplication) recordFailedCheckTx(tx *ethTypes.Transaction, resp abciTypes.ResponseCheckTx) {
+	switch resp.Code {
+	case abciTypes.CodeTypeOK, errors.CodeTypeFutureNonce, errors.CodeTypeRateLimited:
+		return
+	}
+	from, err := app.sender(tx)
+	if err != nil {
+		return
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	if app.maxFailedCheckTx == 0 {
+		return
+	}
+	app.failedCheckTx.add(from)
+}
+
+// resetFailedCheckTx lifts the limits on Commit
+func (app *EthermintApplication) resetFailedCheckTx() {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.failedCheckTx.reset()
+}
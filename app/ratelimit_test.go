@@ -0,0 +1,79 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestFailedCheckTxRateLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	app, signer := newPendingCapTestApp(0)
+	app.logger = tmLog.NewNopLogger()
+	assert.Nil(app.setOption("max_failed_checktx", "3"))
+
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	sign := func(nonce uint64) *ethTypes.Transaction {
+		tx, err := ethTypes.SignTx(pricedTx(nonce, 1), signer, key)
+		assert.Nil(err)
+		return tx
+	}
+	failed := abciTypes.ResponseCheckTx{Code: errors.CodeTypeBaseInvalidInput}
+
+	// held txs don't count
+	app.recordFailedCheckTx(sign(10), abciTypes.ResponseCheckTx{Code: errors.CodeTypeFutureNonce})
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		app.recordFailedCheckTx(sign(nonce), failed)
+		assert.Equal(abciTypes.CodeTypeOK, app.checkRateLimited(sign(nonce+1)).Code)
+	}
+	app.recordFailedCheckTx(sign(2), failed)
+
+	// past the threshold the sender is short-circuited before any validation,
+	// the app has no backend to validate with
+	resp := app.CheckTx(sign(3), CheckTxNew)
+	assert.Equal(errors.CodeTypeRateLimited, resp.Code)
+	assert.Equal(uint64(3), app.failedCheckTx.count(crypto.PubkeyToAddress(key.PublicKey)))
+
+	// other senders aren't affected
+	tx, _ := ethTypes.SignTx(pricedTx(0, 1), signer, other)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkRateLimited(tx).Code)
+
+	// Commit resets the counts
+	app.resetFailedCheckTx()
+	assert.Equal(abciTypes.CodeTypeOK, app.checkRateLimited(sign(3)).Code)
+
+	// disabled
+	assert.Nil(app.setOption("max_failed_checktx", "0"))
+	for nonce := uint64(0); nonce < 5; nonce++ {
+		app.recordFailedCheckTx(sign(nonce), failed)
+	}
+	assert.Equal(abciTypes.CodeTypeOK, app.checkRateLimited(sign(5)).Code)
+}
+
+func TestFailedCheckTxCountsBounded(t *testing.T) {
+	assert := assert.New(t)
+
+	var f failedCheckTxCounts
+	for i := 0; i <= failedCheckTxSendersKept; i++ {
+		f.add(common.BigToAddress(big.NewInt(int64(i))))
+	}
+
+	// the counts stay bounded, the oldest sender is forgotten
+	assert.Equal(failedCheckTxSendersKept, f.counts.Len())
+	assert.Equal(uint64(0), f.count(common.BigToAddress(big.NewInt(0))))
+	assert.Equal(uint64(1), f.count(common.BigToAddress(big.NewInt(failedCheckTxSendersKept))))
+
+	f.reset()
+	assert.Equal(0, f.counts.Len())
+}
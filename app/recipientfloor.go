@@ -0,0 +1,64 @@
+package app
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// parseGasPriceFloors parses a comma separated list of address:price pairs, the
+// prices in wei; an empty value gives an empty map
+func parseGasPriceFloors(value string) (map[common.Address]*big.Int, error) {
+	floors := make(map[common.Address]*big.Int)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		parts := strings.Split(pair, ":")
+		if len(parts) != 2 || !common.IsHexAddress(parts[0]) {
+			return nil, fmt.Errorf("invalid gas price floor: %s", pair)
+		}
+		price, ok := new(big.Int).SetString(parts[1], 10)
+		if !ok || price.Sign() < 0 {
+			return nil, fmt.Errorf("invalid gas price floor: %s", pair)
+		}
+		floors[common.HexToAddress(parts[0])] = price
+	}
+	return floors, nil
+}
+
+// setRecipientGasPriceFloors replaces the gas price floors of the recipients
+func (app *EthermintApplication) setRecipientGasPriceFloors(floors map[common.Address]*big.Int) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.recipientGasPriceFloors = floors
+}
+
+// checkRecipientGasPrice rejects a tx to a recipient with its own gas price floor
+// when the gas price is below it. Unlike the minimum gas price, the floor is
+// demanded from the first tx of a pair as well.
+func (app *EthermintApplication) checkRecipientGasPrice(tx *ethTypes.Transaction) abciTypes.ResponseCheckTx {
+	if tx.To() == nil {
+		return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+	}
+
+	app.mu.Lock()
+	floor, ok := app.recipientGasPriceFloors[*tx.To()]
+	app.mu.Unlock()
+
+	if ok && tx.GasPrice().Cmp(floor) < 0 {
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeLowGasPriceErr,
+			Log: fmt.Sprintf(
+				"Gas price %s is below the floor %s of recipient %s",
+				tx.GasPrice(), floor, tx.To().Hex())}
+	}
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestRecipientGasPriceFloor(t *testing.T) {
+	assert := assert.New(t)
+
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	router := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	other := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	app := &EthermintApplication{
+		lowPriceTransactions: make(map[FromTo]*lowPriceTx),
+		checkFailedCount:     make(map[common.Address]uint64),
+	}
+	assert.Nil(app.setOption("recipient_gas_price_floor", router.Hex()+":10000000000"))
+
+	// 3 gwei passes the global floor but not the one of the router
+	toRouter := ethTypes.NewTransaction(0, router, big.NewInt(0), 21000, big.NewInt(3e9), nil)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkLowPrice(from, toRouter, app.now()).Code)
+	assert.Equal(errors.CodeLowGasPriceErr, app.checkRecipientGasPrice(toRouter).Code)
+
+	toOther := ethTypes.NewTransaction(0, other, big.NewInt(0), 21000, big.NewInt(3e9), nil)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkRecipientGasPrice(toOther).Code)
+	creation := ethTypes.NewContractCreation(0, big.NewInt(0), 53000, big.NewInt(3e9), nil)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkRecipientGasPrice(creation).Code)
+
+	enough := ethTypes.NewTransaction(0, router, big.NewInt(0), 21000, big.NewInt(1e10), nil)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkRecipientGasPrice(enough).Code)
+
+	// an empty value clears the floors
+	assert.Nil(app.setOption("recipient_gas_price_floor", ""))
+	assert.Equal(abciTypes.CodeTypeOK, app.checkRecipientGasPrice(toRouter).Code)
+
+	for _, value := range []string{"0x12:1", router.Hex(), router.Hex() + ":-1", router.Hex() + ":0x10"} {
+		assert.NotNil(app.setOption("recipient_gas_price_floor", value), value)
+	}
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+
+	"github.com/CyberMiles/travis/utils"
+)
+
+// buffered side chain events, the blockchain blocks on a full buffer
+const reorgEventBuffer = 16
+
+// watchReorgs rebuilds the CheckTx state from the canonical head, obtained with
+// latest, whenever blocks are moved to a side chain. The blocks come from
+// tendermint one at a time, a side chain event only follows a reorg of the
+// backend. It returns once the subscription ends.
+func (app *EthermintApplication) watchReorgs(events <-chan core.ChainSideEvent, sub event.Subscription,
+	latest func() (*state.ManagedState, error)) {
+
+	// the events of a reorg are coalesced, and drained while Commit holds up the
+	// rebuild, so that the blockchain is never blocked on them
+	reorged := make(chan struct{}, 1)
+	go func() {
+		for range reorged {
+			if err := app.handleReorg(latest); err != nil {
+				app.logger.Error("Error rebuilding the CheckTx state after a reorg", "err", err) // nolint: errcheck
+			}
+		}
+	}()
+	defer close(reorged)
+
+	for {
+		select {
+		case ev := <-events:
+			// nolint: errcheck
+			app.logger.Info("Chain reorg, rebuilding the CheckTx state",
+				"orphaned", ev.Block.NumberU64(), "hash", ev.Block.Hash().Hex())
+			select {
+			case reorged <- struct{}{}:
+			default:
+			}
+		case <-sub.Err():
+			return
+		}
+	}
+}
+
+// handleReorg rebuilds the CheckTx state from the canonical head, unless the
+// application is closed
+func (app *EthermintApplication) handleReorg(latest func() (*state.ManagedState, error)) error {
+	app.commitMtx.Lock()
+	defer app.commitMtx.Unlock()
+
+	if app.closed {
+		return nil
+	}
+	managed, err := latest()
+	if err != nil {
+		return err
+	}
+	app.invalidateCheckTxState(managed.StateDB)
+	return nil
+}
+
+// invalidateCheckTxState makes head the CheckTx state and drops all the mempool
+// bookkeeping, which may refer to the orphaned branch. Tendermint rechecks its
+// mempool against the new state after the next Commit.
+func (app *EthermintApplication) invalidateCheckTxState(head *state.StateDB) {
+	app.checkTxStateMtx.Lock()
+	defer app.checkTxStateMtx.Unlock()
+
+	app.checkTxState = head
+	utils.NonceCheckedTx.Reset()
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.pool = newTxPool(head.Copy())
+	app.lowPriceTransactions = make(map[FromTo]*lowPriceTx)
+	app.checkFailedCount = make(map[common.Address]uint64)
+	app.failedCheckTx.reset()
+	app.evicted = make(map[common.Hash]*pendingTx)
+	app.futureTxs = make(map[common.Address][]*ethTypes.Transaction)
+	app.pendingBySender = make(map[common.Address]map[common.Hash]uint64)
+	app.underfunded.reset()
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+
+	"github.com/CyberMiles/travis/utils"
+)
+
+func TestReorgRebuildsCheckTxState(t *testing.T) {
+	assert := assert.New(t)
+
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	orphaned := newTestState()
+	orphaned.SetBalance(from, big.NewInt(1))
+	head := newTestState()
+	head.SetBalance(from, big.NewInt(2))
+
+	tx := pricedTx(0, 1)
+	app := &EthermintApplication{
+		logger:               tmLog.NewNopLogger(),
+		checkTxState:         orphaned,
+		pool:                 newTxPool(orphaned.Copy()),
+		lowPriceTransactions: map[FromTo]*lowPriceTx{{from: from}: {tx: tx}},
+		checkFailedCount:     map[common.Address]uint64{from: 1},
+		futureTxs:            map[common.Address][]*ethTypes.Transaction{from: {tx}},
+		pendingBySender:      map[common.Address]map[common.Hash]uint64{from: {tx.Hash(): 0}},
+	}
+	utils.NonceCheckedTx.Add(tx.Hash())
+
+	events := make(chan core.ChainSideEvent)
+	sub := event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+	defer sub.Unsubscribe()
+	go app.watchReorgs(events, sub, func() (*state.ManagedState, error) {
+		return state.ManageState(head), nil
+	})
+
+	events <- core.ChainSideEvent{Block: ethTypes.NewBlockWithHeader(&ethTypes.Header{Number: big.NewInt(5)})}
+	rebuilt := func() bool {
+		app.checkTxStateMtx.Lock()
+		defer app.checkTxStateMtx.Unlock()
+		return app.checkTxState.GetBalance(from).Cmp(big.NewInt(2)) == 0
+	}
+	for i := 0; i < 100 && !rebuilt(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.True(rebuilt())
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	assert.Equal(big.NewInt(2), app.pool.base.GetBalance(from))
+	assert.Empty(app.lowPriceTransactions)
+	assert.Empty(app.checkFailedCount)
+	assert.Empty(app.futureTxs)
+	assert.Empty(app.pendingBySender)
+	assert.False(utils.NonceCheckedTx.Contains(tx.Hash()))
+}
+
+func TestReorgAfterClose(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{checkTxState: newTestState(), closed: true}
+	before := app.checkTxState
+	assert.Nil(app.handleReorg(func() (*state.ManagedState, error) {
+		t.Error("the head of a closed application is read")
+		return state.ManageState(newTestState()), nil
+	}))
+	assert.True(before == app.checkTxState)
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+)
+
+// ReplayCheck checks a batch of txs in order against a copy of the CheckTx state,
+// each admitted tx updating the copy like CheckTx does, and returns the response
+// of each tx. Neither the CheckTx state nor the mempool bookkeeping are touched,
+// the same batch replayed on the same state gets the same responses. It's meant
+// for diagnosing how the order of the txs affects their admission. As in CheckTx,
+// the txs already admitted to the mempool skip the nonce check.
+// #unstable
+func (app *EthermintApplication) ReplayCheck(txs []*ethTypes.Transaction) []abciTypes.ResponseCheckTx {
+	app.checkTxStateMtx.Lock()
+	checkTxState := app.checkTxState.Copy()
+	app.checkTxStateMtx.Unlock()
+
+	return replayCheck(checkTxState, txs, app.validateTxState)
+}
+
+// replayCheck validates the txs in order on checkTxState, applying the admitted ones
+func replayCheck(checkTxState *state.StateDB, txs []*ethTypes.Transaction,
+	validate txValidator) []abciTypes.ResponseCheckTx {
+
+	responses := make([]abciTypes.ResponseCheckTx, 0, len(txs))
+	for _, tx := range txs {
+		from, payer, nonce, resp := validate(tx, checkTxState)
+		if resp.Code == abciTypes.CodeTypeOK {
+			resp = applySponsoredTx(checkTxState, from, payer, nonce, tx, CheckTxNew)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
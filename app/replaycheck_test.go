@@ -0,0 +1,79 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// replayValidator checks the nonce and the balance of the sender, like CheckTx
+func replayValidator(signer ethTypes.Signer) txValidator {
+	return func(tx *ethTypes.Transaction,
+		st *state.StateDB) (common.Address, common.Address, uint64, abciTypes.ResponseCheckTx) {
+
+		from, _ := ethTypes.Sender(signer, tx)
+		if st.GetNonce(from) != tx.Nonce() {
+			return from, common.Address{}, st.GetNonce(from), abciTypes.ResponseCheckTx{Code: errors.CodeTypeBadNonce}
+		}
+		if resp := checkBalance(st, from, tx); resp.Code != abciTypes.CodeTypeOK {
+			return from, common.Address{}, tx.Nonce(), resp
+		}
+		return from, from, tx.Nonce(), abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+	}
+}
+
+func codes(responses []abciTypes.ResponseCheckTx) []uint32 {
+	var c []uint32
+	for _, resp := range responses {
+		c = append(c, resp.Code)
+	}
+	return c
+}
+
+func TestReplayCheckOrderSensitivity(t *testing.T) {
+	assert := assert.New(t)
+
+	signer := ethTypes.HomesteadSigner{}
+	keyA, _ := crypto.GenerateKey()
+	keyB, _ := crypto.GenerateKey()
+	fromA, fromB := crypto.PubkeyToAddress(keyA.PublicKey), crypto.PubkeyToAddress(keyB.PublicKey)
+	validate := replayValidator(signer)
+	ok, badNonce, noFunds := abciTypes.CodeTypeOK, errors.CodeTypeBadNonce, errors.CodeTypeBaseInvalidInput
+
+	// A funds B for a transfer of its own
+	base := newTestState()
+	base.AddBalance(fromA, big.NewInt(21000+1000000+21001))
+	fundB, _ := ethTypes.SignTx(ethTypes.NewTransaction(0, fromB, big.NewInt(1000000), 21000, big.NewInt(1), nil), signer, keyA)
+	spendB, _ := ethTypes.SignTx(ethTypes.NewTransaction(0, fromA, big.NewInt(1), 21000, big.NewInt(1), nil), signer, keyB)
+	nextA, _ := ethTypes.SignTx(pricedTx(1, 1), signer, keyA)
+
+	// the nonces must be increasing
+	assert.Equal([]uint32{ok, ok}, codes(replayCheck(base.Copy(), []*ethTypes.Transaction{fundB, nextA}, validate)))
+	assert.Equal([]uint32{badNonce, ok}, codes(replayCheck(base.Copy(), []*ethTypes.Transaction{nextA, fundB}, validate)))
+
+	// B spends what A sent it only once A's tx is admitted
+	assert.Equal([]uint32{ok, ok}, codes(replayCheck(base.Copy(), []*ethTypes.Transaction{fundB, spendB}, validate)))
+	assert.Equal([]uint32{noFunds, ok}, codes(replayCheck(base.Copy(), []*ethTypes.Transaction{spendB, fundB}, validate)))
+
+	// the state is only mutated by the admitted txs
+	st := base.Copy()
+	replayCheck(st, []*ethTypes.Transaction{spendB, fundB}, validate)
+	assert.Equal(uint64(1), st.GetNonce(fromA))
+	assert.Equal(uint64(0), st.GetNonce(fromB))
+	assert.Equal(big.NewInt(1000000), st.GetBalance(fromB))
+
+	// replaying the same batch on the same state is deterministic
+	first := replayCheck(base.Copy(), []*ethTypes.Transaction{spendB, fundB, nextA}, validate)
+	second := replayCheck(base.Copy(), []*ethTypes.Transaction{spendB, fundB, nextA}, validate)
+	assert.Equal([]uint32{noFunds, ok, ok}, codes(first))
+	assert.Equal(first, second)
+}
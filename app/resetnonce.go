@@ -0,0 +1,105 @@
+package app
+
+import (
+	goerr "errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/CyberMiles/travis/utils"
+)
+
+// errAdminQueryDisabled is returned by the admin queries unless the admin_queries
+// option is set
+var errAdminQueryDisabled = goerr.New("admin queries are disabled")
+
+// resetNonceResult is the result of the travis_resetNonce query
+type resetNonceResult struct {
+	Address common.Address `json:"address"`
+	Nonce   hexutil.Uint64 `json:"nonce"`
+}
+
+// ResetSenderNonce forgets the nonce tracking of the sender in CheckTx: the nonces
+// skipped by its rejected low price txs, its low price txs, its txs held for a
+// nonce gap, its pending tx count and its txs in the pool. The effects of those
+// txs on the other accounts are rolled back and the nonce and balance of the
+// sender in the CheckTx state are reset to the committed ones, the nonce being
+// returned, so that its next txs are checked against the committed state. Its txs
+// left in the mempool get their nonce checked again on their recheck.
+// It's meant for recovering a sender stuck on a nonce.
+// #unstable
+func (app *EthermintApplication) ResetSenderNonce(addr common.Address) uint64 {
+	app.checkTxStateMtx.Lock()
+	defer app.checkTxStateMtx.Unlock()
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	delete(app.checkFailedCount, addr)
+	for ft := range app.lowPriceTransactions {
+		if ft.from == addr {
+			delete(app.lowPriceTransactions, ft)
+		}
+	}
+	delete(app.futureTxs, addr)
+	delete(app.pendingBySender, addr)
+
+	var (
+		nonce   uint64
+		balance = new(big.Int)
+		removed []*pendingTx
+	)
+	if app.pool != nil {
+		removed = app.pool.removeSender(addr)
+		if app.pool.base != nil {
+			nonce = app.pool.base.GetNonce(addr)
+			balance.Set(app.pool.base.GetBalance(addr))
+		}
+	}
+	for _, ptx := range removed {
+		utils.NonceCheckedTx.Remove(ptx.tx.Hash())
+	}
+	if app.checkTxState != nil {
+		for _, ptx := range removed {
+			if ptx.payer != (common.Address{}) {
+				app.checkTxState.AddBalance(ptx.payer, gasCost(ptx.tx))
+			}
+			if to := ptx.tx.To(); to != nil {
+				// the recipient may have spent the credit already
+				credit := ptx.tx.Value()
+				if received := app.checkTxState.GetBalance(*to); received.Cmp(credit) < 0 {
+					credit = received
+				}
+				app.checkTxState.SubBalance(*to, credit)
+			}
+		}
+		app.checkTxState.SetNonce(addr, nonce)
+		app.checkTxState.SetBalance(addr, balance)
+	}
+	app.logger.Info("Reset the nonce tracking of a sender", // nolint: errcheck
+		"address", addr.Hex(), "nonce", nonce)
+	return nonce
+}
+
+// resetNonceQuery serves travis_resetNonce, only when the admin queries are enabled
+func (app *EthermintApplication) resetNonceQuery(params []interface{}) (*resetNonceResult, error) {
+	app.mu.Lock()
+	enabled := app.adminQueries
+	app.mu.Unlock()
+
+	if !enabled {
+		return nil, errAdminQueryDisabled
+	}
+	if len(params) != 1 {
+		return nil, fmt.Errorf("expected 1 param, got %d", len(params))
+	}
+	hex, ok := params[0].(string)
+	if !ok || !common.IsHexAddress(hex) {
+		return nil, fmt.Errorf("invalid address: %v", params[0])
+	}
+	addr := common.HexToAddress(hex)
+	nonce := app.ResetSenderNonce(addr)
+	return &resetNonceResult{Address: addr, Nonce: hexutil.Uint64(nonce)}, nil
+}
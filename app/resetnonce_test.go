@@ -0,0 +1,134 @@
+package app
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestResetSenderNonce(t *testing.T) {
+	assert := assert.New(t)
+
+	stuck := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	other := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+
+	base := newTestState()
+	base.SetNonce(stuck, 3)
+	checkTxState := base.Copy()
+	checkTxState.SetNonce(stuck, 7)
+	checkTxState.SetNonce(other, 2)
+
+	app := &EthermintApplication{
+		logger:               tmLog.NewNopLogger(),
+		checkTxState:         checkTxState,
+		pool:                 newTxPool(base),
+		lowPriceTransactions: make(map[FromTo]*lowPriceTx),
+		checkFailedCount:     map[common.Address]uint64{stuck: 2, other: 1},
+		futureTxs: map[common.Address][]*ethTypes.Transaction{
+			stuck: {pricedTx(9, 1)},
+			other: {pricedTx(4, 1)},
+		},
+		pendingBySender: map[common.Address]map[common.Hash]uint64{
+			stuck: {pricedTx(3, 1).Hash(): 3},
+			other: {pricedTx(1, 1).Hash(): 1},
+		},
+	}
+	app.lowPriceTransactions[FromTo{from: stuck, to: to}] = &lowPriceTx{tx: pricedTx(5, 1), added: time.Now()}
+	app.lowPriceTransactions[FromTo{from: other, to: to}] = &lowPriceTx{tx: pricedTx(1, 1), added: time.Now()}
+
+	assert.Equal(uint64(3), app.ResetSenderNonce(stuck))
+
+	assert.NotContains(app.checkFailedCount, stuck)
+	assert.NotContains(app.lowPriceTransactions, FromTo{from: stuck, to: to})
+	assert.NotContains(app.futureTxs, stuck)
+	assert.NotContains(app.pendingBySender, stuck)
+	assert.Equal(uint64(3), app.checkTxState.GetNonce(stuck))
+
+	// the other senders are left alone
+	assert.Contains(app.checkFailedCount, other)
+	assert.Contains(app.lowPriceTransactions, FromTo{from: other, to: to})
+	assert.Contains(app.futureTxs, other)
+	assert.Contains(app.pendingBySender, other)
+	assert.Equal(uint64(2), app.checkTxState.GetNonce(other))
+}
+
+func TestResetNonceQueryAdminOnly(t *testing.T) {
+	assert := assert.New(t)
+
+	stuck := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	base := newTestState()
+	base.SetNonce(stuck, 3)
+	checkTxState := base.Copy()
+	checkTxState.SetNonce(stuck, 7)
+	app := &EthermintApplication{
+		logger:       tmLog.NewNopLogger(),
+		checkTxState: checkTxState,
+		pool:         newTxPool(base),
+	}
+	query := func() abciTypes.ResponseQuery {
+		data, _ := json.Marshal(jsonRequest{Method: "travis_resetNonce", Params: []interface{}{stuck.Hex()}})
+		return app.Query(abciTypes.RequestQuery{Data: data})
+	}
+
+	res := query()
+	assert.Equal(errors.CodeTypeUnauthorized, res.Code)
+	assert.Equal(uint64(7), app.checkTxState.GetNonce(stuck))
+
+	assert.Nil(app.setOption("admin_queries", "true"))
+	res = query()
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+	var result resetNonceResult
+	assert.Nil(json.Unmarshal(res.Value, &result))
+	assert.Equal(stuck, result.Address)
+	assert.Equal(uint64(3), uint64(result.Nonce))
+	assert.Equal(uint64(3), app.checkTxState.GetNonce(stuck))
+}
+
+func TestResetSenderNonceDropsPoolTxs(t *testing.T) {
+	assert := assert.New(t)
+
+	stuck := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	other := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+
+	base := newTestState()
+	base.AddBalance(stuck, big.NewInt(1000000))
+	base.AddBalance(other, big.NewInt(1000000))
+	checkTxState := base.Copy()
+	app := &EthermintApplication{
+		logger:       tmLog.NewNopLogger(),
+		checkTxState: checkTxState,
+		pool:         newTxPool(base),
+	}
+	checkTx := func(from common.Address, tx *ethTypes.Transaction) {
+		assert.Equal(abciTypes.CodeTypeOK, applySpeculativeTx(checkTxState, from, tx.Nonce(), tx, CheckTxNew).Code)
+		app.recordPending(from, tx)
+	}
+	checkTx(stuck, pricedTx(0, 1))
+	checkTx(other, pricedTx(0, 2))
+	checkTx(stuck, pricedTx(1, 3))
+
+	assert.Equal(uint64(0), app.ResetSenderNonce(stuck))
+
+	// the txs of the sender leave the pool and the CheckTx state
+	assert.NotContains(app.pool.bySender, stuck)
+	if assert.Len(app.pool.txs, 1) {
+		assert.Equal(other, app.pool.txs[0].from)
+	}
+	assert.Equal(1, app.pool.priced.Len())
+	assert.Equal(uint64(0), checkTxState.GetNonce(stuck))
+	assert.Equal(big.NewInt(1000000), checkTxState.GetBalance(stuck))
+	assert.Equal(big.NewInt(1), checkTxState.GetBalance(to))
+	assert.Empty(app.AuditCheckTxState())
+}
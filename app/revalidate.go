@@ -0,0 +1,93 @@
+package app
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/utils"
+)
+
+// txValidator checks a tx against a state, like validateTxState
+type txValidator func(tx *ethTypes.Transaction,
+	currentState *state.StateDB) (from, payer common.Address, nonce uint64, resp abciTypes.ResponseCheckTx)
+
+// sponsoredValidator is a txValidator with the gas of a delegated fee tx paid by
+// its payer, like validateSponsoredTxState
+type sponsoredValidator func(tx *ethTypes.Transaction, delegatedPayer common.Address,
+	currentState *state.StateDB) (from, payer common.Address, nonce uint64, resp abciTypes.ResponseCheckTx)
+
+// RevalidateMempool re-runs the checks of the txs admitted or rechecked since the
+// last Commit against a fresh CheckTx state, e.g. around the activation of a fork changing the
+// validity rules. The txs which now fail are evicted, the mempool drops them on
+// their next recheck, and their hashes are returned.
+// #unstable
+func (app *EthermintApplication) RevalidateMempool() []common.Hash {
+	app.checkTxStateMtx.Lock()
+	defer app.checkTxStateMtx.Unlock()
+
+	app.mu.Lock()
+	pool := app.pool
+	app.mu.Unlock()
+
+	// the nonces are checked again, a tx following an evicted one is evicted too
+	for _, ptx := range pool.txs {
+		utils.NonceCheckedTx.Remove(ptx.tx.Hash())
+	}
+	checkTxState, kept, evicted := revalidatePool(pool, app.validateSponsoredTxState)
+	for _, ptx := range kept.txs {
+		utils.NonceCheckedTx.Add(ptx.tx.Hash())
+	}
+
+	evictedTxs := make(map[common.Hash]bool, len(evicted))
+	for _, hash := range evicted {
+		evictedTxs[hash] = true
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.checkTxState = checkTxState
+	app.pool = kept
+	for _, ptx := range pool.txs {
+		if evictedTxs[ptx.tx.Hash()] {
+			app.evicted[ptx.tx.Hash()] = ptx
+		}
+	}
+	if len(evicted) > 0 {
+		app.logger.Info("Evicted invalidated txs from the mempool", "count", len(evicted)) // nolint: errcheck
+	}
+	return evicted
+}
+
+// revalidatePool replays the txs of the pool in order on a copy of its base, the
+// delegated fee txs being checked with their payer. The txs evicted by a higher
+// priced one are replayed too, the txs of their sender were checked against them.
+// It returns the resulting CheckTx state, the pool of the txs still valid and the
+// hashes of the others.
+func revalidatePool(pool *txPool, validate sponsoredValidator) (*state.StateDB, *txPool, []common.Hash) {
+
+	checkTxState := pool.base.Copy()
+	kept := newTxPool(pool.base)
+	var evicted []common.Hash
+
+	for _, ptx := range pool.txs {
+		from, payer, nonce, resp := validate(ptx.tx, ptx.payer, checkTxState)
+		if resp.Code == abciTypes.CodeTypeOK {
+			resp = applySponsoredTx(checkTxState, from, payer, nonce, ptx.tx, CheckTxNew)
+		}
+		switch {
+		case ptx.index < 0:
+			// evicted by a higher priced tx already, its debits stay in the CheckTx
+			// state until Commit like in recordPending
+			if resp.Code == abciTypes.CodeTypeOK {
+				kept.addEvicted(from, ptx.payer, ptx.tx)
+			}
+		case resp.Code != abciTypes.CodeTypeOK:
+			evicted = append(evicted, ptx.tx.Hash())
+		default:
+			kept.addDelegated(from, ptx.payer, ptx.tx)
+		}
+	}
+	return checkTxState, kept, evicted
+}
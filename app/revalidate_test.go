@@ -0,0 +1,124 @@
+package app
+
+import (
+	"container/heap"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// unsponsored adapts a txValidator to the pools without delegated fee txs
+func unsponsored(validate txValidator) sponsoredValidator {
+	return func(tx *ethTypes.Transaction, _ common.Address,
+		st *state.StateDB) (common.Address, common.Address, uint64, abciTypes.ResponseCheckTx) {
+
+		return validate(tx, st)
+	}
+}
+
+func TestRevalidatePoolAfterFork(t *testing.T) {
+	assert := assert.New(t)
+
+	signer := ethTypes.HomesteadSigner{}
+	keyA, _ := crypto.GenerateKey()
+	keyB, _ := crypto.GenerateKey()
+	fromA, fromB := crypto.PubkeyToAddress(keyA.PublicKey), crypto.PubkeyToAddress(keyB.PublicKey)
+
+	// a contract creation paying the frontier intrinsic gas only
+	create, _ := ethTypes.SignTx(ethTypes.NewContractCreation(0, big.NewInt(0), 30000, big.NewInt(1), nil), signer, keyA)
+	nextA, _ := ethTypes.SignTx(pricedTx(1, 1), signer, keyA)
+	transferB, _ := ethTypes.SignTx(pricedTx(0, 1), signer, keyB)
+
+	config := &params.ChainConfig{HomesteadBlock: big.NewInt(10)}
+	height := big.NewInt(5)
+	validate := func(tx *ethTypes.Transaction,
+		st *state.StateDB) (common.Address, common.Address, uint64, abciTypes.ResponseCheckTx) {
+
+		from, _ := ethTypes.Sender(signer, tx)
+		if st.GetNonce(from) != tx.Nonce() {
+			return from, common.Address{}, st.GetNonce(from), abciTypes.ResponseCheckTx{Code: errors.CodeTypeBadNonce}
+		}
+		gas, _ := intrinsicGas(tx.Data(), tx.To() == nil, config, height)
+		if tx.Gas() < gas {
+			return common.Address{}, common.Address{}, 0, abciTypes.ResponseCheckTx{Code: errors.CodeTypeBaseInvalidInput}
+		}
+		return from, from, tx.Nonce(), abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+	}
+
+	base := newTestState()
+	base.AddBalance(fromA, big.NewInt(1000000000))
+	base.AddBalance(fromB, big.NewInt(1000000000))
+	pool := newTxPool(base)
+	pool.add(fromA, create)
+	pool.add(fromA, nextA)
+	pool.add(fromB, transferB)
+
+	// before the fork every tx is still valid
+	_, kept, evicted := revalidatePool(pool, unsponsored(validate))
+	assert.Empty(evicted)
+	assert.Len(kept.txs, 3)
+
+	// homestead raises the intrinsic gas of the contract creations, the following
+	// tx of the same sender is left with a nonce gap
+	height = big.NewInt(10)
+	checkTxState, kept, evicted := revalidatePool(pool, unsponsored(validate))
+	assert.Equal([]common.Hash{create.Hash(), nextA.Hash()}, evicted)
+	assert.Len(kept.txs, 1)
+	assert.Equal(transferB.Hash(), kept.txs[0].tx.Hash())
+	assert.Equal(uint64(0), checkTxState.GetNonce(fromA))
+	assert.Equal(uint64(1), checkTxState.GetNonce(fromB))
+	// the base is left untouched
+	assert.Equal(uint64(0), base.GetNonce(fromB))
+}
+
+func TestRevalidatePoolReplaysEvicted(t *testing.T) {
+	assert := assert.New(t)
+
+	signer := ethTypes.HomesteadSigner{}
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	cheap, _ := ethTypes.SignTx(pricedTx(0, 1), signer, key)
+	rich, _ := ethTypes.SignTx(pricedTx(1, 2), signer, key)
+
+	validate := func(tx *ethTypes.Transaction,
+		st *state.StateDB) (common.Address, common.Address, uint64, abciTypes.ResponseCheckTx) {
+
+		if st.GetNonce(from) != tx.Nonce() {
+			return from, common.Address{}, st.GetNonce(from), abciTypes.ResponseCheckTx{Code: errors.CodeTypeBadNonce}
+		}
+		return from, from, tx.Nonce(), abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+	}
+
+	base := newTestState()
+	base.AddBalance(from, big.NewInt(1000000000))
+	pool := newTxPool(base)
+	pool.add(from, cheap)
+	pool.add(from, rich)
+	// the cheap tx is evicted by a full mempool
+	heap.Pop(&pool.priced)
+
+	// its debits are replayed, the following tx of its sender was checked against them
+	checkTxState, kept, evicted := revalidatePool(pool, unsponsored(validate))
+	assert.Empty(evicted)
+	assert.Equal(uint64(2), checkTxState.GetNonce(from))
+	assert.Equal(new(big.Int).Sub(big.NewInt(1000000000), new(big.Int).Add(cheap.Cost(), rich.Cost())),
+		checkTxState.GetBalance(from))
+	assert.Len(kept.txs, 2)
+	assert.Equal(1, kept.priced.Len())
+	assert.Equal(rich, kept.priced[0].tx)
+
+	// and again on the next revalidation
+	checkTxState, _, evicted = revalidatePool(kept, unsponsored(validate))
+	assert.Empty(evicted)
+	assert.Equal(uint64(2), checkTxState.GetNonce(from))
+}
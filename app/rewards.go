@@ -0,0 +1,80 @@
+package app
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// validatorReward is the amount credited to an account in a block
+type validatorReward struct {
+	Address common.Address `json:"address"`
+	Amount  *hexutil.Big   `json:"amount"`
+}
+
+// blockRewards are the rewards distributed in a block
+type blockRewards struct {
+	Height  int64             `json:"height"`
+	Total   *hexutil.Big      `json:"total"`
+	Rewards []validatorReward `json:"rewards"`
+}
+
+// addRewards adds the rewards distributed in a block to the running total
+func (app *EthermintApplication) addRewards(rewards map[common.Address]*big.Int) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	for _, r := range rewards {
+		if r != nil {
+			app.totalRewards.Add(app.totalRewards, r)
+		}
+	}
+}
+
+// TotalRewardsDistributed returns the rewards distributed since the node started
+// #unstable
+func (app *EthermintApplication) TotalRewardsDistributed() *big.Int {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	return new(big.Int).Set(app.totalRewards)
+}
+
+// newBlockRewards breaks down the rewards distributed at height, sorted by address
+func newBlockRewards(height int64, rewards map[common.Address]*big.Int) *blockRewards {
+	total := new(big.Int)
+	breakdown := make([]validatorReward, 0, len(rewards))
+	for addr, r := range rewards {
+		if r == nil {
+			continue
+		}
+		total.Add(total, r)
+		breakdown = append(breakdown, validatorReward{Address: addr, Amount: (*hexutil.Big)(new(big.Int).Set(r))})
+	}
+	sort.Slice(breakdown, func(i, j int) bool {
+		return bytes.Compare(breakdown[i].Address[:], breakdown[j].Address[:]) < 0
+	})
+	return &blockRewards{Height: height, Total: (*hexutil.Big)(total), Rewards: breakdown}
+}
+
+// recordLastRewards keeps the breakdown of the rewards distributed in the block
+// ending at height, served by the travis_lastRewards query
+func (app *EthermintApplication) recordLastRewards(height int64, rewards map[common.Address]*big.Int) {
+	last := newBlockRewards(height, rewards)
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.lastRewards = last
+}
+
+// lastBlockRewards returns the rewards distributed in the last ended block, nil
+// before the first one
+func (app *EthermintApplication) lastBlockRewards() *blockRewards {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	return app.lastRewards
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestTotalRewardsDistributed(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{totalRewards: new(big.Int)}
+	v1 := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	v2 := common.HexToAddress("0x2000000000000000000000000000000000000002")
+
+	app.addRewards(map[common.Address]*big.Int{v1: big.NewInt(100)})
+	app.addRewards(map[common.Address]*big.Int{v1: big.NewInt(50), v2: big.NewInt(25)})
+	app.addRewards(nil)
+	assert.Equal(big.NewInt(175), app.TotalRewardsDistributed())
+
+	// the returned value is a copy
+	app.TotalRewardsDistributed().SetInt64(0)
+	assert.Equal(big.NewInt(175), app.TotalRewardsDistributed())
+}
+
+func TestLastRewards(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{totalRewards: new(big.Int)}
+	assert.Nil(app.lastBlockRewards())
+
+	// the validators are paid their power times 1e9 wei
+	powers := map[common.Address]int64{
+		common.HexToAddress("0x3000000000000000000000000000000000000003"): 30,
+		common.HexToAddress("0x1000000000000000000000000000000000000001"): 10,
+		common.HexToAddress("0x2000000000000000000000000000000000000002"): 25,
+	}
+	rewards := make(map[common.Address]*big.Int)
+	for addr, power := range powers {
+		rewards[addr] = new(big.Int).Mul(big.NewInt(power), big.NewInt(1e9))
+	}
+	app.addRewards(rewards)
+	app.recordLastRewards(7, rewards)
+
+	last := app.lastBlockRewards()
+	assert.Equal(int64(7), last.Height)
+	assert.Len(last.Rewards, 3)
+	sum := new(big.Int)
+	for i, r := range last.Rewards {
+		assert.Equal(rewards[r.Address], r.Amount.ToInt())
+		sum.Add(sum, r.Amount.ToInt())
+		if i > 0 {
+			assert.True(last.Rewards[i-1].Address.Big().Cmp(r.Address.Big()) < 0)
+		}
+	}
+	assert.Equal(last.Total.ToInt(), sum)
+	assert.Equal(big.NewInt(65e9), sum)
+	assert.Equal(app.TotalRewardsDistributed(), sum)
+
+	// the breakdown doesn't alias the rewards of the backend
+	rewards[common.HexToAddress("0x1000000000000000000000000000000000000001")].SetInt64(0)
+	assert.Equal(big.NewInt(10e9), last.Rewards[0].Amount.ToInt())
+
+	// a block without a reward strategy distributes nothing
+	app.recordLastRewards(8, nil)
+	assert.Equal(int64(8), app.lastBlockRewards().Height)
+	assert.Empty(app.lastBlockRewards().Rewards)
+	assert.Equal(int64(0), app.lastBlockRewards().Total.ToInt().Int64())
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultRPCAllow are the read-only methods Query forwards when no allowlist is
+// configured. The eth namespace holds methods signing or sending with the keys of
+// the node, e.g. eth_sendTransaction and eth_sign, so they're listed one by one,
+// keeping those and the admin, debug, personal and miner namespaces out of reach.
+var defaultRPCAllow = []string{
+	"eth_blockNumber",
+	"eth_call",
+	"eth_estimateGas",
+	"eth_gasPrice",
+	"eth_getBalance",
+	"eth_getBlockByHash",
+	"eth_getBlockByNumber",
+	"eth_getBlockTransactionCountByHash",
+	"eth_getBlockTransactionCountByNumber",
+	"eth_getCode",
+	"eth_getLogs",
+	"eth_getStorageAt",
+	"eth_getTransactionByBlockHashAndIndex",
+	"eth_getTransactionByBlockNumberAndIndex",
+	"eth_getTransactionByHash",
+	"eth_getTransactionCount",
+	"eth_getTransactionReceipt",
+	"eth_protocolVersion",
+	"eth_syncing",
+	"net_listening",
+	"net_peerCount",
+	"net_version",
+	"web3_clientVersion",
+	"web3_sha3",
+}
+
+// rpcMethodFilter restricts the methods Query forwards to the ethereum rpc client.
+// A pattern is either a method name or a prefix ending with *, e.g. "eth_*" for
+// the eth namespace. The denied patterns win over the allowed ones.
+type rpcMethodFilter struct {
+	// nil allows defaultRPCAllow
+	allow []string
+	deny  []string
+}
+
+func (f rpcMethodFilter) allowed(method string) bool {
+	if matchMethod(f.deny, method) {
+		return false
+	}
+	allow := f.allow
+	if allow == nil {
+		allow = defaultRPCAllow
+	}
+	return matchMethod(allow, method)
+}
+
+// matchMethod tells whether the method matches any of the patterns
+func matchMethod(patterns []string, method string) bool {
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "*") {
+			if strings.HasPrefix(method, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+		} else if pattern == method {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMethodList parses a comma separated list of rpc method patterns; an empty
+// value gives a nil list
+func parseMethodList(value string) ([]string, error) {
+	var methods []string
+	for _, method := range strings.Split(value, ",") {
+		method = strings.TrimSpace(method)
+		if method == "" {
+			continue
+		}
+		if strings.ContainsAny(method, " \t\n") || strings.Contains(strings.TrimSuffix(method, "*"), "*") {
+			return nil, fmt.Errorf("invalid rpc method: %s", method)
+		}
+		methods = append(methods, method)
+	}
+	return methods, nil
+}
+
+// setRPCAllowMethods restricts the forwarded methods to the given patterns; an
+// empty list restores the default allowlist
+func (app *EthermintApplication) setRPCAllowMethods(methods []string) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.rpcFilter.allow = methods
+}
+
+// setRPCDenyMethods refuses to forward the methods matching the given patterns
+func (app *EthermintApplication) setRPCDenyMethods(methods []string) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.rpcFilter.deny = methods
+}
+
+// rpcMethodAllowed tells whether Query can forward the method to the rpc client
+func (app *EthermintApplication) rpcMethodAllowed(method string) bool {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return app.rpcFilter.allowed(method)
+}
@@ -0,0 +1,177 @@
+package app
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+type StubChainService struct{}
+
+func (StubChainService) BlockNumber() hexutil.Uint64 { return 16 }
+func (StubChainService) GasPrice() hexutil.Uint64    { return 1 }
+
+// StubDebugService returns traces of 64 bytes each once marshaled, 66561 bytes in all
+type StubDebugService struct{}
+
+func (StubDebugService) TraceBlock() []string {
+	traces := make([]string, 1024)
+	for i := range traces {
+		traces[i] = strings.Repeat("0", 62)
+	}
+	return traces
+}
+
+func newRPCFilterTestApp(t *testing.T) (*EthermintApplication, func()) {
+	server := rpc.NewServer()
+	assert.Nil(t, server.RegisterName("eth", StubChainService{}))
+	assert.Nil(t, server.RegisterName("debug", StubDebugService{}))
+	client := rpc.DialInProc(server)
+	return &EthermintApplication{rpcClient: client, logger: tmLog.NewNopLogger()}, client.Close
+}
+
+func TestQueryRequestValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	app, closeClient := newRPCFilterTestApp(t)
+	defer closeClient()
+	query := func(data string) abciTypes.ResponseQuery {
+		return app.Query(abciTypes.RequestQuery{Path: queryPathV2, Data: []byte(data)})
+	}
+	decode := func(res abciTypes.ResponseQuery) queryError {
+		var payload queryErrorResponse
+		assert.Nil(json.Unmarshal(res.Value, &payload))
+		return payload.Error
+	}
+
+	// empty method
+	res := query(`{"params":[]}`)
+	assert.Equal(errors.CodeTypeBaseInvalidInput, res.Code)
+	assert.Equal(queryError{Code: rpcInvalidRequest, Message: "missing method"}, decode(res))
+
+	// valid method, forwarded by the default allowlist
+	res = query(`{"method":"eth_blockNumber"}`)
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+	assert.Equal(`"0x10"`, string(res.Value))
+
+	// disallowed method
+	assert.Nil(app.setOption("rpc_allow_method", "eth_blockNumber"))
+	res = query(`{"method":"eth_gasPrice"}`)
+	assert.Equal(errors.CodeTypeUnauthorized, res.Code)
+	assert.Equal(rpcMethodNotFound, decode(res).Code)
+	res = query(`{"method":"eth_blockNumber"}`)
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+
+	// the local methods aren't proxied
+	res = query(`{"method":"travis_validators"}`)
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+
+	// back to the default
+	assert.Nil(app.setOption("rpc_allow_method", ""))
+	assert.Equal(abciTypes.CodeTypeOK, query(`{"method":"eth_gasPrice"}`).Code)
+	assert.NotNil(app.setOption("rpc_allow_method", "eth_call, eth bad"))
+}
+
+func TestRPCMethodFilter(t *testing.T) {
+	assert := assert.New(t)
+
+	// the default allows the read-only methods only
+	var filter rpcMethodFilter
+	for _, method := range []string{"eth_call", "eth_getBalance", "eth_getTransactionReceipt",
+		"eth_blockNumber", "net_version", "web3_clientVersion"} {
+		assert.True(filter.allowed(method), method)
+	}
+	for _, method := range []string{"admin_addPeer", "debug_traceTransaction",
+		"personal_unlockAccount", "miner_start", "eth", "ethx_call"} {
+		assert.False(filter.allowed(method), method)
+	}
+	// the eth methods using the keys of the node included
+	for _, method := range []string{"eth_sendTransaction", "eth_sendRawTransaction",
+		"eth_sign", "eth_signTransaction", "eth_accounts", "eth_call_"} {
+		assert.False(filter.allowed(method), method)
+	}
+
+	// exact and prefix matches
+	filter.allow = []string{"eth_*", "debug_traceTransaction"}
+	assert.True(filter.allowed("eth_call"))
+	assert.True(filter.allowed("debug_traceTransaction"))
+	assert.False(filter.allowed("debug_setHead"))
+	assert.False(filter.allowed("net_version"))
+
+	// denied wins
+	filter.deny = []string{"eth_send*", "eth_sign"}
+	assert.False(filter.allowed("eth_sendRawTransaction"))
+	assert.False(filter.allowed("eth_sendTransaction"))
+	assert.False(filter.allowed("eth_sign"))
+	assert.True(filter.allowed("eth_signTypedData"))
+	assert.True(filter.allowed("eth_call"))
+
+	// everything but a namespace
+	filter = rpcMethodFilter{allow: []string{"*"}, deny: []string{"admin_*"}}
+	assert.True(filter.allowed("debug_traceTransaction"))
+	assert.False(filter.allowed("admin_addPeer"))
+}
+
+func TestRPCMethodOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	app, closeClient := newRPCFilterTestApp(t)
+	defer closeClient()
+	query := func(method string) uint32 {
+		return app.Query(abciTypes.RequestQuery{Data: []byte(`{"method":"` + method + `"}`)}).Code
+	}
+
+	assert.Equal(abciTypes.CodeTypeOK, query("eth_gasPrice"))
+	assert.Equal(errors.CodeTypeUnauthorized, query("admin_nodeInfo"))
+	// eth_sendTransaction is denied by default
+	assert.Equal(errors.CodeTypeUnauthorized, query("eth_sendTransaction"))
+
+	assert.Nil(app.setOption("rpc_deny_method", "eth_gas*"))
+	assert.Equal(errors.CodeTypeUnauthorized, query("eth_gasPrice"))
+	assert.Equal(abciTypes.CodeTypeOK, query("eth_blockNumber"))
+
+	assert.Nil(app.setOption("rpc_deny_method", ""))
+	assert.Equal(abciTypes.CodeTypeOK, query("eth_gasPrice"))
+	assert.NotNil(app.setOption("rpc_deny_method", "eth_*call"))
+}
+
+func TestQueryResponseSize(t *testing.T) {
+	assert := assert.New(t)
+
+	app, closeClient := newRPCFilterTestApp(t)
+	defer closeClient()
+	assert.Nil(app.setOption("rpc_allow_method", "eth_*, debug_traceBlock"))
+	query := func() abciTypes.ResponseQuery {
+		return app.Query(abciTypes.RequestQuery{Path: queryPathV2, Data: []byte(`{"method":"debug_traceBlock"}`)})
+	}
+
+	// no limit by default
+	res := query()
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+	assert.Equal(66561, len(res.Value))
+
+	assert.Nil(app.setOption("max_query_response_size", "66560"))
+	res = query()
+	assert.Equal(errors.CodeTypeResponseTooLarge, res.Code)
+	assert.Contains(res.Log, "response too large")
+	var payload queryErrorResponse
+	assert.Nil(json.Unmarshal(res.Value, &payload))
+	assert.Equal(rpcServerError, payload.Error.Code)
+
+	// the small responses are still served
+	res = app.Query(abciTypes.RequestQuery{Data: []byte(`{"method":"eth_blockNumber"}`)})
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+
+	assert.Nil(app.setOption("max_query_response_size", "66561"))
+	assert.Equal(abciTypes.CodeTypeOK, query().Code)
+	assert.NotNil(app.setOption("max_query_response_size", "-1"))
+}
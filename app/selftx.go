@@ -0,0 +1,63 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// selfTxPolicy decides how the txs sent by an account to itself are checked
+type selfTxPolicy int
+
+const (
+	// selfTxAllow checks them as any other tx
+	selfTxAllow selfTxPolicy = iota
+	// selfTxMinGasPrice requires the minimum gas price, even from the first tx
+	// of the pair
+	selfTxMinGasPrice
+	// selfTxRejectZeroValue rejects the ones without value
+	selfTxRejectZeroValue
+)
+
+// parseSelfTxPolicy parses the self_tx_policy option
+func parseSelfTxPolicy(value string) (selfTxPolicy, error) {
+	switch value {
+	case "", "allow":
+		return selfTxAllow, nil
+	case "min_gas_price":
+		return selfTxMinGasPrice, nil
+	case "reject_zero_value":
+		return selfTxRejectZeroValue, nil
+	}
+	return selfTxAllow, fmt.Errorf("invalid self tx policy: %s", value)
+}
+
+// checkSelfTx applies the self tx policy to a tx whose recipient is its sender
+func (app *EthermintApplication) checkSelfTx(from common.Address, tx *ethTypes.Transaction) abciTypes.ResponseCheckTx {
+	if tx.To() == nil || *tx.To() != from {
+		return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+	}
+	app.mu.Lock()
+	policy := app.selfTxPolicy
+	app.mu.Unlock()
+
+	switch policy {
+	case selfTxMinGasPrice:
+		if tx.GasPrice().Cmp(app.minGasPrice()) < 0 {
+			return abciTypes.ResponseCheckTx{
+				Code: errors.CodeLowGasPriceErr,
+				Log:  "The gas price is too low for a transaction to self"}
+		}
+	case selfTxRejectZeroValue:
+		if tx.Value().Sign() == 0 {
+			return abciTypes.ResponseCheckTx{
+				Code: errors.CodeTypeSelfTransfer,
+				Log:  fmt.Sprintf("Zero value transaction from %s to itself", from.Hex())}
+		}
+	}
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
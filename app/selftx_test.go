@@ -0,0 +1,44 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestSelfTxPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	other := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	cheapSelf := ethTypes.NewTransaction(0, from, big.NewInt(0), 21000, big.NewInt(1), nil)
+	pricedSelf := ethTypes.NewTransaction(0, from, big.NewInt(0), 21000, big.NewInt(2e9), nil)
+	valueSelf := ethTypes.NewTransaction(0, from, big.NewInt(1), 21000, big.NewInt(1), nil)
+	cheapOther := ethTypes.NewTransaction(0, other, big.NewInt(0), 21000, big.NewInt(1), nil)
+
+	// self txs are checked as any other tx by default
+	app := &EthermintApplication{}
+	assert.Equal(abciTypes.CodeTypeOK, app.checkSelfTx(from, cheapSelf).Code)
+
+	assert.Nil(app.setOption("self_tx_policy", "min_gas_price"))
+	assert.Equal(errors.CodeLowGasPriceErr, app.checkSelfTx(from, cheapSelf).Code)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkSelfTx(from, pricedSelf).Code)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkSelfTx(from, cheapOther).Code)
+
+	assert.Nil(app.setOption("self_tx_policy", "reject_zero_value"))
+	assert.Equal(errors.CodeTypeSelfTransfer, app.checkSelfTx(from, cheapSelf).Code)
+	assert.Equal(errors.CodeTypeSelfTransfer, app.checkSelfTx(from, pricedSelf).Code)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkSelfTx(from, valueSelf).Code)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkSelfTx(from, cheapOther).Code)
+
+	assert.Nil(app.setOption("self_tx_policy", "allow"))
+	assert.Equal(abciTypes.CodeTypeOK, app.checkSelfTx(from, cheapSelf).Code)
+	assert.NotNil(app.setOption("self_tx_policy", "never"))
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/CyberMiles/travis/utils"
+)
+
+// defaultSenderCacheSize is the number of senders cached unless configured
+const defaultSenderCacheSize = 4096
+
+// senderCache is an LRU of the senders recovered from the tx signatures, by tx
+// hash. A tx is decoded again on every recheck, the cache spares the recovery.
+// It's safe for concurrent use.
+type senderCache struct {
+	// guards the replacement of the cache on resize
+	mu sync.RWMutex
+	// nil when disabled
+	cache *lru.Cache
+}
+
+// newSenderCache creates a cache of at most size senders, 0 disables it
+func newSenderCache(size int) *senderCache {
+	return &senderCache{cache: utils.NewLRU(size)}
+}
+
+func (c *senderCache) get(hash common.Hash) (common.Address, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.cache == nil {
+		return common.Address{}, false
+	}
+	from, ok := c.cache.Get(hash)
+	if !ok {
+		return common.Address{}, false
+	}
+	return from.(common.Address), true
+}
+
+func (c *senderCache) add(hash common.Hash, from common.Address) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.cache != nil {
+		c.cache.Add(hash, from)
+	}
+}
+
+// len returns the number of senders cached
+func (c *senderCache) len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.cache == nil {
+		return 0
+	}
+	return c.cache.Len()
+}
+
+// resize changes the capacity, evicting the least recently used senders beyond it
+func (c *senderCache) resize(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cache = utils.ResizeLRU(c.cache, size)
+}
+
+// purge drops every sender
+func (c *senderCache) purge() {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.cache != nil {
+		c.cache.Purge()
+	}
+}
+
+// sender recovers the sender of a tx, from the cache when possible
+func (app *EthermintApplication) sender(tx *ethTypes.Transaction) (common.Address, error) {
+	// held until the sender is cached, so that a sender recovered with a replaced
+	// signer isn't cached after the purge of SetSignerResolver
+	app.signerMtx.RLock()
+	defer app.signerMtx.RUnlock()
+
+	if app.senders == nil {
+		return ethTypes.Sender(app.resolveSigner(tx), tx)
+	}
+	hash := tx.Hash()
+	if from, ok := app.senders.get(hash); ok {
+		return from, nil
+	}
+	from, err := ethTypes.Sender(app.resolveSigner(tx), tx)
+	if err != nil {
+		return common.Address{}, err
+	}
+	app.senders.add(hash, from)
+	return from, nil
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSenderCacheEviction(t *testing.T) {
+	assert := assert.New(t)
+
+	cache := newSenderCache(2)
+	hashes := []common.Hash{common.HexToHash("0x01"), common.HexToHash("0x02"), common.HexToHash("0x03")}
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+
+	cache.add(hashes[0], from)
+	cache.add(hashes[1], from)
+	// the first is the least recently used once the second is read
+	_, ok := cache.get(hashes[1])
+	assert.True(ok)
+	cache.add(hashes[2], from)
+
+	_, ok = cache.get(hashes[0])
+	assert.False(ok)
+	_, ok = cache.get(hashes[1])
+	assert.True(ok)
+	_, ok = cache.get(hashes[2])
+	assert.True(ok)
+
+	// shrinking evicts the oldest
+	cache.resize(1)
+	_, ok = cache.get(hashes[1])
+	assert.False(ok)
+	_, ok = cache.get(hashes[2])
+	assert.True(ok)
+}
+
+func TestSenderCacheOption(t *testing.T) {
+	assert := assert.New(t)
+
+	signer := ethTypes.NewEIP155Signer(big.NewInt(777))
+	app := &EthermintApplication{}
+	app.SetSignerResolver(func(tx *ethTypes.Transaction) ethTypes.Signer {
+		return signer
+	})
+	assert.Nil(app.setOption("sender_cache_size", "2"))
+
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	var txs []*ethTypes.Transaction
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		tx, _ := ethTypes.SignTx(pricedTx(nonce, 1), signer, key)
+		sender, err := app.sender(tx)
+		assert.Nil(err)
+		assert.Equal(from, sender)
+		txs = append(txs, tx)
+	}
+
+	// bounded to the configured size, the oldest sender is gone
+	assert.Equal(2, app.senders.len())
+	_, ok := app.senders.get(txs[0].Hash())
+	assert.False(ok)
+	cached, ok := app.senders.get(txs[2].Hash())
+	assert.True(ok)
+	assert.Equal(from, cached)
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"math/big"
+
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// SignerResolver returns the signer a tx is verified with
+type SignerResolver func(tx *ethTypes.Transaction) ethTypes.Signer
+
+// SetSignerResolver replaces the EIP155 signer of the network, e.g. with a mock in
+// tests or with the signer of another tx format
+// #unstable
+func (app *EthermintApplication) SetSignerResolver(resolver SignerResolver) {
+	app.signerMtx.Lock()
+	defer app.signerMtx.Unlock()
+
+	app.signerResolver = resolver
+	if app.senders != nil {
+		// the senders were recovered with the previous signer
+		app.senders.purge()
+	}
+}
+
+// signer returns the signer of a tx
+func (app *EthermintApplication) signer(tx *ethTypes.Transaction) ethTypes.Signer {
+	app.signerMtx.RLock()
+	defer app.signerMtx.RUnlock()
+
+	return app.resolveSigner(tx)
+}
+
+// resolveSigner returns the signer of a tx, signerMtx is held
+func (app *EthermintApplication) resolveSigner(tx *ethTypes.Transaction) ethTypes.Signer {
+	if app.signerResolver != nil {
+		return app.signerResolver(tx)
+	}
+	networkId := big.NewInt(int64(app.backend.Ethereum().NetVersion()))
+	return ethTypes.NewEIP155Signer(networkId)
+}
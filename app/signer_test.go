@@ -0,0 +1,62 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestCustomSignerResolver(t *testing.T) {
+	assert := assert.New(t)
+
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	custom := ethTypes.NewEIP155Signer(big.NewInt(777))
+	tx, err := ethTypes.SignTx(pricedTx(0, 1), custom, key)
+	assert.Nil(err)
+
+	consulted := 0
+	app := &EthermintApplication{}
+	app.SetSignerResolver(func(tx *ethTypes.Transaction) ethTypes.Signer {
+		consulted++
+		return custom
+	})
+
+	sender, err := ethTypes.Sender(app.signer(tx), tx)
+	assert.Nil(err)
+	assert.Equal(from, sender)
+	assert.Equal(1, consulted)
+}
+
+func TestSetSignerResolverWhileRecovering(t *testing.T) {
+	assert := assert.New(t)
+
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	custom := ethTypes.NewEIP155Signer(big.NewInt(777))
+	resolver := func(tx *ethTypes.Transaction) ethTypes.Signer {
+		return custom
+	}
+	app := &EthermintApplication{senders: newSenderCache(defaultSenderCacheSize)}
+	app.SetSignerResolver(resolver)
+
+	// run with -race, the resolver is replaced while CheckTx recovers the senders
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			app.SetSignerResolver(resolver)
+		}
+	}()
+	for nonce := uint64(0); nonce < 100; nonce++ {
+		tx, _ := ethTypes.SignTx(pricedTx(nonce, 1), custom, key)
+		sender, err := app.sender(tx)
+		assert.Nil(err)
+		assert.Equal(from, sender)
+	}
+	<-done
+}
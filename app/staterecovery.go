@@ -0,0 +1,48 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+
+	"github.com/CyberMiles/travis/api"
+)
+
+// verifyCheckTxState makes sure the CheckTx state is the state of the block with the
+// given root. After an unclean shutdown it may have been built from a partially
+// written state, it's then rebuilt from the canonical block state.
+func verifyCheckTxState(st *state.StateDB, root common.Hash,
+	rebuild func() (*state.StateDB, error)) (*state.StateDB, error) {
+
+	if st != nil && stateRoot(st) == root {
+		return st, nil
+	}
+
+	rebuilt, err := rebuild()
+	if err != nil {
+		return nil, fmt.Errorf("CheckTx state doesn't match block state %s and can't be rebuilt: %v",
+			root.Hex(), err)
+	}
+	if actual := stateRoot(rebuilt); actual != root {
+		return nil, fmt.Errorf("CheckTx state root %s doesn't match block state %s",
+			actual.Hex(), root.Hex())
+	}
+	return rebuilt, nil
+}
+
+// blockStateOf returns a builder of the state of the current block of the backend
+func blockStateOf(backend *api.Backend) func() (*state.StateDB, error) {
+	return func() (*state.StateDB, error) {
+		managed, err := backend.ResetState()
+		if err != nil {
+			return nil, err
+		}
+		return managed.StateDB, nil
+	}
+}
+
+// stateRoot computes the root of a state without finalising it
+func stateRoot(st *state.StateDB) common.Hash {
+	return st.Copy().IntermediateRoot(false)
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	goerr "errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+)
+
+func TestVerifyCheckTxState(t *testing.T) {
+	assert := assert.New(t)
+
+	canonical := newTestState()
+	canonical.AddBalance(common.HexToAddress("0x1000000000000000000000000000000000000001"), big.NewInt(1000))
+	root := stateRoot(canonical)
+
+	rebuilds := 0
+	rebuild := func() (*state.StateDB, error) {
+		rebuilds++
+		return canonical.Copy(), nil
+	}
+
+	// a matching state is kept
+	st, err := verifyCheckTxState(canonical, root, rebuild)
+	assert.Nil(err)
+	assert.Equal(canonical, st)
+	assert.Equal(0, rebuilds)
+
+	// a mismatching one is rebuilt from the block state
+	corrupt := newTestState()
+	st, err = verifyCheckTxState(corrupt, root, rebuild)
+	assert.Nil(err)
+	assert.Equal(1, rebuilds)
+	assert.Equal(root, stateRoot(st))
+}
+
+func TestVerifyCheckTxStateUnrecoverable(t *testing.T) {
+	assert := assert.New(t)
+
+	canonical := newTestState()
+	canonical.AddBalance(common.HexToAddress("0x1000000000000000000000000000000000000001"), big.NewInt(1000))
+	root := stateRoot(canonical)
+
+	// the rebuilt state still doesn't match
+	_, err := verifyCheckTxState(newTestState(), root, func() (*state.StateDB, error) {
+		return newTestState(), nil
+	})
+	assert.NotNil(err)
+
+	// the block state can't be loaded
+	_, err = verifyCheckTxState(newTestState(), root, func() (*state.StateDB, error) {
+		return nil, goerr.New("missing trie node")
+	})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "missing trie node")
+}
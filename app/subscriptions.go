@@ -0,0 +1,156 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// subscriptionTopics are the eth_subscribe topics which can be proxied
+var subscriptionTopics = map[string]bool{
+	"newHeads":               true,
+	"newPendingTransactions": true,
+	"syncing":                true,
+}
+
+// buffered notifications of a consumer, a slow consumer misses the next ones
+const subscriptionBuffer = 16
+
+// timeout of setting up a subscription with the rpc client
+const subscribeTimeout = 10 * time.Second
+
+// subscriptionManager fans out the notifications of one rpc subscription per topic
+// to the registered consumers. The rpc subscription is dropped with the last consumer.
+type subscriptionManager struct {
+	client *rpc.Client
+
+	mu     sync.Mutex
+	topics map[string]*topicFeed
+}
+
+// topicFeed is the rpc subscription of a topic and its consumers
+type topicFeed struct {
+	sub       *rpc.ClientSubscription
+	upstream  chan json.RawMessage
+	consumers map[int]chan json.RawMessage
+	nextID    int
+}
+
+func newSubscriptionManager(client *rpc.Client) *subscriptionManager {
+	return &subscriptionManager{
+		client: client,
+		topics: make(map[string]*topicFeed),
+	}
+}
+
+// Subscribe registers a consumer of the notifications of an eth_subscribe topic,
+// e.g. newHeads. The channel is closed once cancel is called, which has to be done
+// when the client disconnects, or when the subscription fails.
+// #unstable
+func (app *EthermintApplication) Subscribe(topic string) (<-chan json.RawMessage, func()) {
+	app.mu.Lock()
+	if app.subscriptions == nil {
+		app.subscriptions = newSubscriptionManager(app.rpcClient)
+	}
+	subscriptions := app.subscriptions
+	app.mu.Unlock()
+
+	ch, cancel, err := subscriptions.subscribe(topic)
+	if err != nil {
+		app.logger.Error("Error subscribing", "topic", topic, "err", err) // nolint: errcheck
+		closed := make(chan json.RawMessage)
+		close(closed)
+		return closed, func() {}
+	}
+	return ch, cancel
+}
+
+func (m *subscriptionManager) subscribe(topic string) (<-chan json.RawMessage, func(), error) {
+	if !subscriptionTopics[topic] {
+		return nil, nil, fmt.Errorf("unsupported subscription topic: %s", topic)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	feed, ok := m.topics[topic]
+	if !ok {
+		upstream := make(chan json.RawMessage, subscriptionBuffer)
+		ctx, cancel := context.WithTimeout(context.Background(), subscribeTimeout)
+		defer cancel()
+		sub, err := m.client.EthSubscribe(ctx, upstream, topic)
+		if err != nil {
+			return nil, nil, err
+		}
+		feed = &topicFeed{
+			sub:       sub,
+			upstream:  upstream,
+			consumers: make(map[int]chan json.RawMessage),
+		}
+		m.topics[topic] = feed
+		go m.forward(topic, feed)
+	}
+
+	id := feed.nextID
+	feed.nextID++
+	ch := make(chan json.RawMessage, subscriptionBuffer)
+	feed.consumers[id] = ch
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() { m.unsubscribe(topic, feed, id) })
+	}
+	return ch, cancel, nil
+}
+
+// unsubscribe removes a consumer, the rpc subscription is dropped with the last one
+func (m *subscriptionManager) unsubscribe(topic string, feed *topicFeed, id int) {
+	m.mu.Lock()
+	if ch, ok := feed.consumers[id]; ok {
+		delete(feed.consumers, id)
+		close(ch)
+	}
+	last := len(feed.consumers) == 0 && m.topics[topic] == feed
+	if last {
+		delete(m.topics, topic)
+	}
+	m.mu.Unlock()
+
+	// the rpc call isn't made under the lock
+	if last {
+		feed.sub.Unsubscribe()
+	}
+}
+
+// forward fans out the notifications of a topic until its rpc subscription ends
+func (m *subscriptionManager) forward(topic string, feed *topicFeed) {
+	for {
+		select {
+		case msg := <-feed.upstream:
+			m.mu.Lock()
+			for _, ch := range feed.consumers {
+				select {
+				case ch <- msg:
+				default:
+				}
+			}
+			m.mu.Unlock()
+		case <-feed.sub.Err():
+			// unsubscribed, or the subscription failed
+			m.mu.Lock()
+			if m.topics[topic] == feed {
+				delete(m.topics, topic)
+			}
+			for id, ch := range feed.consumers {
+				delete(feed.consumers, id)
+				close(ch)
+			}
+			m.mu.Unlock()
+			return
+		}
+	}
+}
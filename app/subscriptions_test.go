@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+)
+
+// StubEthService notifies a new head every 10ms to its newHeads subscribers
+type StubEthService struct {
+	unsubscribed chan struct{}
+}
+
+func (s *StubEthService) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, _ := rpc.NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				notifier.Notify(sub.ID, map[string]string{"number": "0x1"}) // nolint: errcheck
+			case <-sub.Err():
+				close(s.unsubscribed)
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+func TestSubscribeNewHeads(t *testing.T) {
+	assert := assert.New(t)
+
+	service := &StubEthService{unsubscribed: make(chan struct{})}
+	server := rpc.NewServer()
+	assert.Nil(server.RegisterName("eth", service))
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	app := &EthermintApplication{rpcClient: client, logger: tmLog.NewNopLogger()}
+	heads, cancel := app.Subscribe("newHeads")
+
+	select {
+	case head := <-heads:
+		var decoded map[string]string
+		assert.Nil(json.Unmarshal(head, &decoded))
+		assert.Equal("0x1", decoded["number"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification received")
+	}
+
+	// cancelling the last consumer closes its channel and drops the rpc subscription
+	cancel()
+	for range heads {
+	}
+	select {
+	case <-service.unsubscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("rpc subscription not dropped")
+	}
+	assert.Empty(app.subscriptions.topics)
+}
+
+func TestSubscribeUnsupportedTopic(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{logger: tmLog.NewNopLogger()}
+	ch, cancel := app.Subscribe("logs")
+	_, ok := <-ch
+	assert.False(ok)
+	cancel()
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"sync/atomic"
+
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// lazyTx defers the formatting of a logged tx until the line is emitted
+type lazyTx struct {
+	tx *ethTypes.Transaction
+}
+
+func (l lazyTx) String() string {
+	return l.tx.String()
+}
+
+// setTxLogSample logs 1 in sample txs, 0 or 1 logs them all
+func (app *EthermintApplication) setTxLogSample(sample uint64) {
+	atomic.StoreUint64(&app.txLogSample, sample)
+}
+
+// sampleTxLog tells whether the current tx is logged
+func (app *EthermintApplication) sampleTxLog() bool {
+	sample := atomic.LoadUint64(&app.txLogSample)
+	if sample <= 1 {
+		return true
+	}
+	return atomic.AddUint64(&app.txLogCount, 1)%sample == 0
+}
+
+// logTx logs a CheckTx or DeliverTx at Debug level, subject to the sampling
+func (app *EthermintApplication) logTx(msg string, tx *ethTypes.Transaction, keyvals ...interface{}) {
+	if !app.sampleTxLog() {
+		return
+	}
+	app.logger.Debug(msg, append([]interface{}{"tx", lazyTx{tx}}, keyvals...)...) // nolint: errcheck
+}
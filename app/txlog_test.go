@@ -0,0 +1,40 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tmLog "github.com/tendermint/tendermint/libs/log"
+)
+
+// countingLogger counts the emitted lines
+type countingLogger struct {
+	lines int
+}
+
+func (l *countingLogger) Debug(msg string, keyvals ...interface{}) { l.lines++ }
+func (l *countingLogger) Info(msg string, keyvals ...interface{})  { l.lines++ }
+func (l *countingLogger) Error(msg string, keyvals ...interface{}) { l.lines++ }
+func (l *countingLogger) With(keyvals ...interface{}) tmLog.Logger { return l }
+
+func TestTxLogSampling(t *testing.T) {
+	assert := assert.New(t)
+
+	logger := &countingLogger{}
+	app := &EthermintApplication{logger: logger}
+	tx := pricedTx(0, 1)
+
+	// every tx is logged by default
+	for i := 0; i < 100; i++ {
+		app.logTx("CheckTx", tx)
+	}
+	assert.Equal(100, logger.lines)
+
+	logger.lines = 0
+	assert.Nil(app.setOption("tx_log_sample", "100"))
+	for i := 0; i < 10000; i++ {
+		app.logTx("CheckTx", tx)
+	}
+	assert.Equal(100, logger.lines)
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/tendermint/tendermint/crypto/merkle"
+)
+
+// txOrderRootsKept is the number of recently committed blocks whose tx order
+// root is kept
+const txOrderRootsKept = 1024
+
+// txHashLeaf is a delivered tx hash as a leaf of the tx order tree
+type txHashLeaf common.Hash
+
+func (h txHashLeaf) Hash() []byte {
+	return h[:]
+}
+
+// txOrderRoot is the tendermint simple merkle root of the hashes of the txs in
+// delivery order, a tx position is proven with the proofs of
+// merkle.SimpleProofsFromHashers over the same leaves. A block without txs has
+// an empty root.
+func txOrderRoot(hashes []common.Hash) []byte {
+	leaves := make([]merkle.Hasher, len(hashes))
+	for i, hash := range hashes {
+		leaves[i] = txHashLeaf(hash)
+	}
+	return merkle.SimpleHashFromHashers(leaves)
+}
+
+// recordTxOrder appends a tx to the delivery order of the current block,
+// whatever the outcome of its delivery
+func (app *EthermintApplication) recordTxOrder(tx *ethTypes.Transaction) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.txOrder = append(app.txOrder, tx.Hash())
+}
+
+// commitTxOrder stores the tx order root of the block committed at height and
+// starts the order of the next block
+func (app *EthermintApplication) commitTxOrder(height uint64) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if app.txOrderRoots == nil {
+		app.txOrderRoots = make(map[uint64][]byte)
+	}
+	app.txOrderRoots[height] = txOrderRoot(app.txOrder)
+	if height >= txOrderRootsKept {
+		delete(app.txOrderRoots, height-txOrderRootsKept)
+	}
+	app.txOrder = nil
+}
+
+// TxOrderRoot returns the tx order root of a recently committed block
+// #unstable
+func (app *EthermintApplication) TxOrderRoot(height uint64) ([]byte, bool) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	root, ok := app.txOrderRoots[height]
+	return root, ok
+}
+
+// txOrderRootQuery serves travis_txOrderRoot, whose only param is the height
+func (app *EthermintApplication) txOrderRootQuery(params []interface{}) (hexutil.Bytes, error) {
+	if len(params) != 1 {
+		return nil, fmt.Errorf("expected 1 param, got %d", len(params))
+	}
+	// json numbers are decoded as float64
+	height, ok := params[0].(float64)
+	if !ok || height < 0 || height != float64(uint64(height)) {
+		return nil, fmt.Errorf("invalid height: %v", params[0])
+	}
+	root, ok := app.TxOrderRoot(uint64(height))
+	if !ok {
+		return nil, fmt.Errorf("no tx order root for height %d", uint64(height))
+	}
+	return root, nil
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/tendermint/tendermint/crypto/merkle"
+)
+
+func TestTxOrderRoot(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{}
+	var leaves []merkle.Hasher
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		tx := pricedTx(nonce, 1)
+		app.recordTxOrder(tx)
+		leaves = append(leaves, txHashLeaf(tx.Hash()))
+	}
+	app.commitTxOrder(7)
+
+	// recomputed by hand: the left subtree takes the first two leaves
+	leaf := func(i int) []byte { return leaves[i].Hash() }
+	expected := merkle.SimpleHashFromTwoHashes(merkle.SimpleHashFromTwoHashes(leaf(0), leaf(1)), leaf(2))
+	root, ok := app.TxOrderRoot(7)
+	assert.True(ok)
+	assert.Equal(expected, root)
+
+	// the position of each tx is provable against the root
+	_, proofs := merkle.SimpleProofsFromHashers(leaves)
+	for i, proof := range proofs {
+		assert.True(proof.Verify(i, len(proofs), leaf(i), root))
+	}
+	assert.False(proofs[0].Verify(1, len(proofs), leaf(0), root))
+
+	// the order matters
+	app.recordTxOrder(pricedTx(1, 1))
+	app.recordTxOrder(pricedTx(0, 1))
+	app.recordTxOrder(pricedTx(2, 1))
+	app.commitTxOrder(8)
+	swapped, _ := app.TxOrderRoot(8)
+	assert.NotEqual(root, swapped)
+
+	// an empty block
+	app.commitTxOrder(9)
+	empty, ok := app.TxOrderRoot(9)
+	assert.True(ok)
+	assert.Empty(empty)
+
+	res, err := app.txOrderRootQuery([]interface{}{float64(7)})
+	assert.Nil(err)
+	assert.Equal(hexutil.Bytes(root), res)
+	_, err = app.txOrderRootQuery([]interface{}{float64(6)})
+	assert.NotNil(err)
+	_, err = app.txOrderRootQuery([]interface{}{"7"})
+	assert.NotNil(err)
+
+	// only the last roots are kept
+	app.commitTxOrder(7 + txOrderRootsKept)
+	_, ok = app.TxOrderRoot(7)
+	assert.False(ok)
+}
@@ -0,0 +1,206 @@
+package app
+
+import (
+	"bytes"
+	"container/heap"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// pendingTx is a tx admitted by CheckTx which hasn't been committed yet
+type pendingTx struct {
+	tx   *ethTypes.Transaction
+	from common.Address
+	// pays the gas of a delegated fee tx, zero for the other txs
+	payer common.Address
+	// position in the price heap, -1 once evicted
+	index int
+}
+
+// txPool tracks the txs admitted by CheckTx since the last Commit,
+// together with the committed state the CheckTx state started from
+type txPool struct {
+	base     *state.StateDB
+	txs      []*pendingTx
+	bySender map[common.Address][]*pendingTx
+	// resident txs, cheapest first
+	priced priceHeap
+}
+
+func newTxPool(base *state.StateDB) *txPool {
+	return &txPool{
+		base:     base,
+		bySender: make(map[common.Address][]*pendingTx),
+	}
+}
+
+// add records an admitted tx
+func (p *txPool) add(from common.Address, tx *ethTypes.Transaction) {
+	p.addDelegated(from, common.Address{}, tx)
+}
+
+// tracks tells whether the pool holds the tx of from
+func (p *txPool) tracks(from common.Address, hash common.Hash) bool {
+	for _, ptx := range p.bySender[from] {
+		if ptx.tx.Hash() == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// addDelegated records an admitted delegated fee tx, whose gas is paid by payer
+func (p *txPool) addDelegated(from, payer common.Address, tx *ethTypes.Transaction) {
+	ptx := &pendingTx{tx: tx, from: from, payer: payer}
+	p.txs = append(p.txs, ptx)
+	p.bySender[from] = append(p.bySender[from], ptx)
+	heap.Push(&p.priced, ptx)
+}
+
+// addEvicted records a tx evicted by a higher priced one, which stays out of the
+// price heap
+func (p *txPool) addEvicted(from, payer common.Address, tx *ethTypes.Transaction) {
+	ptx := &pendingTx{tx: tx, from: from, payer: payer, index: -1}
+	p.txs = append(p.txs, ptx)
+	p.bySender[from] = append(p.bySender[from], ptx)
+}
+
+// removeSender drops the txs of from, which are returned
+func (p *txPool) removeSender(from common.Address) []*pendingTx {
+	removed := p.bySender[from]
+	delete(p.bySender, from)
+	kept := make([]*pendingTx, 0, len(p.txs)-len(removed))
+	for _, ptx := range p.txs {
+		if ptx.from != from {
+			kept = append(kept, ptx)
+		}
+	}
+	p.txs = kept
+	for _, ptx := range removed {
+		if ptx.index >= 0 {
+			heap.Remove(&p.priced, ptx.index)
+		}
+	}
+	return removed
+}
+
+// recordPending tracks a tx admitted by CheckTx. If the mempool cap is exceeded
+// the cheapest resident is evicted. The effects of the evicted tx on the CheckTx
+// state, its nonce and balance debits, aren't rolled back, the txs of its sender
+// admitted after it were checked against them. They're dropped along with the
+// state on the next Commit, after which the evicted tx fails its recheck.
+func (app *EthermintApplication) recordPending(from common.Address, tx *ethTypes.Transaction) {
+	app.recordDelegatedPending(from, common.Address{}, tx)
+}
+
+// recordDelegatedPending is recordPending for a delegated fee tx, whose gas is paid by
+// payer. A tx the pool tracks already, e.g. rechecked without a Commit resetting the
+// pool, isn't recorded twice.
+func (app *EthermintApplication) recordDelegatedPending(from, payer common.Address, tx *ethTypes.Transaction) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if app.pool.tracks(from, tx.Hash()) {
+		return
+	}
+	app.pool.addDelegated(from, payer, tx)
+	if app.mempoolCap > 0 && app.pool.priced.Len() > app.mempoolCap {
+		evicted := heap.Pop(&app.pool.priced).(*pendingTx)
+		app.evicted[evicted.tx.Hash()] = evicted
+		app.logger.Info("Evicted cheapest tx from the full mempool", // nolint: errcheck
+			"hash", evicted.tx.Hash().Hex(), "gasPrice", evicted.tx.GasPrice())
+	}
+}
+
+// checkMempoolCapacity rejects a new tx when the mempool is full and the tx doesn't
+// outrank the cheapest resident, and any tx which has been evicted before.
+func (app *EthermintApplication) checkMempoolCapacity(tx *ethTypes.Transaction,
+	checkType CheckTxType) abciTypes.ResponseCheckTx {
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	// an evicted tx is dropped by the mempool on its next recheck
+	if _, ok := app.evicted[tx.Hash()]; ok {
+		delete(app.evicted, tx.Hash())
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeMempoolFull,
+			Log:  "Transaction evicted by higher priced transactions"}
+	}
+
+	if checkType != CheckTxNew || app.mempoolCap <= 0 || app.pool.priced.Len() < app.mempoolCap {
+		return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+	}
+	if cheapest := app.pool.priced[0].tx; !cheaper(cheapest, tx) {
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeMempoolFull,
+			Log: fmt.Sprintf(
+				"Mempool is full, gas price %s doesn't outbid %s",
+				tx.GasPrice(), cheapest.GasPrice())}
+	}
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
+
+// resetPending drops the tracked txs once the CheckTx state is reset to base. The
+// residents passing their recheck are tracked again.
+func (app *EthermintApplication) resetPending(base *state.StateDB) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.pool = newTxPool(base.Copy())
+}
+
+// pruneEvicted forgets on Commit the evicted txs whose nonce has been used by a
+// committed tx, which left the mempool without being rechecked
+func (app *EthermintApplication) pruneEvicted(committedState *state.StateDB) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	for hash, ptx := range app.evicted {
+		if ptx.tx.Nonce() < committedState.GetNonce(ptx.from) {
+			delete(app.evicted, hash)
+		}
+	}
+}
+
+// cheaper orders txs by gas price, ties broken deterministically by hash
+func cheaper(a, b *ethTypes.Transaction) bool {
+	if c := a.GasPrice().Cmp(b.GasPrice()); c != 0 {
+		return c < 0
+	}
+	ha, hb := a.Hash(), b.Hash()
+	return bytes.Compare(ha[:], hb[:]) > 0
+}
+
+// priceHeap is a min-heap of pending txs by gas price
+type priceHeap []*pendingTx
+
+func (h priceHeap) Len() int           { return len(h) }
+func (h priceHeap) Less(i, j int) bool { return cheaper(h[i].tx, h[j].tx) }
+func (h priceHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *priceHeap) Push(x interface{}) {
+	ptx := x.(*pendingTx)
+	ptx.index = len(*h)
+	*h = append(*h, ptx)
+}
+
+func (h *priceHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	ptx := old[n-1]
+	old[n-1] = nil
+	ptx.index = -1
+	*h = old[:n-1]
+	return ptx
+}
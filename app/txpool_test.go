@@ -0,0 +1,166 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func newPoolTestApp(capacity int) *EthermintApplication {
+	st := newTestState()
+	return &EthermintApplication{
+		logger:       tmLog.NewNopLogger(),
+		checkTxState: st,
+		pool:         newTxPool(st.Copy()),
+		mempoolCap:   capacity,
+		evicted:      make(map[common.Hash]*pendingTx),
+	}
+}
+
+func pricedTx(nonce uint64, price int64) *ethTypes.Transaction {
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	return ethTypes.NewTransaction(nonce, to, big.NewInt(1), 21000, big.NewInt(price), nil)
+}
+
+// admit mimics the mempool bookkeeping of validateTx
+func admit(app *EthermintApplication, from common.Address, tx *ethTypes.Transaction) abciTypes.ResponseCheckTx {
+	resp := app.checkMempoolCapacity(tx, CheckTxNew)
+	if resp.Code == abciTypes.CodeTypeOK {
+		app.recordPending(from, tx)
+	}
+	return resp
+}
+
+func TestMempoolEvictsCheapest(t *testing.T) {
+	assert := assert.New(t)
+
+	app := newPoolTestApp(2)
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+
+	cheap, mid, rich := pricedTx(0, 1), pricedTx(1, 2), pricedTx(2, 3)
+	assert.Equal(abciTypes.CodeTypeOK, admit(app, from, cheap).Code)
+	assert.Equal(abciTypes.CodeTypeOK, admit(app, from, mid).Code)
+
+	// the pool is full, a tx not outbidding the cheapest is rejected; an equally
+	// priced one is ordered by hash, see TestMempoolTieBreaksOnHash
+	assert.Equal(errors.CodeTypeMempoolFull, admit(app, from, pricedTx(3, 0)).Code)
+
+	// a higher priced tx gets in and evicts the cheapest
+	assert.Equal(abciTypes.CodeTypeOK, admit(app, from, rich).Code)
+	assert.Equal(2, app.pool.priced.Len())
+	assert.Equal(mid, app.pool.priced[0].tx)
+
+	// the evicted tx is rejected on its recheck, once
+	assert.Equal(errors.CodeTypeMempoolFull, app.checkMempoolCapacity(cheap, CheckTxRecheck).Code)
+	assert.Empty(app.evicted)
+}
+
+func TestEvictedTxKeepsSpeculativeState(t *testing.T) {
+	assert := assert.New(t)
+
+	app := newPoolTestApp(1)
+	cheapFrom := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	richFrom := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	for _, st := range []*state.StateDB{app.checkTxState, app.pool.base} {
+		st.AddBalance(cheapFrom, big.NewInt(1000000))
+		st.AddBalance(richFrom, big.NewInt(1000000))
+	}
+	checkTx := func(from common.Address, tx *ethTypes.Transaction) {
+		assert.Equal(abciTypes.CodeTypeOK, applySpeculativeTx(app.checkTxState, from, tx.Nonce(), tx, CheckTxNew).Code)
+		assert.Equal(abciTypes.CodeTypeOK, admit(app, from, tx).Code)
+	}
+
+	cheap, rich := pricedTx(0, 1), pricedTx(0, 2)
+	checkTx(cheapFrom, cheap)
+	checkTx(richFrom, rich)
+	assert.Equal(rich, app.pool.priced[0].tx)
+
+	// the debits of the evicted tx stay in the CheckTx state until Commit, the
+	// next tx of its sender is checked against them
+	assert.Equal(uint64(1), app.checkTxState.GetNonce(cheapFrom))
+	assert.Equal(new(big.Int).Sub(big.NewInt(1000000), cheap.Cost()), app.checkTxState.GetBalance(cheapFrom))
+	assert.Empty(app.AuditCheckTxState())
+}
+
+func TestMempoolTieBreaksOnHash(t *testing.T) {
+	assert := assert.New(t)
+
+	a, b := pricedTx(0, 5), pricedTx(1, 5)
+	// exactly one of two equally priced txs is the cheaper one, whatever the order
+	assert.NotEqual(cheaper(a, b), cheaper(b, a))
+
+	loser, winner := a, b
+	if cheaper(b, a) {
+		loser, winner = b, a
+	}
+
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	app := newPoolTestApp(1)
+	assert.Equal(abciTypes.CodeTypeOK, admit(app, from, loser).Code)
+	assert.Equal(abciTypes.CodeTypeOK, admit(app, from, winner).Code)
+	assert.Equal(winner, app.pool.priced[0].tx)
+
+	app = newPoolTestApp(1)
+	assert.Equal(abciTypes.CodeTypeOK, admit(app, from, winner).Code)
+	assert.Equal(errors.CodeTypeMempoolFull, admit(app, from, loser).Code)
+}
+
+func TestPoolRebuiltByRechecks(t *testing.T) {
+	assert := assert.New(t)
+
+	app := newPoolTestApp(2)
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	first, second := pricedTx(0, 1), pricedTx(1, 2)
+	assert.Equal(abciTypes.CodeTypeOK, admit(app, from, first).Code)
+	assert.Equal(abciTypes.CodeTypeOK, admit(app, from, second).Code)
+
+	// Commit resets the pool, the rechecked residents are tracked again
+	app.resetPending(app.checkTxState)
+	assert.Empty(app.pool.txs)
+	for _, tx := range []*ethTypes.Transaction{first, second} {
+		assert.Equal(abciTypes.CodeTypeOK, app.checkMempoolCapacity(tx, CheckTxRecheck).Code)
+		app.recordPending(from, tx)
+	}
+	assert.Len(app.pool.txs, 2)
+	assert.Equal(2, app.pool.priced.Len())
+
+	// so the cap still holds against the new txs
+	assert.Equal(errors.CodeTypeMempoolFull, admit(app, from, pricedTx(2, 0)).Code)
+
+	// a tx is tracked once, however many times it's rechecked
+	app.recordPending(from, second)
+	assert.Len(app.pool.bySender[from], 2)
+	assert.Equal(2, app.pool.priced.Len())
+	assert.Empty(app.evicted)
+}
+
+func TestPruneEvicted(t *testing.T) {
+	assert := assert.New(t)
+
+	app := newPoolTestApp(1)
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	other := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	assert.Equal(abciTypes.CodeTypeOK, admit(app, from, pricedTx(0, 1)).Code)
+	assert.Equal(abciTypes.CodeTypeOK, admit(app, other, pricedTx(3, 2)).Code)
+	assert.Equal(abciTypes.CodeTypeOK, admit(app, other, pricedTx(4, 3)).Code)
+	assert.Len(app.evicted, 2)
+
+	// the evicted txs whose nonce is committed are forgotten, the others are
+	// still rejected on their recheck
+	committed := newTestState()
+	committed.SetNonce(from, 1)
+	committed.SetNonce(other, 3)
+	app.pruneEvicted(committed)
+	assert.Len(app.evicted, 1)
+	assert.Equal(errors.CodeTypeMempoolFull, app.checkMempoolCapacity(pricedTx(3, 2), CheckTxRecheck).Code)
+	assert.Empty(app.evicted)
+}
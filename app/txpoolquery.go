@@ -0,0 +1,146 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// number of entries of a travis_txpool page when no limit is given
+	defaultTxPoolPageSize = 100
+	// maximum number of entries of a travis_txpool page
+	maxTxPoolPageSize = 1000
+)
+
+// status of the txs listed by travis_txpool
+const (
+	// admitted in the mempool, waiting to be delivered
+	txPoolPending = "pending"
+	// held until the nonce gap before it is filled
+	txPoolQueued = "queued"
+	// below the minimum gas price, recorded by the low price check only
+	txPoolLowPrice = "lowPrice"
+)
+
+type txPoolEntry struct {
+	From   common.Address `json:"from"`
+	Nonce  hexutil.Uint64 `json:"nonce"`
+	Hash   common.Hash    `json:"hash"`
+	Status string         `json:"status"`
+	// the tx pays less than the minimum gas price
+	LowPrice bool `json:"lowPrice,omitempty"`
+}
+
+// txPoolPage is a page of the txs tracked by the application, ordered by sender
+// and nonce; Total is the number of entries matching the filter over all pages
+type txPoolPage struct {
+	Total  int           `json:"total"`
+	Offset uint64        `json:"offset"`
+	Txs    []txPoolEntry `json:"txs"`
+}
+
+// txPoolListing returns a page of the txs tracked since the last Commit, optionally
+// filtered by the sender address given as the only param
+func (app *EthermintApplication) txPoolListing(in jsonRequest) (*txPoolPage, error) {
+	var filter *common.Address
+	switch len(in.Params) {
+	case 0:
+	case 1:
+		hex, ok := in.Params[0].(string)
+		if !ok || !common.IsHexAddress(hex) {
+			return nil, fmt.Errorf("invalid address: %v", in.Params[0])
+		}
+		addr := common.HexToAddress(hex)
+		filter = &addr
+	default:
+		return nil, fmt.Errorf("expected at most 1 param, got %d", len(in.Params))
+	}
+	limit := in.Limit
+	if limit == 0 {
+		limit = defaultTxPoolPageSize
+	}
+	if limit > maxTxPoolPageSize {
+		return nil, fmt.Errorf("limit %d exceeds the maximum of %d", limit, maxTxPoolPageSize)
+	}
+
+	entries := app.txPoolEntries(filter)
+	page := &txPoolPage{Total: len(entries), Offset: in.Offset, Txs: []txPoolEntry{}}
+	if in.Offset < uint64(len(entries)) {
+		end := in.Offset + limit
+		if end > uint64(len(entries)) {
+			end = uint64(len(entries))
+		}
+		page.Txs = entries[in.Offset:end]
+	}
+	return page, nil
+}
+
+// txPoolEntries collects the pending, queued and low price txs of the given sender,
+// or of all of them when nil
+func (app *EthermintApplication) txPoolEntries(filter *common.Address) []txPoolEntry {
+	match := func(from common.Address) bool {
+		return filter == nil || *filter == from
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	lowPrice := make(map[common.Hash]txPoolEntry)
+	for ft, lpt := range app.lowPriceTransactions {
+		if match(ft.from) {
+			lowPrice[lpt.tx.Hash()] = txPoolEntry{
+				From:     ft.from,
+				Nonce:    hexutil.Uint64(lpt.tx.Nonce()),
+				Hash:     lpt.tx.Hash(),
+				Status:   txPoolLowPrice,
+				LowPrice: true,
+			}
+		}
+	}
+
+	var entries []txPoolEntry
+	add := func(entry txPoolEntry) {
+		if _, ok := lowPrice[entry.Hash]; ok {
+			entry.LowPrice = true
+			delete(lowPrice, entry.Hash)
+		}
+		entries = append(entries, entry)
+	}
+	for from, pending := range app.pendingBySender {
+		if !match(from) {
+			continue
+		}
+		for hash, nonce := range pending {
+			add(txPoolEntry{From: from, Nonce: hexutil.Uint64(nonce), Hash: hash, Status: txPoolPending})
+		}
+	}
+	for from, queued := range app.futureTxs {
+		if !match(from) {
+			continue
+		}
+		for _, tx := range queued {
+			add(txPoolEntry{From: from, Nonce: hexutil.Uint64(tx.Nonce()), Hash: tx.Hash(), Status: txPoolQueued})
+		}
+	}
+	// a low price tx which didn't make it to the mempool
+	for _, entry := range lowPrice {
+		entries = append(entries, entry)
+	}
+
+	// maps are unordered, sort for stable pages
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if c := bytes.Compare(a.From[:], b.From[:]); c != 0 {
+			return c < 0
+		}
+		if a.Nonce != b.Nonce {
+			return a.Nonce < b.Nonce
+		}
+		return bytes.Compare(a.Hash[:], b.Hash[:]) < 0
+	})
+	return entries
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"crypto/ecdsa"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestTxPoolListing(t *testing.T) {
+	assert := assert.New(t)
+
+	app, signer := newPendingCapTestApp(0)
+	app.futureTxs = make(map[common.Address][]*ethTypes.Transaction)
+	app.lowPriceTransactions = make(map[FromTo]*lowPriceTx)
+
+	keyA, _ := crypto.GenerateKey()
+	keyB, _ := crypto.GenerateKey()
+	fromA := crypto.PubkeyToAddress(keyA.PublicKey)
+	fromB := crypto.PubkeyToAddress(keyB.PublicKey)
+
+	sign := func(nonce uint64, key *ecdsa.PrivateKey) *ethTypes.Transaction {
+		tx, err := ethTypes.SignTx(pricedTx(nonce, 1), signer, key)
+		assert.Nil(err)
+		return tx
+	}
+
+	// A has nonces 0 and 1 in the mempool and 3 held, B has nonce 0
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		app.addPending(fromA, sign(nonce, keyA))
+	}
+	held := app.queueFutureTx(fromA, 2, plentyBalance, sign(3, keyA), abciTypes.ResponseCheckTx{Code: errors.CodeTypeBadNonce})
+	assert.Equal(errors.CodeTypeFutureNonce, held.Code)
+	cheap := sign(0, keyB)
+	app.addPending(fromB, cheap)
+	app.lowPriceTransactions[FromTo{from: fromB, to: *cheap.To()}] = &lowPriceTx{tx: cheap, added: time.Now()}
+
+	query := func(in jsonRequest) *txPoolPage {
+		in.Method = "travis_txpool"
+		result, handled, err := app.localQuery(in)
+		assert.True(handled)
+		assert.Nil(err)
+		return result.(*txPoolPage)
+	}
+
+	page := query(jsonRequest{})
+	assert.Equal(4, page.Total)
+	assert.Len(page.Txs, 4)
+
+	// filtered by sender, ordered by nonce
+	page = query(jsonRequest{Params: []interface{}{fromA.Hex()}})
+	assert.Equal(3, page.Total)
+	if assert.Len(page.Txs, 3) {
+		for i, nonce := range []uint64{0, 1, 3} {
+			assert.Equal(fromA, page.Txs[i].From)
+			assert.Equal(hexutil.Uint64(nonce), page.Txs[i].Nonce)
+		}
+		assert.Equal(txPoolPending, page.Txs[1].Status)
+		assert.Equal(txPoolQueued, page.Txs[2].Status)
+	}
+
+	page = query(jsonRequest{Params: []interface{}{fromB.Hex()}})
+	if assert.Len(page.Txs, 1) {
+		assert.Equal(cheap.Hash(), page.Txs[0].Hash)
+		assert.Equal(txPoolPending, page.Txs[0].Status)
+		assert.True(page.Txs[0].LowPrice)
+	}
+
+	// paginated
+	page = query(jsonRequest{Params: []interface{}{fromA.Hex()}, Offset: 1, Limit: 1})
+	assert.Equal(3, page.Total)
+	if assert.Len(page.Txs, 1) {
+		assert.Equal(hexutil.Uint64(1), page.Txs[0].Nonce)
+	}
+	page = query(jsonRequest{Offset: 10})
+	assert.Equal(4, page.Total)
+	assert.Empty(page.Txs)
+
+	// invalid requests
+	_, _, err := app.localQuery(jsonRequest{Method: "travis_txpool", Params: []interface{}{"0x12"}})
+	assert.NotNil(err)
+	_, _, err = app.localQuery(jsonRequest{Method: "travis_txpool", Limit: maxTxPoolPageSize + 1})
+	assert.NotNil(err)
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// txReceipt answers travis_txReceipt: the receipt of eth_getTransactionReceipt with
+// the number of blocks mined on top of the tx block as confirmations, or only
+// pending set while the tx isn't mined
+func (app *EthermintApplication) txReceipt(params []interface{}) (map[string]interface{}, error) {
+	if len(params) != 1 {
+		return nil, fmt.Errorf("expected 1 param, got %d", len(params))
+	}
+	hex, ok := params[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid transaction hash: %v", params[0])
+	}
+	hash := common.HexToHash(hex)
+
+	var receipt map[string]interface{}
+	if err := app.rpcClient.Call(&receipt, "eth_getTransactionReceipt", hash); err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		return map[string]interface{}{"transactionHash": hash, "pending": true}, nil
+	}
+	var head hexutil.Uint64
+	if err := app.rpcClient.Call(&head, "eth_blockNumber"); err != nil {
+		return nil, err
+	}
+	number, ok := receipt["blockNumber"].(string)
+	if !ok {
+		return nil, fmt.Errorf("receipt without a block number")
+	}
+	block, err := hexutil.DecodeUint64(number)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block number %s: %v", number, err)
+	}
+	confirmations := hexutil.Uint64(0)
+	if uint64(head) > block {
+		confirmations = hexutil.Uint64(uint64(head) - block)
+	}
+	receipt["confirmations"] = confirmations
+	receipt["pending"] = false
+	return receipt, nil
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+)
+
+var minedTxHash = common.HexToHash("0x01")
+
+// StubReceiptService has mined minedTxHash at height 10 of 16
+type StubReceiptService struct{}
+
+func (StubReceiptService) BlockNumber() hexutil.Uint64 { return 16 }
+
+func (StubReceiptService) GetTransactionReceipt(hash common.Hash) (map[string]interface{}, error) {
+	if hash != minedTxHash {
+		return nil, nil
+	}
+	return map[string]interface{}{
+		"transactionHash": hash,
+		"blockNumber":     hexutil.Uint64(10),
+		"status":          hexutil.Uint(1),
+	}, nil
+}
+
+func TestTxReceiptQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	server := rpc.NewServer()
+	assert.Nil(server.RegisterName("eth", StubReceiptService{}))
+	client := rpc.DialInProc(server)
+	defer client.Close()
+	app := &EthermintApplication{rpcClient: client, logger: tmLog.NewNopLogger()}
+
+	query := func(hash common.Hash) map[string]interface{} {
+		data, _ := json.Marshal(jsonRequest{Method: "travis_txReceipt", Params: []interface{}{hash.Hex()}})
+		res := app.Query(abciTypes.RequestQuery{Data: data})
+		assert.Equal(abciTypes.CodeTypeOK, res.Code, res.Log)
+		var receipt map[string]interface{}
+		assert.Nil(json.Unmarshal(res.Value, &receipt))
+		return receipt
+	}
+
+	// mined at 10, 16 is the head
+	receipt := query(minedTxHash)
+	assert.Equal("0x6", receipt["confirmations"])
+	assert.Equal(false, receipt["pending"])
+	assert.Equal("0x1", receipt["status"])
+
+	receipt = query(common.HexToHash("0x02"))
+	assert.Equal(true, receipt["pending"])
+	assert.NotContains(receipt, "confirmations")
+
+	data, _ := json.Marshal(jsonRequest{Method: "travis_txReceipt"})
+	assert.NotEqual(abciTypes.CodeTypeOK, app.Query(abciTypes.RequestQuery{Data: data}).Code)
+}
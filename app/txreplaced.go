@@ -0,0 +1,19 @@
+package app
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TxReplacedHandler is notified when a tx waiting in CheckTx is replaced by
+// another tx of the same sender and nonce
+type TxReplacedHandler func(oldHash, newHash common.Hash)
+
+// SetTxReplacedHandler sets the handler notified of the replaced txs, e.g. to
+// update the pending txs shown by a wallet; nil disables it
+// #unstable
+func (app *EthermintApplication) SetTxReplacedHandler(handler TxReplacedHandler) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.onTxReplaced = handler
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+// isTypedTxEnvelope tells an EIP-2718 envelope from a legacy tx, which is an rlp list
+func isTypedTxEnvelope(b []byte) bool {
+	return len(b) > 0 && b[0] <= 0x7f
+}
+
+// recoverSigner recovers the address which signed a hash, v being the recovery id
+func recoverSigner(sighash common.Hash, V, R, S *big.Int) (common.Address, error) {
+	if V == nil || R == nil || S == nil || V.BitLen() > 1 {
+		return common.Address{}, ethTypes.ErrInvalidSig
+	}
+	v := byte(V.Uint64())
+	if !crypto.ValidateSignatureValues(v, R, S, true) {
+		return common.Address{}, ethTypes.ErrInvalidSig
+	}
+
+	r, s := R.Bytes(), S.Bytes()
+	sig := make([]byte, 65)
+	copy(sig[32-len(r):32], r)
+	copy(sig[64-len(s):64], s)
+	sig[64] = v
+	pub, err := crypto.SigToPub(sighash[:], sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// openTypedTx opens an EIP-2718 envelope into the legacy tx it carries and the
+// account paying its gas. The EVM of the backend only executes legacy txs, so only
+// the delegated fee txs, wrapping one, are accepted; the other types, access list
+// txs included, are rejected up front with the code returned along with the error.
+// The size of the envelope is checked before decoding it, as checkTxSize does for
+// the legacy txs.
+func openTypedTx(b []byte) (*ethTypes.Transaction, common.Address, uint32, error) {
+	if len(b) > maxTransactionSize {
+		return nil, common.Address{}, errors.CodeTypeInternalErr, core.ErrOversizedData
+	}
+	if b[0] != delegatedFeeTxType {
+		return nil, common.Address{}, errors.CodeTypeUnsupportedTxType,
+			fmt.Errorf("unsupported transaction type 0x%02x", b[0])
+	}
+	return openDelegatedFeeTx(b)
+}
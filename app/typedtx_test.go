@@ -0,0 +1,40 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestCheckTypedTxUnsupported(t *testing.T) {
+	assert := assert.New(t)
+
+	// the envelopes are told apart from the legacy txs
+	legacy, _ := rlp.EncodeToBytes(pricedTx(0, 1))
+	assert.False(isTypedTxEnvelope(legacy))
+
+	// access list txs and unknown types are rejected up front
+	for _, txType := range []byte{0x01, 0x02, 0x05} {
+		b := []byte{txType, 0xc0}
+		assert.True(isTypedTxEnvelope(b))
+		_, _, code, err := openTypedTx(b)
+		assert.NotNil(err)
+		assert.Equal(errors.CodeTypeUnsupportedTxType, code)
+	}
+}
+
+func TestCheckTypedTxSize(t *testing.T) {
+	assert := assert.New(t)
+
+	// the envelope is rejected before it's decoded, whatever its type
+	for _, txType := range []byte{0x01, delegatedFeeTxType, 0x05} {
+		b := append([]byte{txType}, make([]byte, maxTransactionSize)...)
+		_, _, code, err := openTypedTx(b)
+		assert.NotNil(err)
+		assert.Equal(errors.CodeTypeInternalErr, code)
+	}
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	lru "github.com/hashicorp/golang-lru"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+const (
+	// number of senders whose failed balance checks are counted
+	underfundedSendersKept = 16384
+	// failed balance checks after which a sender is fast-rejected
+	underfundedThreshold = 3
+)
+
+// underfundedSenders counts the failed balance checks of each sender since the
+// last Commit. Only the sender itself can be fast-rejected, once its own count
+// reached underfundedThreshold. The counts are kept in an LRU, a flood of
+// distinct senders evicts the oldest ones, which then get the full validation
+// again until they fail it underfundedThreshold more times.
+type underfundedSenders struct {
+	counts *lru.Cache
+}
+
+func (u *underfundedSenders) add(addr common.Address) {
+	if u.counts == nil {
+		u.counts, _ = lru.New(underfundedSendersKept)
+	}
+	count := 0
+	if v, ok := u.counts.Get(addr); ok {
+		count = v.(int)
+	}
+	if count < underfundedThreshold {
+		u.counts.Add(addr, count+1)
+	}
+}
+
+func (u *underfundedSenders) contains(addr common.Address) bool {
+	if u.counts == nil {
+		return false
+	}
+	v, ok := u.counts.Peek(addr)
+	return ok && v.(int) >= underfundedThreshold
+}
+
+func (u *underfundedSenders) reset() {
+	if u.counts != nil {
+		u.counts.Purge()
+	}
+}
+
+// checkUnderfunded fast-rejects the txs of a sender which repeatedly failed the
+// balance check, before any state access
+func (app *EthermintApplication) checkUnderfunded(tx *ethTypes.Transaction) abciTypes.ResponseCheckTx {
+	from, err := app.sender(tx)
+	if err != nil {
+		// reported by the full validation
+		return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	if app.underfunded.contains(from) {
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeBaseInvalidInput,
+			Log:  "Sender repeatedly failed the balance check"}
+	}
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
+
+// recordUnderfunded counts a failed balance check of a sender
+func (app *EthermintApplication) recordUnderfunded(from common.Address) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.underfunded.add(from)
+}
+
+// resetUnderfunded clears the counts on Commit, the balances may have changed
+func (app *EthermintApplication) resetUnderfunded() {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.underfunded.reset()
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func newUnderfundedTestApp() (*EthermintApplication, ethTypes.Signer) {
+	signer := ethTypes.NewEIP155Signer(big.NewInt(777))
+	app := &EthermintApplication{}
+	app.SetSignerResolver(func(tx *ethTypes.Transaction) ethTypes.Signer {
+		return signer
+	})
+	return app, signer
+}
+
+func TestUnderfundedSenderFastRejected(t *testing.T) {
+	assert := assert.New(t)
+
+	app, signer := newUnderfundedTestApp()
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	tx, err := ethTypes.SignTx(pricedTx(0, 1), signer, key)
+	assert.Nil(err)
+
+	// the balance check failures below the threshold still get the full validation
+	for i := 0; i < underfundedThreshold; i++ {
+		assert.Equal(abciTypes.CodeTypeOK, app.checkUnderfunded(tx).Code)
+		app.recordUnderfunded(from)
+	}
+	assert.Equal(errors.CodeTypeBaseInvalidInput, app.checkUnderfunded(tx).Code)
+
+	// other senders aren't affected
+	other, _ := crypto.GenerateKey()
+	otherTx, err := ethTypes.SignTx(pricedTx(0, 1), signer, other)
+	assert.Nil(err)
+	assert.Equal(abciTypes.CodeTypeOK, app.checkUnderfunded(otherTx).Code)
+
+	// Commit resets the filter
+	app.resetUnderfunded()
+	assert.Equal(abciTypes.CodeTypeOK, app.checkUnderfunded(tx).Code)
+}
+
+func TestUnderfundedSendersBounded(t *testing.T) {
+	assert := assert.New(t)
+
+	address := func(i int) common.Address {
+		return common.BytesToAddress(crypto.Keccak256(big.NewInt(int64(i)).Bytes()))
+	}
+
+	var u underfundedSenders
+	for j := 0; j < underfundedThreshold; j++ {
+		u.add(address(0))
+	}
+	assert.True(u.contains(address(0)))
+
+	// a flood of failing senders never flags another sender
+	for i := 1; i <= underfundedSendersKept; i++ {
+		for j := 0; j < underfundedThreshold; j++ {
+			u.add(address(i))
+		}
+	}
+	for i := underfundedSendersKept + 1; i < underfundedSendersKept+1000; i++ {
+		assert.False(u.contains(address(i)))
+	}
+
+	// the counts stay bounded, the oldest sender is forgotten
+	assert.Equal(underfundedSendersKept, u.counts.Len())
+	assert.False(u.contains(address(0)))
+	assert.True(u.contains(address(underfundedSendersKept)))
+}
+
+// BenchmarkUnderfundedFastReject measures the rejection of a flagged sender
+func BenchmarkUnderfundedFastReject(b *testing.B) {
+	app, signer := newUnderfundedTestApp()
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	for i := 0; i < underfundedThreshold; i++ {
+		app.recordUnderfunded(from)
+	}
+	txs := signedTxs(b, signer, key)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		app.checkUnderfunded(txs[i%len(txs)])
+	}
+}
+
+// BenchmarkUnderfundedFullCheck measures the balance check of the same sender
+// through the state, as done without the filter
+func BenchmarkUnderfundedFullCheck(b *testing.B) {
+	app, signer := newUnderfundedTestApp()
+	key, _ := crypto.GenerateKey()
+	txs := signedTxs(b, signer, key)
+	st := newTestState()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tx := txs[i%len(txs)]
+		from, _ := ethTypes.Sender(app.signer(tx), tx)
+		checkBalance(st, from, tx)
+	}
+}
+
+func signedTxs(b *testing.B, signer ethTypes.Signer, key *ecdsa.PrivateKey) []*ethTypes.Transaction {
+	txs := make([]*ethTypes.Transaction, 1024)
+	for i := range txs {
+		tx, err := ethTypes.SignTx(pricedTx(uint64(i), 1), signer, key)
+		if err != nil {
+			b.Fatal(err)
+		}
+		txs[i] = tx
+	}
+	return txs
+}
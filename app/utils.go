@@ -23,6 +23,11 @@ type jsonRequest struct {
 	Method string          `json:"method"`
 	ID     json.RawMessage `json:"id,omitempty"`
 	Params []interface{}   `json:"params,omitempty"`
+	// optional block height the method is evaluated at
+	Height *uint64 `json:"height,omitempty"`
+	// pagination of the methods returning a listing
+	Offset uint64 `json:"offset,omitempty"`
+	Limit  uint64 `json:"limit,omitempty"`
 }
 
 // rlp decode an etherum transaction
@@ -35,6 +40,46 @@ func decodeTx(txBytes []byte) (*types.Transaction, error) {
 	return tx, nil
 }
 
+// byteCounter counts the bytes written to it
+type byteCounter int
+
+func (c *byteCounter) Write(b []byte) (int, error) {
+	*c += byteCounter(len(b))
+	return len(b), nil
+}
+
+// encodedTxSize returns the length of the RLP encoding of the tx. Unlike Size(),
+// which is cached on the tx, it's measured on the tx content.
+func encodedTxSize(tx *types.Transaction) (int, error) {
+	var c byteCounter
+	if err := rlp.Encode(&c, tx); err != nil {
+		return 0, err
+	}
+	return int(c), nil
+}
+
+// checkTxSize rejects the txs over maxTransactionSize. The cached Size() is only
+// a cheap pre-filter, the encoded length has the final say.
+func checkTxSize(tx *types.Transaction) abciTypes.ResponseCheckTx {
+	if tx.Size() > maxTransactionSize {
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeInternalErr,
+			Log:  core.ErrOversizedData.Error()}
+	}
+	size, err := encodedTxSize(tx)
+	if err != nil {
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeEncodingErr,
+			Log:  err.Error()}
+	}
+	if size > maxTransactionSize {
+		return abciTypes.ResponseCheckTx{
+			Code: errors.CodeTypeInternalErr,
+			Log:  core.ErrOversizedData.Error()}
+	}
+	return abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
+
 //-------------------------------------------------------
 // convenience methods for validators
 
@@ -50,6 +95,7 @@ func (app *EthermintApplication) Receiver() common.Address {
 // SetValidators sets new validators on the strategy
 // #unstable
 func (app *EthermintApplication) SetValidators(validators []abciTypes.Validator) {
+	app.setValidatorSet(validators)
 	if app.strategy != nil {
 		app.strategy.SetValidators(validators)
 	}
@@ -73,47 +119,45 @@ func (app *EthermintApplication) CollectTx(tx *types.Transaction) {
 }
 
 func (app *EthermintApplication) basicCheck(tx *ethTypes.Transaction) (*state.StateDB, common.Address, uint64, abciTypes.ResponseCheckTx) {
+	return app.basicCheckWithState(tx, app.checkTxState)
+}
+
+func (app *EthermintApplication) basicCheckWithState(tx *ethTypes.Transaction,
+	currentState *state.StateDB) (*state.StateDB, common.Address, uint64, abciTypes.ResponseCheckTx) {
 
 	// Heuristic limit, reject transactions over 32KB to prevent DOS attacks
-	if tx.Size() > maxTransactionSize {
-		return nil, common.Address{}, 0,
-			abciTypes.ResponseCheckTx{
-				Code: errors.CodeTypeInternalErr,
-				Log:  core.ErrOversizedData.Error()}
+	if resp := app.traceStep(tx, "size", checkTxSize(tx)); resp.Code != abciTypes.CodeTypeOK {
+		return nil, common.Address{}, 0, resp
 	}
 
 	// tx.ChainID() must > 0
 	if tx.ChainId().Cmp(big.NewInt(0)) <= 0 {
 		return nil, common.Address{}, 0,
-			abciTypes.ResponseCheckTx{
+			app.traceStep(tx, "chain_id", abciTypes.ResponseCheckTx{
 				Code: errors.CodeTypeInternalErr,
-				Log:  types.ErrInvalidChainId.Error()}
+				Log:  types.ErrInvalidChainId.Error()})
 	}
 
-	networkId := big.NewInt(int64(app.backend.Ethereum().NetVersion()))
-	signer := ethTypes.NewEIP155Signer(networkId)
-
 	// Make sure the transaction is signed properly
-	from, err := ethTypes.Sender(signer, tx)
+	from, err := app.sender(tx)
 	if err != nil {
 		// TODO: Add errors.CodeTypeInvalidSignature ?
 		return nil, common.Address{}, 0,
-			abciTypes.ResponseCheckTx{
+			app.traceStep(tx, "signature", abciTypes.ResponseCheckTx{
 				Code: errors.CodeTypeInternalErr,
-				Log:  err.Error()}
+				Log:  err.Error()})
 	}
+	app.tracePass(tx, "chain_id", "signature")
 
 	// Transactions can't be negative. This may never happen using RLP decoded
 	// transactions but may occur if you create a transaction using the RPC.
 	if tx.Value().Sign() < 0 {
 		return nil, common.Address{}, 0,
-			abciTypes.ResponseCheckTx{
+			app.traceStep(tx, "value", abciTypes.ResponseCheckTx{
 				Code: errors.CodeTypeBaseInvalidInput,
-				Log:  core.ErrNegativeValue.Error()}
+				Log:  core.ErrNegativeValue.Error()})
 	}
 
-	currentState := app.checkTxState
-
 	// Make sure the account exist - cant send from non-existing account.
 	//if !currentState.Exist(from) {
 	//	return nil, common.Address{}, 0,
@@ -126,35 +170,49 @@ func (app *EthermintApplication) basicCheck(tx *ethTypes.Transaction) (*state.St
 	gasLimit := app.backend.GasLimit()
 	if gasLimit < tx.Gas() {
 		return nil, common.Address{}, 0,
-			abciTypes.ResponseCheckTx{
+			app.traceStep(tx, "gas_limit", abciTypes.ResponseCheckTx{
 				Code: errors.CodeTypeInternalErr,
-				Log:  core.ErrGasLimitReached.Error()}
+				Log:  core.ErrGasLimitReached.Error()})
+	}
+	app.tracePass(tx, "value", "gas_limit")
+
+	nonce, resp := app.checkNonce(currentState, from, tx)
+	if resp.Code != abciTypes.CodeTypeOK {
+		return nil, from, nonce, resp
 	}
 
+	return currentState, from, nonce, abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+}
+
+// checkNonce checks the nonce of a tx is the next one of the sender, counting the
+// failed CheckTx of the sender; a tx already nonce checked passes. The nonce of
+// the sender in the state is returned.
+func (app *EthermintApplication) checkNonce(currentState *state.StateDB, from common.Address,
+	tx *ethTypes.Transaction) (uint64, abciTypes.ResponseCheckTx) {
+
 	nonce := currentState.GetNonce(from)
-	if _, ok := utils.NonceCheckedTx[tx.Hash()]; !ok {
+	if !utils.NonceCheckedTx.Contains(tx.Hash()) {
 		// Check if nonce is not strictly increasing
 		// if not then recheck with feeding failed count
 		if nonce != tx.Nonce() {
-			if c, ok := app.checkFailedCount[from]; ok {
+			if c, ok := app.failedCount(from); ok {
 				if nonce+c != tx.Nonce() {
-					return nil, common.Address{}, 0,
-						abciTypes.ResponseCheckTx{
+					return nonce,
+						app.traceStep(tx, "nonce", abciTypes.ResponseCheckTx{
 							Code: errors.CodeTypeBadNonce,
 							Log: fmt.Sprintf(
 								"Nonce not strictly increasing. Expected %d Got %d",
-								nonce, tx.Nonce())}
+								nonce, tx.Nonce())})
 				}
 			} else {
-				return nil, common.Address{}, 0,
-					abciTypes.ResponseCheckTx{
+				return nonce,
+					app.traceStep(tx, "nonce", abciTypes.ResponseCheckTx{
 						Code: errors.CodeTypeBadNonce,
 						Log: fmt.Sprintf(
 							"Nonce not strictly increasing. Expected %d Got %d",
-							nonce, tx.Nonce())}
+							nonce, tx.Nonce())})
 			}
 		}
 	}
-
-	return currentState, from, nonce, abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK}
+	return nonce, app.traceStep(tx, "nonce", abciTypes.ResponseCheckTx{Code: abciTypes.CodeTypeOK})
 }
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestCheckTxSize(t *testing.T) {
+	assert := assert.New(t)
+
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	small := ethTypes.NewTransaction(0, to, big.NewInt(1), 21000, big.NewInt(1), nil)
+	assert.Equal(abciTypes.CodeTypeOK, checkTxSize(small).Code)
+
+	large := ethTypes.NewTransaction(0, to, big.NewInt(1), 21000, big.NewInt(1), make([]byte, maxTransactionSize))
+	assert.Equal(errors.CodeTypeInternalErr, checkTxSize(large).Code)
+
+	// a tx decoded over a smaller one keeps the cached size of the latter when the
+	// decoding fails on a trailing element, after all the fields have been read
+	smallBytes, err := rlp.EncodeToBytes(small)
+	assert.Nil(err)
+	oversized, err := rlp.EncodeToBytes([]interface{}{
+		uint64(0), big.NewInt(1), uint64(21000), to, big.NewInt(1),
+		make([]byte, maxTransactionSize), big.NewInt(0), big.NewInt(0), big.NewInt(0),
+		uint64(0),
+	})
+	assert.Nil(err)
+
+	tx := new(ethTypes.Transaction)
+	assert.Nil(tx.DecodeRLP(rlp.NewStream(bytes.NewReader(smallBytes), 0)))
+	assert.NotNil(tx.DecodeRLP(rlp.NewStream(bytes.NewReader(oversized), 0)))
+	assert.Len(tx.Data(), maxTransactionSize)
+	assert.True(tx.Size() < maxTransactionSize)
+
+	size, err := encodedTxSize(tx)
+	assert.Nil(err)
+	assert.True(size > maxTransactionSize)
+	assert.Equal(errors.CodeTypeInternalErr, checkTxSize(tx).Code)
+}
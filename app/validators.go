@@ -0,0 +1,140 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+)
+
+// validatorKey identifies a validator by its public key
+func validatorKey(v abciTypes.Validator) string {
+	return fmt.Sprintf("%s/%X", v.PubKey.Type, v.PubKey.Data)
+}
+
+// sanitizeValidatorUpdates drops the malformed and duplicate entries of a validator
+// update and the removals that would leave no validator with positive power.
+// The dropped entries are logged.
+func sanitizeValidatorUpdates(current, updates []abciTypes.Validator,
+	logger tmLog.Logger) []abciTypes.Validator {
+
+	seen := make(map[string]bool, len(updates))
+	sanitized := make([]abciTypes.Validator, 0, len(updates))
+	for _, v := range updates {
+		key := validatorKey(v)
+		switch {
+		case len(v.PubKey.Data) == 0:
+			logger.Error("Dropping validator update without pubkey", "power", v.Power)
+		case v.Power < 0:
+			logger.Error("Dropping validator update with negative power", "pubkey", key, "power", v.Power)
+		case seen[key]:
+			logger.Error("Dropping duplicate validator update", "pubkey", key, "power", v.Power)
+		default:
+			seen[key] = true
+			sanitized = append(sanitized, v)
+		}
+	}
+
+	if len(current) == 0 || hasActiveValidator(current, sanitized) {
+		return sanitized
+	}
+
+	// the update would empty the validator set, keep the current validators
+	kept := sanitized[:0]
+	for _, v := range sanitized {
+		if v.Power == 0 {
+			logger.Error("Dropping validator removal that would empty the validator set", "pubkey", validatorKey(v))
+			continue
+		}
+		kept = append(kept, v)
+	}
+	return kept
+}
+
+// updateValidators sanitizes a validator update returned by EndBlock against the
+// current validator set and applies it, the sanitized update is returned
+func (app *EthermintApplication) updateValidators(updates []abciTypes.Validator) []abciTypes.Validator {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	updates = sanitizeValidatorUpdates(app.validators, updates, app.logger)
+	app.validators = applyValidatorUpdates(app.validators, updates)
+	return updates
+}
+
+// hasActiveValidator returns whether at least one validator with positive power
+// remains once the updates are applied to the current set
+func hasActiveValidator(current, updates []abciTypes.Validator) bool {
+	power := make(map[string]int64, len(current)+len(updates))
+	for _, v := range current {
+		power[validatorKey(v)] = v.Power
+	}
+	for _, v := range updates {
+		power[validatorKey(v)] = v.Power
+	}
+	for _, p := range power {
+		if p > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// applyValidatorUpdates returns the validator set with the updates applied,
+// a zero power removing the validator
+func applyValidatorUpdates(current, updates []abciTypes.Validator) []abciTypes.Validator {
+	byKey := make(map[string]abciTypes.Validator, len(current)+len(updates))
+	for _, v := range current {
+		byKey[validatorKey(v)] = v
+	}
+	for _, v := range updates {
+		if v.Power == 0 {
+			delete(byKey, validatorKey(v))
+		} else {
+			byKey[validatorKey(v)] = v
+		}
+	}
+
+	keys := make([]string, 0, len(byKey))
+	for k := range byKey {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	validators := make([]abciTypes.Validator, 0, len(keys))
+	for _, k := range keys {
+		validators = append(validators, byKey[k])
+	}
+	return validators
+}
+
+// setValidatorSet records the current validator set
+func (app *EthermintApplication) setValidatorSet(validators []abciTypes.Validator) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.validators = validators
+}
+
+// validatorInfo is the json form of a validator served by the travis_validators query
+type validatorInfo struct {
+	PubKeyType string `json:"pub_key_type"`
+	PubKey     string `json:"pub_key"`
+	Power      int64  `json:"power"`
+}
+
+// validatorSet returns the current validator set
+func (app *EthermintApplication) validatorSet() []validatorInfo {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	validators := make([]validatorInfo, 0, len(app.validators))
+	for _, v := range app.validators {
+		validators = append(validators, validatorInfo{
+			PubKeyType: v.PubKey.Type,
+			PubKey:     fmt.Sprintf("%X", v.PubKey.Data),
+			Power:      v.Power,
+		})
+	}
+	return validators
+}
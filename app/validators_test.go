@@ -0,0 +1,77 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	tmLog "github.com/tendermint/tendermint/libs/log"
+)
+
+func testValidator(b byte, power int64) abciTypes.Validator {
+	return abciTypes.Validator{
+		PubKey: abciTypes.PubKey{Type: "ed25519", Data: []byte{b}},
+		Power:  power,
+	}
+}
+
+func TestSanitizeValidatorUpdates(t *testing.T) {
+	assert := assert.New(t)
+	logger := tmLog.NewNopLogger()
+
+	current := []abciTypes.Validator{testValidator(1, 10), testValidator(2, 10)}
+
+	// malformed and duplicate entries are dropped
+	updates := []abciTypes.Validator{
+		testValidator(3, 5),
+		testValidator(3, 7),
+		testValidator(4, -1),
+		{Power: 5},
+	}
+	assert.Equal([]abciTypes.Validator{testValidator(3, 5)},
+		sanitizeValidatorUpdates(current, updates, logger))
+
+	// removals that leave an active validator go through
+	updates = []abciTypes.Validator{testValidator(1, 0)}
+	assert.Equal(updates, sanitizeValidatorUpdates(current, updates, logger))
+
+	// removals that would empty the validator set are dropped
+	updates = []abciTypes.Validator{testValidator(1, 0), testValidator(2, 0)}
+	assert.Empty(sanitizeValidatorUpdates(current, updates, logger))
+
+	// unless a new validator joins in the same update
+	updates = []abciTypes.Validator{testValidator(1, 0), testValidator(2, 0), testValidator(3, 1)}
+	assert.Equal(updates, sanitizeValidatorUpdates(current, updates, logger))
+}
+
+func TestQueryValidators(t *testing.T) {
+	assert := assert.New(t)
+
+	app := &EthermintApplication{logger: tmLog.NewNopLogger()}
+	app.InitChain(abciTypes.RequestInitChain{
+		Validators: []abciTypes.Validator{testValidator(1, 10), testValidator(2, 20)},
+	})
+
+	data, _ := json.Marshal(jsonRequest{Method: "travis_validators"})
+	res := app.Query(abciTypes.RequestQuery{Data: data})
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+
+	var validators []validatorInfo
+	assert.Nil(json.Unmarshal(res.Value, &validators))
+	assert.Equal([]validatorInfo{
+		{PubKeyType: "ed25519", PubKey: "01", Power: 10},
+		{PubKeyType: "ed25519", PubKey: "02", Power: 20},
+	}, validators)
+
+	// updates returned by EndBlock are sanitized and applied to the set
+	updates := app.updateValidators([]abciTypes.Validator{testValidator(1, 0), testValidator(3, 5), testValidator(3, 6)})
+	assert.Equal([]abciTypes.Validator{testValidator(1, 0), testValidator(3, 5)}, updates)
+	res = app.Query(abciTypes.RequestQuery{Data: data})
+	assert.Nil(json.Unmarshal(res.Value, &validators))
+	assert.Equal([]validatorInfo{
+		{PubKeyType: "ed25519", PubKey: "02", Power: 20},
+		{PubKeyType: "ed25519", PubKey: "03", Power: 5},
+	}, validators)
+}
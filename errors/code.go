@@ -12,5 +12,19 @@ const (
 	CodeTypeBaseInvalidInput  uint32 = 20
 	CodeTypeBaseInvalidOutput uint32 = 21
 
-	CodeLowGasPriceErr        uint32 = 101
+	CodeLowGasPriceErr         uint32 = 101
+	CodeTypeSenderHasCode      uint32 = 102
+	CodeTypeMempoolFull        uint32 = 103
+	CodeTypeFutureNonce        uint32 = 104
+	CodeTypeUnsupportedTxType  uint32 = 105
+	CodeTypeDustValue          uint32 = 106
+	CodeTypeTooManyPending     uint32 = 107
+	CodeTypeServiceUnavailable uint32 = 108
+	CodeTypeInvalidPayerSig    uint32 = 109
+	CodeTypeRateLimited        uint32 = 110
+	CodeTypeDuplicateTx        uint32 = 111
+	CodeTypeBreakerOpen        uint32 = 112
+	CodeTypeSelfTransfer       uint32 = 113
+	CodeTypeStateFull          uint32 = 114
+	CodeTypeResponseTooLarge   uint32 = 115
 )
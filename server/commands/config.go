@@ -62,6 +62,7 @@ type EthermintConfig struct {
 	WSApiFlag           string `mapstructure:"wsapi"`
 	VerbosityFlag       uint   `mapstructure:"verbosity"`
 	GCMode              string `mapstructure:"gcmode"`
+	CommitWAL           bool   `mapstructure:"commit_wal"`
 }
 
 func DefaultEthermintConfig() EthermintConfig {
@@ -81,6 +82,7 @@ func DefaultEthermintConfig() EthermintConfig {
 		WSApiFlag:           "",
 		VerbosityFlag:       3,
 		GCMode:              "full",
+		CommitWAL:           false,
 	}
 }
 
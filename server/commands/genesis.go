@@ -8,6 +8,7 @@ import (
 	"github.com/pkg/errors"
 
 	travis "github.com/CyberMiles/travis/types"
+	"github.com/CyberMiles/travis/utils"
 	cmn "github.com/tendermint/tendermint/libs/common"
 	"github.com/tendermint/tendermint/types"
 	"strconv"
@@ -28,6 +29,7 @@ type GenesisDoc struct {
 	BackupVals       uint16                     `json:"backup_vals"`
 	SelfStakingRatio string                     `json:"self_staking_ratio"`
 	CubePubKeys      []travis.GenesisCubePubKey `json:"cube_pub_keys"`
+	Params           map[string]string          `json:"params,omitempty"`
 }
 
 // SaveAs is a utility method for saving GenensisDoc as a JSON file.
@@ -80,6 +82,12 @@ func (genDoc *GenesisDoc) ValidateAndComplete() error {
 		return errors.Errorf("The genesis file must have at least one cube pub key")
 	}
 
+	for name, value := range genDoc.Params {
+		if !utils.CheckParamType(name, value) {
+			return errors.Errorf("Invalid genesis param %s: %s", name, value)
+		}
+	}
+
 	if genDoc.GenesisTime.IsZero() {
 		genDoc.GenesisTime = time.Now()
 	}
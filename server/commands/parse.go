@@ -7,6 +7,7 @@ import (
 	"github.com/pkg/errors"
 
 	cmn "github.com/tendermint/tendermint/libs/common"
+	"sort"
 	"strconv"
 )
 
@@ -72,6 +73,16 @@ func GetOptions(path string) ([]Option, error) {
 
 	res = append(res, Option{"stake", "cube_pub_keys", string(cubePubKeysBytes)})
 
+	// set the other params, in a fixed order
+	names := make([]string, 0, len(genDoc.Params))
+	for name := range genDoc.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		res = append(res, Option{"params", name, genDoc.Params[name]})
+	}
+
 	// set validators
 	for _, val := range validators {
 		res = append(res, Option{"stake", "validator", val})
@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"gopkg.in/urfave/cli.v1"
@@ -27,6 +28,7 @@ import (
 
 type Services struct {
 	backend *api.Backend
+	ethApp  *app.EthermintApplication
 	tmNode  *node.Node
 	emNode  *ethereum.Node
 }
@@ -55,6 +57,13 @@ func startServices(rootDir string, storeApp *app.StoreApp) (*Services, error) {
 		os.Exit(1)
 	}
 	ethApp.SetLogger(emtUtils.EthermintLogger().With("module", "vm"))
+	ethApp.SetMetrics(app.PrometheusMetrics())
+	if config.EMConfig.CommitWAL {
+		if err := ethApp.OpenCommitWAL(filepath.Join(rootDir, defaultDataDir, "commit.wal")); err != nil {
+			log.Warn(err.Error())
+			os.Exit(1)
+		}
+	}
 
 	// Create Basecoin app
 	basecoinApp, err := createBaseCoinApp(rootDir, storeApp, ethApp, backend.Ethereum())
@@ -71,7 +80,7 @@ func startServices(rootDir string, storeApp *app.StoreApp) (*Services, error) {
 	}
 	backend.SetTMNode(tmNode)
 
-	return &Services{backend, tmNode, emNode}, nil
+	return &Services{backend, ethApp, tmNode, emNode}, nil
 }
 
 // startNode copies the logic from go-ethereum
@@ -64,6 +64,9 @@ func start(rootDir string, storeApp *app.StoreApp) error {
 	// wait forever
 	cmn.TrapSignal(func() {
 		srvs.tmNode.Stop()
+		if err := srvs.ethApp.Close(); err != nil {
+			fmt.Println("Error closing the ethereum application:", err)
+		}
 		srvs.emNode.Stop()
 		dbm.Sqliter.CloseDB()
 		//for {
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// NewLRU creates an LRU of at most size entries, nil when size isn't positive
+func NewLRU(size int) *lru.Cache {
+	if size <= 0 {
+		return nil
+	}
+	cache, _ := lru.New(size)
+	return cache
+}
+
+// ResizeLRU returns an LRU of at most size entries holding the most recently used
+// entries of cache, nil when size isn't positive. The LRUs of golang-lru can't be
+// resized in place.
+func ResizeLRU(cache *lru.Cache, size int) *lru.Cache {
+	resized := NewLRU(size)
+	if cache == nil || resized == nil {
+		return resized
+	}
+	// oldest first
+	keys := cache.Keys()
+	if len(keys) > size {
+		keys = keys[len(keys)-size:]
+	}
+	for _, key := range keys {
+		if value, ok := cache.Peek(key); ok {
+			resized.Add(key, value)
+		}
+	}
+	return resized
+}
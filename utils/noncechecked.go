@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// DefaultNonceCheckedTxsSize is the number of txs NonceCheckedTx remembers unless
+// configured
+const DefaultNonceCheckedTxsSize = 10000
+
+// NonceCheckedTxs is an LRU of the hashes of the txs whose nonce has been checked
+// by CheckTx, which skip the nonce check when checked again. The least recently
+// used ones are evicted beyond its size. It's safe for concurrent use.
+type NonceCheckedTxs struct {
+	// guards the replacement of the cache on Resize
+	mu sync.RWMutex
+	// nil when disabled
+	cache *lru.Cache
+}
+
+// NewNonceCheckedTxs creates a set of at most size txs, 0 disables it
+func NewNonceCheckedTxs(size int) *NonceCheckedTxs {
+	return &NonceCheckedTxs{cache: NewLRU(size)}
+}
+
+// Add marks a tx as nonce checked
+func (c *NonceCheckedTxs) Add(hash common.Hash) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.cache != nil {
+		c.cache.Add(hash, struct{}{})
+	}
+}
+
+// Contains tells whether the nonce of a tx has been checked
+func (c *NonceCheckedTxs) Contains(hash common.Hash) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.cache == nil {
+		return false
+	}
+	// a lookup makes the tx the most recently used
+	_, ok := c.cache.Get(hash)
+	return ok
+}
+
+// Remove forgets a tx, its nonce is checked again
+func (c *NonceCheckedTxs) Remove(hash common.Hash) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.cache != nil {
+		c.cache.Remove(hash)
+	}
+}
+
+// Len returns the number of txs remembered
+func (c *NonceCheckedTxs) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.cache == nil {
+		return 0
+	}
+	return c.cache.Len()
+}
+
+// Resize changes the capacity, evicting the least recently used txs beyond it
+func (c *NonceCheckedTxs) Resize(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cache = ResizeLRU(c.cache, size)
+}
+
+// Reset forgets every tx
+func (c *NonceCheckedTxs) Reset() {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.cache != nil {
+		c.cache.Purge()
+	}
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func hashOf(i int) common.Hash {
+	return common.BigToHash(big.NewInt(int64(i)))
+}
+
+func TestNonceCheckedTxsEviction(t *testing.T) {
+	assert := assert.New(t)
+
+	checked := NewNonceCheckedTxs(3)
+	for i := 0; i < 3; i++ {
+		checked.Add(hashOf(i))
+	}
+	// a lookup makes the oldest the most recently used
+	assert.True(checked.Contains(hashOf(0)))
+
+	checked.Add(hashOf(3))
+	checked.Add(hashOf(4))
+	assert.Equal(3, checked.Len())
+	assert.True(checked.Contains(hashOf(0)))
+	assert.False(checked.Contains(hashOf(1)))
+	assert.False(checked.Contains(hashOf(2)))
+	assert.True(checked.Contains(hashOf(3)))
+	assert.True(checked.Contains(hashOf(4)))
+
+	checked.Remove(hashOf(3))
+	assert.False(checked.Contains(hashOf(3)))
+	assert.Equal(2, checked.Len())
+
+	checked.Resize(1)
+	assert.Equal(1, checked.Len())
+	assert.True(checked.Contains(hashOf(4)))
+
+	checked.Reset()
+	assert.Equal(0, checked.Len())
+
+	// a zero size remembers nothing
+	disabled := NewNonceCheckedTxs(0)
+	disabled.Add(hashOf(0))
+	assert.False(disabled.Contains(hashOf(0)))
+}
+
+func TestNonceCheckedTxsConcurrency(t *testing.T) {
+	assert := assert.New(t)
+
+	checked := NewNonceCheckedTxs(100)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				hash := hashOf(g*1000 + i)
+				checked.Add(hash)
+				checked.Contains(hash)
+				if i%3 == 0 {
+					checked.Remove(hash)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.True(checked.Len() <= 100)
+}
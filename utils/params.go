@@ -29,6 +29,12 @@ type Params struct {
 	MaxSlashingBlocks         int16          `json:"max_slashing_blocks" type:"uint"`
 	SlashingRatio             string         `json:"slashing_ratio" type:"float"`
 	CubePubKeys               string         `json:"cube_pub_keys" type:"json"`
+	DeliveryBreakerThreshold  uint64         `json:"delivery_breaker_threshold" type:"uint"` // consecutive backend errors failing the rest of a block, 0 disables it
+	GasLimitMin               uint64         `json:"gas_limit_min" type:"uint"`
+	GasLimitMax               uint64         `json:"gas_limit_max" type:"uint"`
+	GasLimitTarget            uint64         `json:"gas_limit_target" type:"uint"`      // block gas limit the adjustment heads for, 0 disables it
+	MaxHeaderTimeDrift        uint64         `json:"max_header_time_drift" type:"uint"` // seconds a block time may be past its parent, 0 disables it
+	Paymaster                 string         `json:"paymaster" type:"address"`          // contract sponsoring the gas of txs, empty when disabled
 }
 
 func defaultParams() *Params {
@@ -52,6 +58,12 @@ func defaultParams() *Params {
 		MaxSlashingBlocks:         12,
 		SlashingRatio:             "0.001",
 		CubePubKeys:               "{}",
+		DeliveryBreakerThreshold:  0,
+		GasLimitMin:               0,
+		GasLimitMax:               0,
+		GasLimitTarget:            0,
+		MaxHeaderTimeDrift:        0,
+		Paymaster:                 "",
 	}
 }
 
@@ -135,6 +147,10 @@ func CheckParamType(name, value string) bool {
 				}
 			case "string":
 				return true
+			case "address":
+				if value == "" || common.IsHexAddress(value) {
+					return true
+				}
 			}
 			return false
 		}
@@ -2,6 +2,7 @@ package ethereum
 
 import (
 	"bytes"
+	"fmt"
 	"math/big"
 	"sync"
 
@@ -42,6 +43,10 @@ type EthState struct {
 
 	mtx  sync.Mutex
 	work workState // latest working state
+
+	// gas limit of the blocks built on the next resets of the work state,
+	// calcGasLimit is used when 0
+	nextGasLimit uint64
 }
 
 // After NewEthState, call SetEthereum and SetEthConfig.
@@ -62,21 +67,31 @@ func (es *EthState) SetEthConfig(ethConfig *eth.Config) {
 
 // Execute the transaction.
 func (es *EthState) DeliverTx(tx *ethTypes.Transaction) abciTypes.ResponseDeliverTx {
+	return es.deliverTx(tx, nil)
+}
+
+// Execute the transaction with its gas paid by payer instead of the sender.
+func (es *EthState) DeliverSponsoredTx(tx *ethTypes.Transaction, payer common.Address) abciTypes.ResponseDeliverTx {
+	return es.deliverTx(tx, &payer)
+}
+
+func (es *EthState) deliverTx(tx *ethTypes.Transaction, payer *common.Address) abciTypes.ResponseDeliverTx {
 	es.mtx.Lock()
 	defer es.mtx.Unlock()
 
 	blockchain := es.ethereum.BlockChain()
 	chainConfig := es.ethereum.APIBackend.ChainConfig()
 	blockHash := common.Hash{}
-	return es.work.deliverTx(blockchain, es.ethConfig, chainConfig, blockHash, tx)
+	return es.work.deliverTx(blockchain, es.ethConfig, chainConfig, blockHash, tx, payer)
 }
 
 // Accumulate validator rewards.
-func (es *EthState) AccumulateRewards(config *params.ChainConfig, strategy *emtTypes.Strategy) {
+func (es *EthState) AccumulateRewards(config *params.ChainConfig, strategy emtTypes.RewardStrategy,
+	validators []abciTypes.Validator) map[common.Address]*big.Int {
 	es.mtx.Lock()
 	defer es.mtx.Unlock()
 
-	es.work.accumulateRewards(config, strategy)
+	return es.work.accumulateRewards(config, strategy, validators)
 }
 
 // Commit and reset the work.
@@ -118,6 +133,9 @@ func (es *EthState) resetWorkState(receiver common.Address) error {
 
 	currentBlock := blockchain.CurrentBlock()
 	ethHeader := newBlockHeader(receiver, currentBlock)
+	if es.nextGasLimit != 0 {
+		ethHeader.GasLimit = es.nextGasLimit
+	}
 
 	es.work = workState{
 		header:          ethHeader,
@@ -152,6 +170,23 @@ func (es *EthState) GasLimit() *core.GasPool {
 	return es.work.gp
 }
 
+// HeaderGasLimit returns the gas limit of the block being built
+func (es *EthState) HeaderGasLimit() uint64 {
+	es.mtx.Lock()
+	defer es.mtx.Unlock()
+
+	return es.work.header.GasLimit
+}
+
+// SetNextGasLimit sets the gas limit of the blocks built after the next reset of
+// the work state, 0 restores the default one
+func (es *EthState) SetNextGasLimit(limit uint64) {
+	es.mtx.Lock()
+	defer es.mtx.Unlock()
+
+	es.nextGasLimit = limit
+}
+
 //----------------------------------------------------------------------
 // Implements: miner.Pending API (our custom patch to go-ethereum)
 
@@ -190,25 +225,62 @@ type workState struct {
 	gp              *core.GasPool
 }
 
-// nolint: unparam
-func (ws *workState) accumulateRewards(config *params.ChainConfig, strategy *emtTypes.Strategy) {
+func (ws *workState) accumulateRewards(config *params.ChainConfig, strategy emtTypes.RewardStrategy,
+	validators []abciTypes.Validator) map[common.Address]*big.Int {
 
-	ethash.AccumulateRewards(config, ws.state, ws.header, []*ethTypes.Header{})
+	var rewards map[common.Address]*big.Int
+	// observer nodes may run without a reward strategy
+	if strategy != nil {
+		rewards = strategy.Distribute(ws.state, validators, emtTypes.BlockInfo{Header: ws.header, Config: config})
+	}
 	ws.header.GasUsed = *ws.totalUsedGas
+	return rewards
 }
 
 // Runs ApplyTransaction against the ethereum blockchain, fetches any logs,
 // and appends the tx, receipt, and logs.
+// A non nil payer lends the gas of the tx to the sender, which buys it as usual,
+// and gets the refund of the unused gas back, so that it pays the used gas only.
 func (ws *workState) deliverTx(blockchain *core.BlockChain, config *eth.Config,
 	chainConfig *params.ChainConfig, blockHash common.Hash,
-	tx *ethTypes.Transaction) abciTypes.ResponseDeliverTx {
+	tx *ethTypes.Transaction, payer *common.Address) abciTypes.ResponseDeliverTx {
 
-	delete(utils.NonceCheckedTx, tx.Hash())
+	utils.NonceCheckedTx.Remove(tx.Hash())
 
 	ws.handleStateChangeQueue()
 	ws.travisTxIndex = len(utils.StateChangeQueue)
 
 	ws.state.Prepare(tx.Hash(), blockHash, ws.txIndex)
+
+	var (
+		from     common.Address
+		snapshot int
+	)
+	if payer != nil {
+		var err error
+		from, err = ethTypes.Sender(ethTypes.MakeSigner(chainConfig, ws.header.Number), tx)
+		if err != nil {
+			return abciTypes.ResponseDeliverTx{Code: errors.CodeTypeBaseInvalidInput, Log: err.Error()}
+		}
+		gasCost := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas()))
+		if balance := ws.state.GetBalance(*payer); balance.Cmp(gasCost) < 0 {
+			return abciTypes.ResponseDeliverTx{
+				Code: errors.CodeTypeBaseInvalidInput,
+				Log:  fmt.Sprintf("Payer balance: %s, gas cost: %s", balance, gasCost)}
+		}
+		snapshot = ws.state.Snapshot()
+		ws.state.SubBalance(*payer, gasCost)
+		ws.state.AddBalance(from, gasCost)
+	}
+
+	// the tracer picks up the data returned by a revert, whether the vm debugging
+	// (--vmdebug) is on or not
+	tracer := new(revertTracer)
+	vmConfig := vm.Config{
+		EnablePreimageRecording: config.EnablePreimageRecording,
+		Debug:                   true,
+		Tracer:                  tracer,
+	}
 	receipt, usedGas, err := core.ApplyTransaction(
 		chainConfig,
 		blockchain,
@@ -218,10 +290,24 @@ func (ws *workState) deliverTx(blockchain *core.BlockChain, config *eth.Config,
 		ws.header,
 		tx,
 		ws.totalUsedGas,
-		vm.Config{EnablePreimageRecording: config.EnablePreimageRecording},
+		vmConfig,
 	)
 	if err != nil {
-		return abciTypes.ResponseDeliverTx{Code: errors.CodeTypeInternalErr, Log: err.Error()}
+		if payer != nil {
+			// a failed tx leaves no trace of the loan
+			ws.state.RevertToSnapshot(snapshot)
+		}
+		if ws.state.Error() != nil {
+			// the state couldn't be read, the backend is failing
+			return abciTypes.ResponseDeliverTx{Code: errors.CodeTypeInternalErr, Log: err.Error()}
+		}
+		// the tx is invalid in this state, e.g. a bad nonce or an unaffordable gas
+		return abciTypes.ResponseDeliverTx{Code: errors.CodeTypeBaseInvalidInput, Log: err.Error()}
+	}
+	if payer != nil {
+		refund := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas()-usedGas))
+		ws.state.SubBalance(from, refund)
+		ws.state.AddBalance(*payer, refund)
 	}
 
 	usedGasFee := big.NewInt(0).Mul(new(big.Int).SetUint64(usedGas), tx.GasPrice())
@@ -236,7 +322,11 @@ func (ws *workState) deliverTx(blockchain *core.BlockChain, config *eth.Config,
 	ws.receipts = append(ws.receipts, receipt)
 	ws.allLogs = append(ws.allLogs, logs...)
 
-	return abciTypes.ResponseDeliverTx{Code: abciTypes.CodeTypeOK}
+	res := abciTypes.ResponseDeliverTx{Code: abciTypes.CodeTypeOK, GasUsed: int64(usedGas)}
+	if tracer.reverted {
+		res.Log = revertLog(tracer.output)
+	}
+	return res
 }
 
 // Commit the ethereum state, update the header, make a new block and add it to
@@ -0,0 +1,94 @@
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/CyberMiles/travis/errors"
+)
+
+func TestDeliverSponsoredTx(t *testing.T) {
+	assert := assert.New(t)
+
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	payer := common.HexToAddress("0x5000000000000000000000000000000000000005")
+	poorPayer := common.HexToAddress("0x6000000000000000000000000000000000000006")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	db := ethdb.NewMemDatabase()
+	gspec := &core.Genesis{
+		Config:   params.TestChainConfig,
+		GasLimit: 10000000,
+		Alloc: core.GenesisAlloc{
+			// the sender only holds the value of its txs
+			from:      {Balance: big.NewInt(2)},
+			payer:     {Balance: big.NewInt(1e18)},
+			poorPayer: {Balance: big.NewInt(20999)},
+		},
+	}
+	genesis := gspec.MustCommit(db)
+	blockchain, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
+	assert.Nil(err)
+	defer blockchain.Stop()
+
+	st, err := blockchain.State()
+	assert.Nil(err)
+	usedGas := uint64(0)
+	header := &ethTypes.Header{
+		ParentHash: genesis.Hash(),
+		Number:     big.NewInt(1),
+		GasLimit:   genesis.GasLimit(),
+		Difficulty: big.NewInt(1),
+		Time:       big.NewInt(1),
+	}
+	ws := workState{
+		header:          header,
+		state:           st,
+		totalUsedGas:    &usedGas,
+		totalUsedGasFee: big.NewInt(0),
+		gp:              new(core.GasPool).AddGas(header.GasLimit),
+	}
+	signer := ethTypes.MakeSigner(gspec.Config, header.Number)
+	transfer := func(nonce uint64) *ethTypes.Transaction {
+		tx, _ := ethTypes.SignTx(ethTypes.NewTransaction(nonce, to, big.NewInt(1), 50000, big.NewInt(1), nil), signer, key)
+		return tx
+	}
+
+	// unsponsored, the sender can't buy the gas
+	res := ws.deliverTx(blockchain, &eth.Config{}, gspec.Config, common.Hash{}, transfer(0), nil)
+	assert.Equal(errors.CodeTypeBaseInvalidInput, res.Code)
+
+	// the payer is charged the used gas only, the sender the value
+	res = ws.deliverTx(blockchain, &eth.Config{}, gspec.Config, common.Hash{}, transfer(0), &payer)
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+	assert.Equal(int64(21000), res.GasUsed)
+	assert.Equal(big.NewInt(1e18-21000), st.GetBalance(payer))
+	assert.Equal(big.NewInt(1), st.GetBalance(from))
+	assert.Equal(big.NewInt(1), st.GetBalance(to))
+	assert.Equal(uint64(1), st.GetNonce(from))
+
+	// a payer which can't cover the gas limit is refused
+	res = ws.deliverTx(blockchain, &eth.Config{}, gspec.Config, common.Hash{}, transfer(1), &poorPayer)
+	assert.Equal(errors.CodeTypeBaseInvalidInput, res.Code)
+	assert.Equal(big.NewInt(20999), st.GetBalance(poorPayer))
+
+	// a failed tx leaves the balances untouched
+	res = ws.deliverTx(blockchain, &eth.Config{}, gspec.Config, common.Hash{}, transfer(5), &payer)
+	assert.Equal(errors.CodeTypeBaseInvalidInput, res.Code)
+	assert.Equal(big.NewInt(1e18-21000), st.GetBalance(payer))
+	assert.Equal(big.NewInt(1), st.GetBalance(from))
+	assert.Len(ws.receipts, 1)
+}
@@ -0,0 +1,80 @@
+package ethereum
+
+import (
+	"bytes"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// errExecutionReverted is the message of the error the EVM reports a REVERT with
+const errExecutionReverted = "evm: execution reverted"
+
+// revertSelector is the selector of Error(string), the ABI encoding of a revert reason
+var revertSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+
+// revertTracer captures the outcome of the top level call of a tx, only the
+// output of a reverted call is kept. It's installed on every tx, so it does
+// nothing but in CaptureEnd, which the EVM calls once per tx.
+type revertTracer struct {
+	reverted bool
+	output   []byte
+}
+
+var _ vm.Tracer = (*revertTracer)(nil)
+
+func (t *revertTracer) CaptureStart(from common.Address, to common.Address, call bool,
+	input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+func (t *revertTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64,
+	memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+func (t *revertTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64,
+	memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+func (t *revertTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	if err != nil && err.Error() == errExecutionReverted {
+		t.reverted = true
+		t.output = common.CopyBytes(output)
+	}
+	return nil
+}
+
+// revertLog describes a reverted tx with its decoded reason, or the raw returned
+// data when it isn't an Error(string)
+func revertLog(output []byte) string {
+	if len(output) == 0 {
+		return "execution reverted"
+	}
+	if reason, ok := decodeRevertReason(output); ok {
+		return "execution reverted: " + reason
+	}
+	return "execution reverted: " + hexutil.Encode(output)
+}
+
+// decodeRevertReason decodes the ABI encoded Error(string) returned by a revert
+func decodeRevertReason(output []byte) (string, bool) {
+	if len(output) < 4+64 || !bytes.Equal(output[:4], revertSelector) {
+		return "", false
+	}
+	data := output[4:]
+	offset := new(big.Int).SetBytes(data[:32])
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(data))-32 {
+		return "", false
+	}
+	start := offset.Uint64() + 32
+	length := new(big.Int).SetBytes(data[offset.Uint64():start])
+	if !length.IsUint64() || length.Uint64() > uint64(len(data))-start {
+		return "", false
+	}
+	return string(data[start : start+length.Uint64()]), true
+}
@@ -0,0 +1,136 @@
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+)
+
+// encodeRevertReason ABI encodes Error(reason)
+func encodeRevertReason(reason string) []byte {
+	data := append([]byte{}, revertSelector...)
+	data = append(data, common.LeftPadBytes([]byte{0x20}, 32)...)
+	data = append(data, common.LeftPadBytes(big.NewInt(int64(len(reason))).Bytes(), 32)...)
+	return append(data, common.RightPadBytes([]byte(reason), (len(reason)+31)/32*32)...)
+}
+
+// revertingInitCode is the init code of a contract whose constructor reverts with
+// the given data
+func revertingInitCode(data []byte) []byte {
+	n := byte(len(data))
+	code := []byte{
+		byte(vm.PUSH1), n, byte(vm.PUSH1), 12, byte(vm.PUSH1), 0, byte(vm.CODECOPY),
+		byte(vm.PUSH1), n, byte(vm.PUSH1), 0, byte(vm.REVERT),
+	}
+	return append(code, data...)
+}
+
+func TestDecodeRevertReason(t *testing.T) {
+	assert := assert.New(t)
+
+	reason, ok := decodeRevertReason(encodeRevertReason("insufficient allowance"))
+	assert.True(ok)
+	assert.Equal("insufficient allowance", reason)
+
+	reason, ok = decodeRevertReason(encodeRevertReason(""))
+	assert.True(ok)
+	assert.Equal("", reason)
+
+	// other selector, truncated data, out of bounds offset or length
+	custom := encodeRevertReason("x")
+	custom[0] = 0xff
+	_, ok = decodeRevertReason(custom)
+	assert.False(ok)
+	_, ok = decodeRevertReason(encodeRevertReason("truncated")[:4+40])
+	assert.False(ok)
+	badOffset := encodeRevertReason("x")
+	badOffset[4+31] = 0xff
+	_, ok = decodeRevertReason(badOffset)
+	assert.False(ok)
+	badLength := encodeRevertReason("x")
+	badLength[4+63] = 0xff
+	_, ok = decodeRevertReason(badLength)
+	assert.False(ok)
+
+	assert.Equal("execution reverted", revertLog(nil))
+	assert.Equal("execution reverted: nope", revertLog(encodeRevertReason("nope")))
+	assert.Equal("execution reverted: 0xdeadbeef", revertLog(hexutil.MustDecode("0xdeadbeef")))
+}
+
+func TestDeliverTxRevertReason(t *testing.T) {
+	assert := assert.New(t)
+
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	db := ethdb.NewMemDatabase()
+	gspec := &core.Genesis{
+		Config:   params.TestChainConfig,
+		GasLimit: 10000000,
+		Alloc:    core.GenesisAlloc{from: {Balance: big.NewInt(1e18)}},
+	}
+	genesis := gspec.MustCommit(db)
+	blockchain, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
+	assert.Nil(err)
+	defer blockchain.Stop()
+
+	st, err := blockchain.State()
+	assert.Nil(err)
+	usedGas := uint64(0)
+	header := &ethTypes.Header{
+		ParentHash: genesis.Hash(),
+		Number:     big.NewInt(1),
+		GasLimit:   genesis.GasLimit(),
+		Difficulty: big.NewInt(1),
+		Time:       big.NewInt(1),
+	}
+	ws := workState{
+		header:          header,
+		state:           st,
+		totalUsedGas:    &usedGas,
+		totalUsedGasFee: big.NewInt(0),
+		gp:              new(core.GasPool).AddGas(header.GasLimit),
+	}
+	signer := ethTypes.MakeSigner(gspec.Config, header.Number)
+	vmDebug := &eth.Config{EnablePreimageRecording: true}
+	deliver := func(config *eth.Config, nonce uint64, revertData []byte) abciTypes.ResponseDeliverTx {
+		tx, _ := ethTypes.SignTx(ethTypes.NewContractCreation(nonce, big.NewInt(0), 200000, big.NewInt(1),
+			revertingInitCode(revertData)), signer, key)
+		return ws.deliverTx(blockchain, config, gspec.Config, common.Hash{}, tx, nil)
+	}
+
+	// the tx is included, its reason is reported in the log
+	res := deliver(vmDebug, 0, encodeRevertReason("not allowed"))
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+	assert.Equal("execution reverted: not allowed", res.Log)
+	assert.Equal(ethTypes.ReceiptStatusFailed, ws.receipts[0].Status)
+
+	// a non standard revert passes its data through
+	res = deliver(vmDebug, 1, []byte{0xca, 0xfe})
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+	assert.Equal("execution reverted: 0xcafe", res.Log)
+
+	// a successful tx has no log
+	tx, _ := ethTypes.SignTx(ethTypes.NewTransaction(2, from, big.NewInt(1), 21000, big.NewInt(1), nil), signer, key)
+	res = ws.deliverTx(blockchain, vmDebug, gspec.Config, common.Hash{}, tx, nil)
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+	assert.Empty(res.Log)
+
+	// without the vm debugging the revert is reported too
+	res = deliver(&eth.Config{}, 3, encodeRevertReason("not allowed"))
+	assert.Equal(abciTypes.CodeTypeOK, res.Code)
+	assert.Equal("execution reverted: not allowed", res.Log)
+	assert.Equal(ethTypes.ReceiptStatusFailed, ws.receipts[3].Status)
+}
@@ -0,0 +1,32 @@
+package ethereum
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	emtTypes "github.com/CyberMiles/travis/vm/types"
+)
+
+// EthashRewardStrategy credits the ethash block reward to the coinbase of the block.
+// It is the default reward strategy.
+type EthashRewardStrategy struct{}
+
+var _ emtTypes.RewardStrategy = EthashRewardStrategy{}
+
+// Distribute implements RewardStrategy
+func (EthashRewardStrategy) Distribute(st *state.StateDB, validators []abciTypes.Validator,
+	block emtTypes.BlockInfo) map[common.Address]*big.Int {
+
+	coinbase := block.Header.Coinbase
+	before := new(big.Int).Set(st.GetBalance(coinbase))
+	ethash.AccumulateRewards(block.Config, st, block.Header, []*ethTypes.Header{})
+
+	return map[common.Address]*big.Int{
+		coinbase: new(big.Int).Sub(st.GetBalance(coinbase), before),
+	}
+}
@@ -0,0 +1,96 @@
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+
+	emtTypes "github.com/CyberMiles/travis/vm/types"
+)
+
+// stubRewardStrategy pays each validator its power in wei
+type stubRewardStrategy struct {
+	addresses map[string]common.Address
+}
+
+func (s stubRewardStrategy) Distribute(st *state.StateDB, validators []abciTypes.Validator,
+	block emtTypes.BlockInfo) map[common.Address]*big.Int {
+
+	rewards := make(map[common.Address]*big.Int)
+	for _, v := range validators {
+		addr := s.addresses[string(v.Address)]
+		amount := big.NewInt(v.Power)
+		st.AddBalance(addr, amount)
+		rewards[addr] = amount
+	}
+	return rewards
+}
+
+func TestAccumulateRewardsWithStrategy(t *testing.T) {
+	assert := assert.New(t)
+
+	st, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	alice := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	bob := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	strategy := stubRewardStrategy{addresses: map[string]common.Address{"alice": alice, "bob": bob}}
+	validators := []abciTypes.Validator{
+		{Address: []byte("alice"), Power: 10},
+		{Address: []byte("bob"), Power: 30},
+	}
+
+	usedGas := uint64(42000)
+	ws := workState{
+		header:       &ethTypes.Header{Number: big.NewInt(1)},
+		state:        st,
+		totalUsedGas: &usedGas,
+	}
+	rewards := ws.accumulateRewards(params.TestChainConfig, strategy, validators)
+
+	assert.Equal(big.NewInt(10), st.GetBalance(alice))
+	assert.Equal(big.NewInt(30), st.GetBalance(bob))
+	assert.Equal(big.NewInt(10), rewards[alice])
+	assert.Equal(big.NewInt(30), rewards[bob])
+	assert.Equal(usedGas, ws.header.GasUsed)
+}
+
+func TestAccumulateRewardsWithoutStrategy(t *testing.T) {
+	assert := assert.New(t)
+
+	st, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	usedGas := uint64(21000)
+	ws := workState{
+		header:       &ethTypes.Header{Number: big.NewInt(1)},
+		state:        st,
+		totalUsedGas: &usedGas,
+	}
+	validators := []abciTypes.Validator{{Address: []byte("alice"), Power: 10}}
+
+	var rewards map[common.Address]*big.Int
+	assert.NotPanics(func() {
+		rewards = ws.accumulateRewards(params.TestChainConfig, nil, validators)
+	})
+	assert.Empty(rewards)
+	// the block is still sealed with its gas used
+	assert.Equal(usedGas, ws.header.GasUsed)
+}
+
+func TestEthashRewardStrategy(t *testing.T) {
+	assert := assert.New(t)
+
+	st, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	coinbase := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	header := &ethTypes.Header{Number: big.NewInt(1), Coinbase: coinbase}
+
+	rewards := EthashRewardStrategy{}.Distribute(st, nil, emtTypes.BlockInfo{Header: header, Config: params.TestChainConfig})
+
+	assert.True(st.GetBalance(coinbase).Sign() > 0)
+	assert.Equal(st.GetBalance(coinbase), rewards[coinbase])
+}
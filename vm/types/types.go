@@ -1,8 +1,12 @@
 package types
 
 import (
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
 	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
 
 	"github.com/tendermint/tendermint/abci/types"
 )
@@ -24,3 +28,16 @@ type Strategy struct {
 	MinerRewardStrategy
 	ValidatorsStrategy
 }
+
+// BlockInfo describes the block the rewards are distributed for
+type BlockInfo struct {
+	Header *ethTypes.Header
+	Config *params.ChainConfig
+}
+
+// RewardStrategy is a validator compensation scheme.
+// Distribute credits the rewards of the block to the state and returns the
+// amount credited to each account.
+type RewardStrategy interface {
+	Distribute(state *state.StateDB, validators []types.Validator, block BlockInfo) map[common.Address]*big.Int
+}